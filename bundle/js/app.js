@@ -14,4 +14,38 @@ const logisticsAddressDetailMap = (id, lat, lon) => {
 	L.marker([lat, lon]).addTo(map);
 }
 
+// logisticsPlantMap plots the GeoJSON points of url, their properties name, address and url are shown in popups.
+const logisticsPlantMap = (id, url) => {
+	var map = L.map(id, {
+		center: [0, 0],
+		zoom: 2
+	});
+
+	L.tileLayer('https://tile.openstreetmap.org/{z}/{x}/{y}.png', {
+		maxZoom: 19,
+		attribution: '&copy; <a href="http://www.openstreetmap.org/copyright">OpenStreetMap</a>'
+	}).addTo(map);
+
+	fetch(url)
+		.then(response => response.json())
+		.then(data => {
+			var layer = L.geoJSON(data, {
+				onEachFeature: (feature, marker) => {
+					var link = document.createElement('a');
+					link.href = feature.properties.url;
+					link.textContent = feature.properties.name;
+
+					var popup = document.createElement('div');
+					popup.append(link, document.createElement('br'), feature.properties.address);
+					marker.bindPopup(popup);
+				}
+			}).addTo(map);
+
+			if (data.features.length > 0) {
+				map.fitBounds(layer.getBounds(), { maxZoom: 12, padding: [32, 32] });
+			}
+		});
+}
+
 window.logisticsAddressDetailMap = logisticsAddressDetailMap;
+window.logisticsPlantMap = logisticsPlantMap;
@@ -2,8 +2,10 @@ package accounting
 
 import (
 	"context"
+	"database/sql"
 	_ "embed"
 	"errors"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/tombuente/apex/internal/database"
@@ -17,10 +19,10 @@ var Schema string
 var Fixture string
 
 type Database struct {
-	db *pgx.Conn
+	db database.Querier
 }
 
-func MakeDatabase(db *pgx.Conn) Database {
+func MakeDatabase(db database.Querier) Database {
 	return Database{
 		db: db,
 	}
@@ -67,6 +69,29 @@ RETURNING *
 	return database.One[Account](ctx, db.db, query, id, params.Description)
 }
 
+func (db Database) accountBalance(ctx context.Context, id int64) (AccountBalance, error) {
+	const query = `
+SELECT
+	$1::INTEGER AS account_id,
+	COALESCE(SUM(amount) FILTER (WHERE type_id = 1), 0) AS debit,
+	COALESCE(SUM(amount) FILTER (WHERE type_id = 2), 0) AS credit
+FROM accounting.document_positions
+WHERE account_id = $1
+`
+
+	return database.One[AccountBalance](ctx, db.db, query, id)
+}
+
+func (db Database) currency(ctx context.Context, id int64) (Currency, error) {
+	const query = `
+SELECT *
+FROM accounting.currencies
+WHERE id = $1
+`
+
+	return database.One[Currency](ctx, db.db, query, id)
+}
+
 func (db Database) currencies(ctx context.Context) ([]Currency, error) {
 	const query = `
 SELECT *
@@ -111,24 +136,93 @@ WHERE document_id = $1
 	return Document{DocumentHeader: header, Positions: positions}, nil
 }
 
-func (db Database) documents(ctx context.Context, _ DocumentFilter) ([]Document, error) {
-	const query = `
-SELECT *
-FROM accounting.documents
+// documentSortColumns maps the sort keys accepted in DocumentFilter.SortBy to columns.
+var documentSortColumns = map[string]string{
+	"id":           "d.id",
+	"date":         "d.date",
+	"posting_date": "d.posting_date",
+	"reference":    "d.reference",
+	"total":        "totals.debit",
+}
+
+// documents returns a page of document summaries matching the filter and the total amount of matching documents.
+func (db Database) documents(ctx context.Context, filter DocumentFilter) ([]DocumentSummary, int64, error) {
+	const queryFormat = `
+SELECT d.*, totals.debit, totals.credit, COUNT(*) OVER() AS total_count
+FROM accounting.documents d
+CROSS JOIN LATERAL (
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE type_id = $13), 0) AS debit,
+		COALESCE(SUM(amount) FILTER (WHERE type_id = $14), 0) AS credit
+	FROM accounting.document_positions
+	WHERE document_id = d.id
+) totals
+WHERE
+	(d.date         >= $1 OR $1 IS NULL) AND
+	(d.date         <= $2 OR $2 IS NULL) AND
+	(d.posting_date >= $3 OR $3 IS NULL) AND
+	(d.posting_date <= $4 OR $4 IS NULL) AND
+	(d.reference   ILIKE $5 OR $5 IS NULL) AND
+	(d.description ILIKE $6 OR $6 IS NULL) AND
+	(d.currency_id = $7 OR $7 IS NULL) AND
+	(EXISTS (SELECT 1 FROM accounting.document_positions p WHERE p.document_id = d.id AND p.account_id = $8) OR $8 IS NULL) AND
+	(totals.debit >= $9  OR $9  IS NULL) AND
+	(totals.debit <= $10 OR $10 IS NULL)
+ORDER BY %[1]v %[2]v, d.id %[2]v
+LIMIT $11 OFFSET $12
 `
 
-	return database.Many[Document](ctx, db.db, query)
+	column, ok := documentSortColumns[filter.SortBy]
+	if !ok {
+		column = documentSortColumns["id"]
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	query := fmt.Sprintf(queryFormat, column, direction)
+
+	type documentSummaryRow struct {
+		DocumentSummary
+		TotalCount int64 `db:"total_count"`
+	}
+
+	rows, err := database.Many[documentSummaryRow](ctx, db.db, query,
+		filter.DateFrom, filter.DateTo, filter.PostingDateFrom, filter.PostingDateTo, filter.Reference, filter.Description,
+		filter.CurrencyID, filter.AccountID, filter.AmountMin, filter.AmountMax, filter.Limit, filter.Offset,
+		PositionTypeDebit, PositionTypeCredit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	documents := make([]DocumentSummary, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, row.DocumentSummary)
+	}
+
+	return documents, rows[0].TotalCount, nil
 }
 
 func (db Database) createDocument(ctx context.Context, params DocumentParams) (Document, error) {
-	// TODO: use transaction to make sure documents are not created without positions and vise versa
+	var document Document
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		document, err = InsertDocument(ctx, tx, params)
+		return err
+	})
 
+	return document, err
+}
+
+// InsertDocument inserts a document header and its positions, it should run inside of a transaction to make sure
+// documents are not created without positions. Other modules use it to post documents in their own transactions.
+func InsertDocument(ctx context.Context, q database.Querier, params DocumentParams) (Document, error) {
 	const documentHeaderQuery = `
 INSERT INTO accounting.documents (date, posting_date, reference, description, currency_id)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *
 `
-	documentHeader, err := database.One[DocumentHeader](ctx, db.db, documentHeaderQuery, params.Date, params.PostingDate, params.Reference, params.Description, params.CurrencyID)
+	documentHeader, err := database.One[DocumentHeader](ctx, q, documentHeaderQuery, params.Date, params.PostingDate, params.Reference, params.Description, params.CurrencyID)
 	if err != nil {
 		return Document{}, err
 	}
@@ -141,7 +235,7 @@ RETURNING *
 	var documentPositions []DocumentPosition
 
 	for _, posParams := range params.Positions {
-		documentPosition, err := database.One[DocumentPosition](ctx, db.db, documentPositionsQuery, documentHeader.ID, posParams.AccountID, posParams.Description, posParams.TypeID, posParams.Amount)
+		documentPosition, err := database.One[DocumentPosition](ctx, q, documentPositionsQuery, documentHeader.ID, posParams.AccountID, posParams.Description, posParams.TypeID, posParams.Amount)
 		if err != nil {
 			return Document{}, err
 		}
@@ -151,3 +245,192 @@ RETURNING *
 
 	return Document{DocumentHeader: documentHeader, Positions: documentPositions}, nil
 }
+
+func (db Database) documentAttachment(ctx context.Context, documentID int64, id int64) (DocumentAttachment, error) {
+	const query = `
+SELECT *
+FROM accounting.document_attachments
+WHERE document_id = $1 AND id = $2
+`
+
+	return database.One[DocumentAttachment](ctx, db.db, query, documentID, id)
+}
+
+func (db Database) documentAttachments(ctx context.Context, documentID int64) ([]DocumentAttachment, error) {
+	const query = `
+SELECT *
+FROM accounting.document_attachments
+WHERE document_id = $1
+ORDER BY id ASC
+`
+
+	return database.Many[DocumentAttachment](ctx, db.db, query, documentID)
+}
+
+func (db Database) createDocumentAttachment(ctx context.Context, documentID int64, params DocumentAttachmentParams) (DocumentAttachment, error) {
+	const query = `
+INSERT INTO accounting.document_attachments (document_id, filename, content_type, size, blob_key)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
+`
+
+	return database.One[DocumentAttachment](ctx, db.db, query, documentID, params.Filename, params.ContentType, params.Size, params.BlobKey)
+}
+
+func (db Database) asset(ctx context.Context, id int64) (Asset, error) {
+	const query = `
+SELECT *
+FROM accounting.assets
+WHERE id = $1
+`
+
+	return database.One[Asset](ctx, db.db, query, id)
+}
+
+func (db Database) assets(ctx context.Context, filter AssetFilter) ([]Asset, error) {
+	const query = `
+SELECT *
+FROM accounting.assets
+WHERE ((disposal_date IS NOT NULL) = $1 OR $1 IS NULL)
+ORDER BY id ASC
+`
+
+	return database.Many[Asset](ctx, db.db, query, filter.Disposed)
+}
+
+func (db Database) createAsset(ctx context.Context, params AssetParams) (Asset, error) {
+	const query = `
+INSERT INTO accounting.assets (description, acquisition_date, cost, salvage_value, useful_life, method, declining_rate, currency_id, asset_account_id, expense_account_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *
+`
+
+	return database.One[Asset](ctx, db.db, query, params.Description, params.AcquisitionDate, params.Cost, params.SalvageValue, params.UsefulLife,
+		params.Method, params.DecliningRate, params.CurrencyID, params.AssetAccountID, params.ExpenseAccountID)
+}
+
+func (db Database) updateAsset(ctx context.Context, id int64, params AssetParams) (Asset, error) {
+	const query = `
+UPDATE accounting.assets
+SET
+	description        = $2,
+	acquisition_date   = $3,
+	cost               = $4,
+	salvage_value      = $5,
+	useful_life        = $6,
+	method             = $7,
+	declining_rate     = $8,
+	currency_id        = $9,
+	asset_account_id   = $10,
+	expense_account_id = $11
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Asset](ctx, db.db, query, id, params.Description, params.AcquisitionDate, params.Cost, params.SalvageValue, params.UsefulLife,
+		params.Method, params.DecliningRate, params.CurrencyID, params.AssetAccountID, params.ExpenseAccountID)
+}
+
+// disposeAsset posts the disposal document and marks the asset as disposed.
+func (db Database) disposeAsset(ctx context.Context, id int64, date string, document DocumentParams) (Asset, error) {
+	const query = `
+UPDATE accounting.assets
+SET
+	disposal_date        = $2,
+	disposal_document_id = $3
+WHERE id = $1 AND disposal_date IS NULL
+RETURNING *
+`
+
+	var asset Asset
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		// Disposing of an asset without book value and proceeds has nothing to post.
+		var documentID sql.NullInt64
+		if len(document.Positions) > 0 {
+			disposalDocument, err := InsertDocument(ctx, tx, document)
+			if err != nil {
+				return err
+			}
+			documentID = sql.NullInt64{Valid: true, Int64: disposalDocument.ID}
+		}
+
+		var err error
+		asset, err = database.One[Asset](ctx, tx, query, id, date, documentID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return fmt.Errorf("%w: asset has already been disposed", xerrors.ErrBadRequest)
+		}
+		return err
+	})
+
+	return asset, err
+}
+
+func (db Database) assetDepreciations(ctx context.Context, filter AssetDepreciationFilter) ([]AssetDepreciation, error) {
+	const query = `
+SELECT *
+FROM accounting.asset_depreciations
+WHERE (asset_id = $1 OR $1 IS NULL)
+ORDER BY asset_id ASC, period ASC
+`
+
+	return database.Many[AssetDepreciation](ctx, db.db, query, filter.AssetID)
+}
+
+func (db Database) depreciationRuns(ctx context.Context) ([]DepreciationRun, error) {
+	const query = `
+SELECT *
+FROM accounting.depreciation_runs
+ORDER BY id DESC
+`
+
+	return database.Many[DepreciationRun](ctx, db.db, query)
+}
+
+// depreciationPosting is a document of a depreciation run together with the depreciations it posts.
+type depreciationPosting struct {
+	document      DocumentParams
+	depreciations []AssetDepreciation
+}
+
+// createDepreciationRun records a run and posts its documents. Depreciating an asset twice for the same period fails
+// and rolls back the whole run.
+func (db Database) createDepreciationRun(ctx context.Context, period string, postings []depreciationPosting) (DepreciationRun, error) {
+	const runQuery = `
+INSERT INTO accounting.depreciation_runs (period)
+VALUES ($1)
+RETURNING *
+`
+
+	const depreciationQuery = `
+INSERT INTO accounting.asset_depreciations (run_id, asset_id, document_id, period, amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
+`
+
+	var run DepreciationRun
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		run, err = database.One[DepreciationRun](ctx, tx, runQuery, period)
+		if err != nil {
+			return err
+		}
+
+		for _, posting := range postings {
+			document, err := InsertDocument(ctx, tx, posting.document)
+			if err != nil {
+				return err
+			}
+
+			for _, depreciation := range posting.depreciations {
+				_, err := database.One[AssetDepreciation](ctx, tx, depreciationQuery, run.ID, depreciation.AssetID, document.ID, depreciation.Period, depreciation.Amount)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return run, err
+}
@@ -3,6 +3,19 @@ package accounting
 import (
 	"database/sql"
 	"strconv"
+	"time"
+)
+
+// Document position types as seeded in seed.sql.
+const (
+	PositionTypeDebit  int64 = 1
+	PositionTypeCredit int64 = 2
+)
+
+// Depreciation methods of assets.
+const (
+	DepreciationStraightLine     = "straight_line"
+	DepreciationDecliningBalance = "declining_balance"
 )
 
 type Account struct {
@@ -74,7 +87,138 @@ type DocumentHeaderParams struct {
 	CurrencyID  int64
 }
 
+// DocumentSummary is a document header together with the totals of its positions.
+type DocumentSummary struct {
+	DocumentHeader
+	Debit  int64 `json:"debit" db:"debit"`
+	Credit int64 `json:"credit" db:"credit"`
+}
+
+// AccountBalance are the debit and credit totals posted to an account.
+type AccountBalance struct {
+	AccountID int64 `json:"account_id" db:"account_id"`
+	Debit     int64 `json:"debit" db:"debit"`
+	Credit    int64 `json:"credit" db:"credit"`
+}
+
 type DocumentFilter struct {
+	DateFrom        sql.NullString
+	DateTo          sql.NullString
+	PostingDateFrom sql.NullString
+	PostingDateTo   sql.NullString
+	Reference       sql.NullString // Reference is an ILIKE pattern, see database.ContainsPattern.
+	Description     sql.NullString // Description is an ILIKE pattern, see database.ContainsPattern.
+	CurrencyID      sql.NullInt64
+	AccountID       sql.NullInt64 // AccountID matches documents with at least one position on the account.
+	AmountMin       sql.NullInt64 // AmountMin is compared against the debit total of a document.
+	AmountMax       sql.NullInt64 // AmountMax is compared against the debit total of a document.
+
+	SortBy     string // SortBy is one of the keys of documentSortColumns.
+	Descending bool
+	Limit      int64
+	Offset     int64
+}
+
+type DocumentAttachment struct {
+	ID          int64     `json:"id" db:"id"`
+	DocumentID  int64     `json:"document_id" db:"document_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	BlobKey     string    `json:"-" db:"blob_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type DocumentAttachmentParams struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	BlobKey     string
+}
+
+type Asset struct {
+	ID                 int64          `json:"id" db:"id"`
+	Description        string         `json:"description" db:"description"`
+	AcquisitionDate    string         `json:"acquisition_date" db:"acquisition_date"`
+	Cost               int64          `json:"cost" db:"cost"`
+	SalvageValue       int64          `json:"salvage_value" db:"salvage_value"`
+	UsefulLife         int64          `json:"useful_life" db:"useful_life"` // UsefulLife is the useful life in months.
+	Method             string         `json:"method" db:"method"`
+	DecliningRate      int64          `json:"declining_rate" db:"declining_rate"` // DecliningRate is the yearly rate in percent used by the declining-balance method.
+	CurrencyID         int64          `json:"currency_id" db:"currency_id"`
+	AssetAccountID     int64          `json:"asset_account_id" db:"asset_account_id"`
+	ExpenseAccountID   int64          `json:"expense_account_id" db:"expense_account_id"`
+	DisposalDate       sql.NullString `json:"disposal_date" db:"disposal_date"`
+	DisposalDocumentID sql.NullInt64  `json:"disposal_document_id" db:"disposal_document_id"`
+}
+
+type AssetParams struct {
+	Description      string `json:"description" form:"description"`
+	AcquisitionDate  string `json:"acquisition_date" form:"acquisition_date"`
+	Cost             int64  `json:"cost" form:"cost"`
+	SalvageValue     int64  `json:"salvage_value" form:"salvage_value"`
+	UsefulLife       int64  `json:"useful_life" form:"useful_life"`
+	Method           string `json:"method" form:"method"`
+	DecliningRate    int64  `json:"declining_rate" form:"declining_rate"`
+	CurrencyID       int64  `json:"currency_id" form:"currency_id"`
+	AssetAccountID   int64  `json:"asset_account_id" form:"asset_account_id"`
+	ExpenseAccountID int64  `json:"expense_account_id" form:"expense_account_id"`
+}
+
+type AssetFilter struct {
+	Disposed sql.NullBool
+}
+
+// AssetDisposalParams describe the sale or retirement of an asset. The difference between proceeds and the remaining
+// book value is posted as gain or loss.
+type AssetDisposalParams struct {
+	Date              string `json:"date" form:"date"`
+	Proceeds          int64  `json:"proceeds" form:"proceeds"`
+	ProceedsAccountID int64  `json:"proceeds_account_id" form:"proceeds_account_id"`
+	GainAccountID     int64  `json:"gain_account_id" form:"gain_account_id"`
+	LossAccountID     int64  `json:"loss_account_id" form:"loss_account_id"`
+}
+
+type AssetDepreciation struct {
+	ID         int64  `json:"id" db:"id"`
+	RunID      int64  `json:"run_id" db:"run_id"`
+	AssetID    int64  `json:"asset_id" db:"asset_id"`
+	DocumentID int64  `json:"document_id" db:"document_id"`
+	Period     string `json:"period" db:"period"`
+	Amount     int64  `json:"amount" db:"amount"`
+}
+
+type AssetDepreciationFilter struct {
+	AssetID sql.NullInt64
+}
+
+// DepreciationPeriod is a single month of an asset's depreciation schedule.
+type DepreciationPeriod struct {
+	Period     string
+	Opening    int64
+	Amount     int64
+	Closing    int64
+	DocumentID sql.NullInt64 // DocumentID is set once the depreciation has been posted.
+}
+
+type DepreciationRun struct {
+	ID        int64     `json:"id" db:"id"`
+	Period    string    `json:"period" db:"period"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type DepreciationRunParams struct {
+	Period string `json:"period" form:"period"`
+}
+
+// AssetScheduleRow is a line of the asset history schedule, it shows how the book value of an asset developed in a year.
+type AssetScheduleRow struct {
+	Asset
+	Opening      int64
+	Additions    int64
+	Depreciation int64
+	Disposals    int64
+	Closing      int64
 }
 
 func (account Account) GetID() string {
@@ -85,6 +229,24 @@ func (account Account) Redirect() string {
 	return "/accounting/accounts/" + account.GetID()
 }
 
+func (document DocumentSummary) GetID() string {
+	return strconv.FormatInt(document.ID, 10)
+}
+
+func (document DocumentSummary) Redirect() string {
+	return "/accounting/documents/" + document.GetID()
+}
+
+// Balanced reports whether debit and credit totals match.
+func (document DocumentSummary) Balanced() bool {
+	return document.Debit == document.Credit
+}
+
+// Balance is the debit balance of the account, negative for credit balances.
+func (balance AccountBalance) Balance() int64 {
+	return balance.Debit - balance.Credit
+}
+
 func (document Document) GetID() string {
 	return strconv.FormatInt(document.ID, 10)
 }
@@ -92,3 +254,27 @@ func (document Document) GetID() string {
 func (document Document) Redirect() string {
 	return "/accounting/documents/" + document.GetID()
 }
+
+func (attachment DocumentAttachment) GetID() string {
+	return strconv.FormatInt(attachment.ID, 10)
+}
+
+func (attachment DocumentAttachment) Redirect() string {
+	return "/accounting/documents/" + strconv.FormatInt(attachment.DocumentID, 10) + "/attachments/" + attachment.GetID()
+}
+
+func (asset Asset) GetID() string {
+	return strconv.FormatInt(asset.ID, 10)
+}
+
+func (asset Asset) Redirect() string {
+	return "/accounting/assets/" + asset.GetID()
+}
+
+func (run DepreciationRun) GetID() string {
+	return strconv.FormatInt(run.ID, 10)
+}
+
+func (run DepreciationRun) Redirect() string {
+	return "/accounting/depreciation-runs"
+}
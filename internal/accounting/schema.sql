@@ -34,3 +34,45 @@ CREATE TABLE IF NOT EXISTS accounting.document_positions(
 	type_id     INTEGER NOT NULL REFERENCES accounting.document_position_types(id),
 	amount      NUMERIC NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS accounting.document_attachments(
+	id           SERIAL       PRIMARY KEY,
+	document_id  INTEGER      NOT NULL REFERENCES accounting.documents(id),
+	filename     VARCHAR(255) NOT NULL,
+	content_type VARCHAR(255) NOT NULL,
+	size         BIGINT       NOT NULL,
+	blob_key     VARCHAR(64)  NOT NULL,
+	created_at   TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS accounting.assets(
+	id                   SERIAL       PRIMARY KEY,
+	description          TEXT         NOT NULL,
+	acquisition_date     VARCHAR(255) NOT NULL,
+	cost                 NUMERIC      NOT NULL CHECK (cost >= 0),
+	salvage_value        NUMERIC      NOT NULL DEFAULT 0 CHECK (salvage_value >= 0),
+	useful_life          INTEGER      NOT NULL CHECK (useful_life > 0),
+	method               VARCHAR(255) NOT NULL,
+	declining_rate       INTEGER      NOT NULL DEFAULT 0,
+	currency_id          INTEGER      NOT NULL REFERENCES accounting.currencies(id),
+	asset_account_id     INTEGER      NOT NULL REFERENCES accounting.accounts(id),
+	expense_account_id   INTEGER      NOT NULL REFERENCES accounting.accounts(id),
+	disposal_date        VARCHAR(255),
+	disposal_document_id INTEGER      REFERENCES accounting.documents(id)
+);
+
+CREATE TABLE IF NOT EXISTS accounting.depreciation_runs(
+	id         SERIAL     PRIMARY KEY,
+	period     VARCHAR(7) NOT NULL,
+	created_at TIMESTAMP  NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS accounting.asset_depreciations(
+	id          SERIAL     PRIMARY KEY,
+	run_id      INTEGER    NOT NULL REFERENCES accounting.depreciation_runs(id),
+	asset_id    INTEGER    NOT NULL REFERENCES accounting.assets(id),
+	document_id INTEGER    NOT NULL REFERENCES accounting.documents(id),
+	period      VARCHAR(7) NOT NULL,
+	amount      NUMERIC    NOT NULL,
+	UNIQUE (asset_id, period)
+);
@@ -1,16 +1,48 @@
 package accounting
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tombuente/apex/internal/blob"
+	"github.com/tombuente/apex/internal/xerrors"
 )
 
+// MaxAttachmentSize is the maximum size of a single document attachment in bytes.
+const MaxAttachmentSize = 10 << 20
+
+// periodLayout is the layout of accounting periods (months).
+const periodLayout = "2006-01"
+
+// attachmentContentTypes lists the content types accepted for document attachments, receipts and invoices are
+// expected to be either PDFs or images.
+var attachmentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
 type Service struct {
-	db Database
+	db    Database
+	blobs blob.Store
 }
 
-func MakeService(db Database) Service {
+func MakeService(db Database, blobs blob.Store) Service {
 	return Service{
-		db: db,
+		db:    db,
+		blobs: blobs,
 	}
 }
 
@@ -42,10 +74,492 @@ func (s Service) document(ctx context.Context, id int64) (Document, error) {
 	return s.db.document(ctx, id)
 }
 
-func (s Service) documents(ctx context.Context, filter DocumentFilter) ([]Document, error) {
+func (s Service) documents(ctx context.Context, filter DocumentFilter) ([]DocumentSummary, int64, error) {
 	return s.db.documents(ctx, filter)
 }
 
 func (s Service) createDocument(ctx context.Context, params DocumentParams) (Document, error) {
 	return s.db.createDocument(ctx, params)
 }
+
+func (s Service) documentAttachment(ctx context.Context, documentID int64, id int64) (DocumentAttachment, error) {
+	return s.db.documentAttachment(ctx, documentID, id)
+}
+
+func (s Service) documentAttachments(ctx context.Context, documentID int64) ([]DocumentAttachment, error) {
+	return s.db.documentAttachments(ctx, documentID)
+}
+
+// createDocumentAttachment validates and stores the content of r and attaches it to the document.
+// The content type is sniffed from the content, the type announced by the client is not trusted.
+func (s Service) createDocumentAttachment(ctx context.Context, documentID int64, filename string, size int64, r io.Reader) (DocumentAttachment, error) {
+	if size > MaxAttachmentSize {
+		return DocumentAttachment{}, fmt.Errorf("%w: attachment exceeds the maximum size of %v MiB", xerrors.ErrBadRequest, MaxAttachmentSize>>20)
+	}
+
+	if _, err := s.db.document(ctx, documentID); err != nil {
+		return DocumentAttachment{}, err
+	}
+
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "." || filename == string(filepath.Separator) {
+		return DocumentAttachment{}, fmt.Errorf("%w: attachment has no filename", xerrors.ErrBadRequest)
+	}
+
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return DocumentAttachment{}, xerrors.Join(xerrors.ErrInternal, err)
+	}
+	if len(head) == 0 {
+		return DocumentAttachment{}, fmt.Errorf("%w: attachment is empty", xerrors.ErrBadRequest)
+	}
+
+	contentType := http.DetectContentType(head)
+	if !attachmentContentTypes[contentType] {
+		return DocumentAttachment{}, fmt.Errorf("%w: attachments of type %v are not allowed", xerrors.ErrBadRequest, contentType)
+	}
+
+	// The announced size is not trusted either, reading one byte more than the maximum size detects larger content before
+	// anything is stored.
+	content, err := io.ReadAll(io.LimitReader(br, MaxAttachmentSize+1))
+	if err != nil {
+		return DocumentAttachment{}, xerrors.Join(xerrors.ErrInternal, err)
+	}
+	if len(content) > MaxAttachmentSize {
+		return DocumentAttachment{}, fmt.Errorf("%w: attachment exceeds the maximum size of %v MiB", xerrors.ErrBadRequest, MaxAttachmentSize>>20)
+	}
+
+	object, err := s.blobs.Put(ctx, bytes.NewReader(content))
+	if err != nil {
+		return DocumentAttachment{}, xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	params := DocumentAttachmentParams{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        object.Size,
+		BlobKey:     object.Key,
+	}
+
+	return s.db.createDocumentAttachment(ctx, documentID, params)
+}
+
+// openDocumentAttachment returns the attachment and a reader for its content, the caller has to close the reader.
+func (s Service) openDocumentAttachment(ctx context.Context, documentID int64, id int64) (DocumentAttachment, io.ReadCloser, error) {
+	attachment, err := s.db.documentAttachment(ctx, documentID, id)
+	if err != nil {
+		return DocumentAttachment{}, nil, err
+	}
+
+	content, err := s.blobs.Open(ctx, attachment.BlobKey)
+	if err != nil {
+		return DocumentAttachment{}, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+func (s Service) asset(ctx context.Context, id int64) (Asset, error) {
+	return s.db.asset(ctx, id)
+}
+
+func (s Service) assets(ctx context.Context, filter AssetFilter) ([]Asset, error) {
+	return s.db.assets(ctx, filter)
+}
+
+func (s Service) createAsset(ctx context.Context, params AssetParams) (Asset, error) {
+	if err := validateAssetParams(params); err != nil {
+		return Asset{}, err
+	}
+
+	return s.db.createAsset(ctx, params)
+}
+
+// updateAsset updates an asset. Once depreciation has been posted only the description and the expense account can be changed,
+// otherwise the posted depreciation would no longer match the schedule.
+func (s Service) updateAsset(ctx context.Context, id int64, params AssetParams) (Asset, error) {
+	if err := validateAssetParams(params); err != nil {
+		return Asset{}, err
+	}
+
+	asset, err := s.db.asset(ctx, id)
+	if err != nil {
+		return Asset{}, err
+	}
+
+	if asset.DisposalDate.Valid {
+		return Asset{}, fmt.Errorf("%w: disposed assets can not be changed", xerrors.ErrBadRequest)
+	}
+
+	posted, err := s.db.assetDepreciations(ctx, AssetDepreciationFilter{AssetID: sql.NullInt64{Valid: true, Int64: id}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return Asset{}, err
+	}
+
+	changed := params.AcquisitionDate != asset.AcquisitionDate || params.Cost != asset.Cost || params.SalvageValue != asset.SalvageValue ||
+		params.UsefulLife != asset.UsefulLife || params.Method != asset.Method || params.DecliningRate != asset.DecliningRate ||
+		params.CurrencyID != asset.CurrencyID || params.AssetAccountID != asset.AssetAccountID
+	if len(posted) > 0 && changed {
+		return Asset{}, fmt.Errorf("%w: depreciation has already been posted, only description and expense account can be changed", xerrors.ErrBadRequest)
+	}
+
+	return s.db.updateAsset(ctx, id, params)
+}
+
+func validateAssetParams(params AssetParams) error {
+	if strings.TrimSpace(params.Description) == "" {
+		return fmt.Errorf("%w: asset description is required", xerrors.ErrBadRequest)
+	}
+
+	if _, err := time.Parse(time.DateOnly, params.AcquisitionDate); err != nil {
+		return fmt.Errorf("%w: acquisition date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if params.Cost < 0 || params.SalvageValue < 0 || params.SalvageValue > params.Cost {
+		return fmt.Errorf("%w: salvage value has to be between zero and the acquisition cost", xerrors.ErrBadRequest)
+	}
+
+	if params.UsefulLife <= 0 {
+		return fmt.Errorf("%w: useful life has to be at least one month", xerrors.ErrBadRequest)
+	}
+
+	switch params.Method {
+	case DepreciationStraightLine:
+	case DepreciationDecliningBalance:
+		if params.DecliningRate <= 0 || params.DecliningRate > 100 {
+			return fmt.Errorf("%w: declining rate has to be between 1 and 100 percent", xerrors.ErrBadRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown depreciation method %v", xerrors.ErrBadRequest, params.Method)
+	}
+
+	if params.CurrencyID == 0 || params.AssetAccountID == 0 || params.ExpenseAccountID == 0 {
+		return fmt.Errorf("%w: currency, asset account and expense account are required", xerrors.ErrBadRequest)
+	}
+
+	return nil
+}
+
+// depreciationSchedule calculates the monthly depreciation of an asset over its useful life, starting in the month of acquisition.
+//
+// Declining-balance depreciation switches to straight-line as soon as straight-line depreciation of the remaining book value
+// is higher, so assets are always fully depreciated at the end of their useful life.
+func depreciationSchedule(asset Asset) ([]DepreciationPeriod, error) {
+	acquired, err := time.Parse(time.DateOnly, asset.AcquisitionDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformatted acquisition date of asset %v", xerrors.ErrInternal, asset.ID)
+	}
+	start := time.Date(acquired.Year(), acquired.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	periods := make([]DepreciationPeriod, 0, asset.UsefulLife)
+	bookValue := asset.Cost
+	for month := int64(0); month < asset.UsefulLife; month++ {
+		depreciable := bookValue - asset.SalvageValue
+		amount := depreciable / (asset.UsefulLife - month)
+
+		if asset.Method == DepreciationDecliningBalance {
+			declining := (bookValue*asset.DecliningRate + 600) / 1200
+			amount = max(amount, declining)
+		}
+
+		if month == asset.UsefulLife-1 {
+			amount = depreciable
+		}
+		amount = min(amount, depreciable)
+
+		periods = append(periods, DepreciationPeriod{
+			Period:  start.AddDate(0, int(month), 0).Format(periodLayout),
+			Opening: bookValue,
+			Amount:  amount,
+			Closing: bookValue - amount,
+		})
+		bookValue -= amount
+	}
+
+	return periods, nil
+}
+
+// assetSchedule returns the depreciation schedule of an asset with posted periods linked to their documents.
+func (s Service) assetSchedule(ctx context.Context, asset Asset) ([]DepreciationPeriod, error) {
+	periods, err := depreciationSchedule(asset)
+	if err != nil {
+		return nil, err
+	}
+
+	posted, err := s.db.assetDepreciations(ctx, AssetDepreciationFilter{AssetID: sql.NullInt64{Valid: true, Int64: asset.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	documents := make(map[string]int64, len(posted))
+	for _, depreciation := range posted {
+		documents[depreciation.Period] = depreciation.DocumentID
+	}
+
+	for i := range periods {
+		if documentID, ok := documents[periods[i].Period]; ok {
+			periods[i].DocumentID = sql.NullInt64{Valid: true, Int64: documentID}
+		}
+	}
+
+	return periods, nil
+}
+
+func (s Service) depreciationRuns(ctx context.Context) ([]DepreciationRun, error) {
+	return s.db.depreciationRuns(ctx)
+}
+
+// createDepreciationRun posts the depreciation of all assets in use up to and including the period. Periods that have been
+// missed by earlier runs are caught up. One document is posted per currency.
+func (s Service) createDepreciationRun(ctx context.Context, params DepreciationRunParams) (DepreciationRun, error) {
+	period, err := time.Parse(periodLayout, params.Period)
+	if err != nil {
+		return DepreciationRun{}, fmt.Errorf("%w: period has to be formatted as YYYY-MM", xerrors.ErrBadRequest)
+	}
+
+	assets, err := s.db.assets(ctx, AssetFilter{Disposed: sql.NullBool{Valid: true, Bool: false}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return DepreciationRun{}, err
+	}
+
+	posted, err := s.db.assetDepreciations(ctx, AssetDepreciationFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return DepreciationRun{}, err
+	}
+
+	isPosted := make(map[int64]map[string]bool)
+	for _, depreciation := range posted {
+		if isPosted[depreciation.AssetID] == nil {
+			isPosted[depreciation.AssetID] = make(map[string]bool)
+		}
+		isPosted[depreciation.AssetID][depreciation.Period] = true
+	}
+
+	lastDay := period.AddDate(0, 1, -1).Format(time.DateOnly)
+	postings := make(map[int64]*depreciationPosting)
+	var currencies []int64
+
+	for _, asset := range assets {
+		schedule, err := depreciationSchedule(asset)
+		if err != nil {
+			return DepreciationRun{}, err
+		}
+
+		var total int64
+		var depreciations []AssetDepreciation
+		for _, p := range schedule {
+			if p.Period > params.Period || p.Amount == 0 || isPosted[asset.ID][p.Period] {
+				continue
+			}
+
+			depreciations = append(depreciations, AssetDepreciation{AssetID: asset.ID, Period: p.Period, Amount: p.Amount})
+			total += p.Amount
+		}
+
+		if total == 0 {
+			continue
+		}
+
+		posting, ok := postings[asset.CurrencyID]
+		if !ok {
+			posting = &depreciationPosting{
+				document: DocumentParams{
+					DocumentHeaderParams: DocumentHeaderParams{
+						Description: "Depreciation " + params.Period,
+						Date:        lastDay,
+						PostingDate: lastDay,
+						Reference:   "DEP-" + params.Period,
+						CurrencyID:  asset.CurrencyID,
+					},
+				},
+			}
+			postings[asset.CurrencyID] = posting
+			currencies = append(currencies, asset.CurrencyID)
+		}
+
+		description := fmt.Sprintf("Depreciation asset %v: %v", asset.ID, asset.Description)
+		posting.document.Positions = append(posting.document.Positions,
+			DocumentPositionParams{Description: description, AccountID: asset.ExpenseAccountID, TypeID: PositionTypeDebit, Amount: total},
+			DocumentPositionParams{Description: description, AccountID: asset.AssetAccountID, TypeID: PositionTypeCredit, Amount: total},
+		)
+		posting.depreciations = append(posting.depreciations, depreciations...)
+	}
+
+	if len(currencies) == 0 {
+		return DepreciationRun{}, fmt.Errorf("%w: no depreciation is due up to %v", xerrors.ErrBadRequest, params.Period)
+	}
+
+	slices.Sort(currencies)
+	documents := make([]depreciationPosting, 0, len(currencies))
+	for _, currencyID := range currencies {
+		documents = append(documents, *postings[currencyID])
+	}
+
+	return s.db.createDepreciationRun(ctx, params.Period, documents)
+}
+
+// disposeAsset retires an asset and posts the disposal. The remaining book value is credited to the asset account and the difference
+// to the proceeds is posted as gain or loss. Assets without book value and proceeds are retired without a document.
+//
+// The book value is only right if depreciation has been posted up to and including the month of disposal and not beyond it, so
+// disposals are rejected until depreciation runs have caught up and can not be dated before periods that have been posted.
+func (s Service) disposeAsset(ctx context.Context, id int64, params AssetDisposalParams) (Asset, error) {
+	asset, err := s.db.asset(ctx, id)
+	if err != nil {
+		return Asset{}, err
+	}
+
+	if asset.DisposalDate.Valid {
+		return Asset{}, fmt.Errorf("%w: asset has already been disposed", xerrors.ErrBadRequest)
+	}
+
+	date, err := time.Parse(time.DateOnly, params.Date)
+	if err != nil {
+		return Asset{}, fmt.Errorf("%w: disposal date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if params.Date < asset.AcquisitionDate {
+		return Asset{}, fmt.Errorf("%w: asset can not be disposed before it has been acquired", xerrors.ErrBadRequest)
+	}
+
+	if params.Proceeds < 0 {
+		return Asset{}, fmt.Errorf("%w: proceeds can not be negative", xerrors.ErrBadRequest)
+	}
+
+	posted, err := s.db.assetDepreciations(ctx, AssetDepreciationFilter{AssetID: sql.NullInt64{Valid: true, Int64: id}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return Asset{}, err
+	}
+
+	period := date.Format(periodLayout)
+	isPosted := make(map[string]bool, len(posted))
+	bookValue := asset.Cost
+	for _, depreciation := range posted {
+		if depreciation.Period > period {
+			return Asset{}, fmt.Errorf("%w: depreciation has been posted for %v, the asset can not be disposed before", xerrors.ErrBadRequest,
+				depreciation.Period)
+		}
+		isPosted[depreciation.Period] = true
+		bookValue -= depreciation.Amount
+	}
+
+	schedule, err := depreciationSchedule(asset)
+	if err != nil {
+		return Asset{}, err
+	}
+	for _, p := range schedule {
+		if p.Period <= period && p.Amount != 0 && !isPosted[p.Period] {
+			return Asset{}, fmt.Errorf("%w: depreciation for %v has not been posted, run depreciation up to %v before the disposal", xerrors.ErrBadRequest,
+				p.Period, period)
+		}
+	}
+
+	description := fmt.Sprintf("Disposal asset %v: %v", asset.ID, asset.Description)
+	document := DocumentParams{
+		DocumentHeaderParams: DocumentHeaderParams{
+			Description: description,
+			Date:        params.Date,
+			PostingDate: params.Date,
+			Reference:   "DISP-" + asset.GetID(),
+			CurrencyID:  asset.CurrencyID,
+		},
+	}
+
+	if params.Proceeds > 0 {
+		if params.ProceedsAccountID == 0 {
+			return Asset{}, fmt.Errorf("%w: proceeds account is required", xerrors.ErrBadRequest)
+		}
+		document.Positions = append(document.Positions, DocumentPositionParams{Description: description, AccountID: params.ProceedsAccountID, TypeID: PositionTypeDebit, Amount: params.Proceeds})
+	}
+
+	if bookValue > 0 {
+		document.Positions = append(document.Positions, DocumentPositionParams{Description: description, AccountID: asset.AssetAccountID, TypeID: PositionTypeCredit, Amount: bookValue})
+	}
+
+	switch difference := params.Proceeds - bookValue; {
+	case difference > 0:
+		if params.GainAccountID == 0 {
+			return Asset{}, fmt.Errorf("%w: gain account is required", xerrors.ErrBadRequest)
+		}
+		document.Positions = append(document.Positions, DocumentPositionParams{Description: "Gain on " + description, AccountID: params.GainAccountID, TypeID: PositionTypeCredit, Amount: difference})
+	case difference < 0:
+		if params.LossAccountID == 0 {
+			return Asset{}, fmt.Errorf("%w: loss account is required", xerrors.ErrBadRequest)
+		}
+		document.Positions = append(document.Positions, DocumentPositionParams{Description: "Loss on " + description, AccountID: params.LossAccountID, TypeID: PositionTypeDebit, Amount: -difference})
+	}
+
+	return s.db.disposeAsset(ctx, id, params.Date, document)
+}
+
+// assetHistorySchedule shows how the book values of all assets developed during a year, based on posted depreciation.
+func (s Service) assetHistorySchedule(ctx context.Context, year int) ([]AssetScheduleRow, error) {
+	assets, err := s.db.assets(ctx, AssetFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	posted, err := s.db.assetDepreciations(ctx, AssetDepreciationFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	yearStart := fmt.Sprintf("%04d-01-01", year)
+	nextYearStart := fmt.Sprintf("%04d-01-01", year+1)
+	periodStart := yearStart[:7]
+	nextPeriodStart := nextYearStart[:7]
+
+	depreciationBefore := make(map[int64]int64)
+	depreciationDuring := make(map[int64]int64)
+	for _, depreciation := range posted {
+		switch {
+		case depreciation.Period < periodStart:
+			depreciationBefore[depreciation.AssetID] += depreciation.Amount
+		case depreciation.Period < nextPeriodStart:
+			depreciationDuring[depreciation.AssetID] += depreciation.Amount
+		}
+	}
+
+	var rows []AssetScheduleRow
+	for _, asset := range assets {
+		if asset.AcquisitionDate >= nextYearStart || (asset.DisposalDate.Valid && asset.DisposalDate.String < yearStart) {
+			continue
+		}
+
+		row := AssetScheduleRow{Asset: asset}
+		if asset.AcquisitionDate < yearStart {
+			row.Opening = asset.Cost - depreciationBefore[asset.ID]
+		} else {
+			row.Additions = asset.Cost
+		}
+		row.Depreciation = depreciationDuring[asset.ID]
+
+		if asset.DisposalDate.Valid && asset.DisposalDate.String < nextYearStart {
+			row.Disposals = row.Opening + row.Additions - row.Depreciation
+		}
+		row.Closing = row.Opening + row.Additions - row.Depreciation - row.Disposals
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// The following methods let other modules post to the ledger.
+
+func (s Service) Accounts(ctx context.Context, filter AccountFilter) ([]Account, error) {
+	return s.db.accounts(ctx, filter)
+}
+
+func (s Service) AccountBalance(ctx context.Context, accountID int64) (AccountBalance, error) {
+	return s.db.accountBalance(ctx, accountID)
+}
+
+func (s Service) Currency(ctx context.Context, id int64) (Currency, error) {
+	return s.db.currency(ctx, id)
+}
+
+func (s Service) Currencies(ctx context.Context) ([]Currency, error) {
+	return s.db.currencies(ctx)
+}
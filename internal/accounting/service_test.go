@@ -0,0 +1,104 @@
+package accounting
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDepreciationSchedule(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset Asset
+		want  []int64
+	}{
+		{
+			name:  "straight-line",
+			asset: Asset{AcquisitionDate: "2024-11-15", Cost: 1200, UsefulLife: 12, Method: DepreciationStraightLine},
+			want:  []int64{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100},
+		},
+		{
+			name:  "straight-line with remainder in the last period",
+			asset: Asset{AcquisitionDate: "2024-01-31", Cost: 1000, UsefulLife: 3, Method: DepreciationStraightLine},
+			want:  []int64{333, 333, 334},
+		},
+		{
+			name:  "straight-line with salvage value",
+			asset: Asset{AcquisitionDate: "2024-01-01", Cost: 1000, SalvageValue: 100, UsefulLife: 4, Method: DepreciationStraightLine},
+			want:  []int64{225, 225, 225, 225},
+		},
+		{
+			// 100% a year are 1/12 of the book value a month, straight-line takes over once 12 months are left.
+			name:  "declining-balance switching to straight-line",
+			asset: Asset{AcquisitionDate: "2024-01-01", Cost: 120000, UsefulLife: 24, Method: DepreciationDecliningBalance, DecliningRate: 100},
+			want: []int64{
+				10000, 9167, 8403, 7703, 7061, 6472, 5933, 5438, 4985, 4570, 4189, 3840,
+				3520, 3519, 3520, 3520, 3520, 3520, 3520, 3520, 3520, 3520, 3520, 3520,
+			},
+		},
+		{
+			// The salvage value is reached before the end of the useful life, the remaining periods depreciate nothing.
+			name: "declining-balance with salvage value",
+			asset: Asset{
+				AcquisitionDate: "2024-01-01",
+				Cost:            120000,
+				SalvageValue:    20000,
+				UsefulLife:      24,
+				Method:          DepreciationDecliningBalance,
+				DecliningRate:   100,
+			},
+			want: []int64{
+				10000, 9167, 8403, 7703, 7061, 6472, 5933, 5438, 4985, 4570, 4189, 3840,
+				3520, 3227, 2958, 2711, 2485, 2278, 2088, 1914, 1058, 0, 0, 0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, err := depreciationSchedule(tt.asset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			amounts := make([]int64, len(periods))
+			for i, period := range periods {
+				amounts[i] = period.Amount
+			}
+			if !slices.Equal(amounts, tt.want) {
+				t.Fatalf("amounts = %v, want %v", amounts, tt.want)
+			}
+
+			opening := tt.asset.Cost
+			for _, period := range periods {
+				if period.Opening != opening || period.Closing != opening-period.Amount {
+					t.Errorf("period %v opens at %v and closes at %v, want %v and %v", period.Period, period.Opening, period.Closing, opening, opening-period.Amount)
+				}
+				opening = period.Closing
+			}
+			if opening != tt.asset.SalvageValue {
+				t.Errorf("book value at the end = %v, want the salvage value %v", opening, tt.asset.SalvageValue)
+			}
+		})
+	}
+}
+
+func TestDepreciationSchedulePeriods(t *testing.T) {
+	periods, err := depreciationSchedule(Asset{AcquisitionDate: "2024-11-30", Cost: 300, UsefulLife: 3, Method: DepreciationStraightLine})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, period := range periods {
+		got = append(got, period.Period)
+	}
+	if want := []string{"2024-11", "2024-12", "2025-01"}; !slices.Equal(got, want) {
+		t.Errorf("periods = %v, want %v", got, want)
+	}
+}
+
+func TestDepreciationScheduleInvalidDate(t *testing.T) {
+	if _, err := depreciationSchedule(Asset{AcquisitionDate: "30.11.2024", Cost: 300, UsefulLife: 3}); err == nil {
+		t.Error("schedule of an asset with a malformatted acquisition date succeeded, want error")
+	}
+}
@@ -2,25 +2,32 @@ package accounting
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/tombuente/apex/internal/database"
 	"github.com/tombuente/apex/internal/flash"
+	"github.com/tombuente/apex/internal/pdf"
 	"github.com/tombuente/apex/internal/templates"
 	"github.com/tombuente/apex/internal/xerrors"
 	"github.com/tombuente/apex/internal/xui"
 )
 
 type UI struct {
-	service   Service
-	templates map[string]*template.Template
+	service    Service
+	templates  map[string]*template.Template
+	letterhead pdf.Letterhead
 }
 
 type accountsData struct {
@@ -28,6 +35,34 @@ type accountsData struct {
 	Resources []Account
 }
 
+type documentListData struct {
+	Message    flash.Message
+	Resources  []DocumentSummary
+	Query      url.Values
+	Page       xui.Page
+	Accounts   []Account
+	Currencies []Currency
+}
+
+type assetData struct {
+	Message    flash.Message
+	Resource   *Asset
+	Accounts   []Account
+	Currencies []Currency
+	Schedule   []DepreciationPeriod
+}
+
+type assetScheduleData struct {
+	Message   flash.Message
+	Year      int
+	Resources []AssetScheduleRow
+}
+
+type depreciationRunsData struct {
+	Message   flash.Message
+	Resources []DepreciationRun
+}
+
 type documentData struct {
 	Message       flash.Message
 	Resource      *Document
@@ -35,12 +70,14 @@ type documentData struct {
 	Currencies    []Currency
 	PositionTypes []DocumentPositionType
 	Positions     []DocumentPosition
+	Attachments   []DocumentAttachment
 }
 
-func NewUIRouter(templateFS fs.FS, service Service) (*chi.Mux, error) {
+func NewUIRouter(templateFS fs.FS, service Service, letterhead pdf.Letterhead) (*chi.Mux, error) {
 	ui := UI{
-		service:   service,
-		templates: make(map[string]*template.Template),
+		service:    service,
+		templates:  make(map[string]*template.Template),
+		letterhead: letterhead,
 	}
 
 	var err error
@@ -63,12 +100,30 @@ func NewUIRouter(templateFS fs.FS, service Service) (*chi.Mux, error) {
 
 	r.Route("/documents", func(r chi.Router) {
 		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.document, ui.additionalDocumentData, ui.templates["document-detail"]))
+		r.Get("/{id}/pdf", ui.documentPDF)
 		r.Get("/new", xui.CreateViewWithData(ui.additionalDocumentData, ui.templates["document-create"]))
-		r.Get("/", xui.ListView(ui.makeDocumentFilter, ui.service.documents, ui.templates["document-list"]))
+		r.Get("/", ui.documentListView)
 		r.Post("/", xui.CreateWithFormParser(parseDocumentForm, ui.service.createDocument))
+		r.Post("/{id}/attachments", ui.createDocumentAttachment)
+		r.Get("/{id}/attachments/{attachmentID}", ui.downloadDocumentAttachment)
 		// r.Post("/verify", ui.vertifyDocumentViewHTMX)
 	})
 
+	r.Route("/assets", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.additionalAssetData, ui.templates["asset-create"]))
+		r.Get("/schedule", ui.assetScheduleView)
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.asset, ui.additionalAssetData, ui.templates["asset-detail"]))
+		r.Get("/", xui.ListView(ui.makeAssetFilter, ui.service.assets, ui.templates["asset-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateAsset))
+		r.Post("/{id}/dispose", ui.disposeAsset)
+		r.Post("/", xui.Create(ui.service.createAsset))
+	})
+
+	r.Route("/depreciation-runs", func(r chi.Router) {
+		r.Get("/", ui.depreciationRunListView)
+		r.Post("/", ui.createDepreciationRun)
+	})
+
 	return r, nil
 }
 
@@ -107,18 +162,445 @@ func (ui UI) additionalDocumentData(ctx context.Context, w http.ResponseWriter,
 		return documentData{}, err
 	}
 
+	var attachments []DocumentAttachment
+	if document != nil {
+		attachments, err = ui.service.documentAttachments(ctx, document.ID)
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return documentData{}, err
+		}
+	}
+
 	return documentData{
 		Message:       flash.Get(w, r),
 		Resource:      document,
 		Accounts:      accounts,
 		Currencies:    currencies,
 		PositionTypes: documentPositionTypes,
+		Attachments:   attachments,
 	}, nil
 }
 
+func (ui UI) documentPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	document, err := ui.service.document(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to query resource", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	accounts, err := ui.service.accounts(r.Context(), AccountFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query accounts", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	accountNames := make(map[int64]string, len(accounts))
+	for _, account := range accounts {
+		accountNames[account.ID] = account.Description
+	}
+
+	currencies, err := ui.service.currencies(r.Context())
+	if err != nil {
+		slog.Error("Unable to query currencies", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	var currencyISO string
+	for _, currency := range currencies {
+		if currency.ID == document.CurrencyID {
+			currencyISO = currency.ISO
+		}
+	}
+
+	report := pdf.NewReport(ui.letterhead, "Document "+document.GetID())
+	report.Fields([][2]string{
+		{"Reference", document.Reference},
+		{"Description", document.Description},
+		{"Date", document.Date},
+		{"Posting date", document.PostingDate},
+		{"Currency", currencyISO},
+	})
+
+	var rows [][]string
+	var debit, credit int64
+	for _, position := range document.Positions {
+		row := []string{position.Description, accountNames[position.AccountID], "", ""}
+		switch position.TypeID {
+		case PositionTypeDebit:
+			row[2] = strconv.FormatInt(position.Amount, 10)
+			debit += position.Amount
+		case PositionTypeCredit:
+			row[3] = strconv.FormatInt(position.Amount, 10)
+			credit += position.Amount
+		}
+		rows = append(rows, row)
+	}
+
+	report.Heading("Positions")
+	report.Table([]pdf.Column{
+		{Title: "Description", Width: 4},
+		{Title: "Account", Width: 3},
+		{Title: "Debit", Width: 1.5, Align: pdf.Right},
+		{Title: "Credit", Width: 1.5, Align: pdf.Right},
+	}, rows)
+	report.Totals([][2]string{
+		{"Debit total", strconv.FormatInt(debit, 10) + " " + currencyISO},
+		{"Credit total", strconv.FormatInt(credit, 10) + " " + currencyISO},
+	})
+
+	xui.ServePDF(w, "document-"+document.GetID()+".pdf", report)
+}
+
+func (ui UI) createDocumentAttachment(w http.ResponseWriter, r *http.Request) {
+	documentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+	redirect := Document{DocumentHeader: DocumentHeader{ID: documentID}}.Redirect()
+
+	// Leave some room for the multipart overhead, the exact limit is enforced by the service.
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAttachmentSize+1<<20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: "Unable to read the uploaded file, it might be too large."})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	defer file.Close()
+
+	_, err = ui.service.createDocumentAttachment(r.Context(), documentID, header.Filename, header.Size, file)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: err.Error()})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to create document attachment", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.EntryCreated(w)
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (ui UI) downloadDocumentAttachment(w http.ResponseWriter, r *http.Request) {
+	documentID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	attachmentID, err := strconv.ParseInt(chi.URLParam(r, "attachmentID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted attachment id", http.StatusBadRequest)
+		return
+	}
+
+	attachment, content, err := ui.service.openDocumentAttachment(r.Context(), documentID, attachmentID)
+	if err != nil {
+		slog.Error("Unable to open document attachment", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if _, err := io.Copy(w, content); err != nil {
+		slog.Error("Unable to write document attachment", "error", err)
+	}
+}
+
+// documentPageSize is the default amount of documents shown per page.
+const documentPageSize = 50
+
+func (ui UI) documentListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, err := xui.ParsePage(query, documentPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := ui.makeDocumentFilter(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Limit = page.Size
+	filter.Offset = page.Offset()
+
+	documents, total, err := ui.service.documents(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query documents", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+	page.Total = total
+
+	accounts, err := ui.service.accounts(r.Context(), AccountFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query accounts", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	currencies, err := ui.service.currencies(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query currencies", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := documentListData{
+		Message:    flash.Get(w, r),
+		Resources:  documents,
+		Query:      query,
+		Page:       page,
+		Accounts:   accounts,
+		Currencies: currencies,
+	}
+
+	if err := ui.templates["document-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (ui UI) makeDocumentFilter(ctx context.Context, values url.Values) (DocumentFilter, error) {
-	// TODO: Remove dummy filter
-	return DocumentFilter{}, nil
+	filter := DocumentFilter{
+		SortBy:     values.Get("sort"),
+		Descending: values.Get("order") == "desc",
+	}
+
+	if _, ok := documentSortColumns[filter.SortBy]; filter.SortBy != "" && !ok {
+		return DocumentFilter{}, fmt.Errorf("%w: unable to sort by %v", xerrors.ErrBadRequest, filter.SortBy)
+	}
+
+	dates := []struct {
+		key    string
+		target *sql.NullString
+	}{
+		{"date_from", &filter.DateFrom},
+		{"date_to", &filter.DateTo},
+		{"posting_date_from", &filter.PostingDateFrom},
+		{"posting_date_to", &filter.PostingDateTo},
+	}
+	for _, date := range dates {
+		value := values.Get(date.key)
+		if value == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return DocumentFilter{}, fmt.Errorf("%w: %v has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest, date.key)
+		}
+
+		*date.target = sql.NullString{Valid: true, String: value}
+	}
+
+	filter.Reference = database.ContainsPattern(values.Get("reference"))
+	filter.Description = database.ContainsPattern(values.Get("description"))
+
+	integers := []struct {
+		key    string
+		target *sql.NullInt64
+	}{
+		{"currency_id", &filter.CurrencyID},
+		{"account_id", &filter.AccountID},
+		{"amount_min", &filter.AmountMin},
+		{"amount_max", &filter.AmountMax},
+	}
+	for _, integer := range integers {
+		value := values.Get(integer.key)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return DocumentFilter{}, fmt.Errorf("%w: unable to convert %v to integer", xerrors.ErrBadRequest, integer.key)
+		}
+
+		*integer.target = sql.NullInt64{Valid: true, Int64: n}
+	}
+
+	return filter, nil
+}
+
+func (ui UI) additionalAssetData(ctx context.Context, w http.ResponseWriter, r *http.Request, asset *Asset) (assetData, error) {
+	accounts, err := ui.service.accounts(ctx, AccountFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return assetData{}, err
+	}
+
+	currencies, err := ui.service.currencies(ctx)
+	if err != nil {
+		return assetData{}, err
+	}
+
+	var schedule []DepreciationPeriod
+	if asset != nil {
+		schedule, err = ui.service.assetSchedule(ctx, *asset)
+		if err != nil {
+			return assetData{}, err
+		}
+	}
+
+	return assetData{
+		Message:    flash.Get(w, r),
+		Resource:   asset,
+		Accounts:   accounts,
+		Currencies: currencies,
+		Schedule:   schedule,
+	}, nil
+}
+
+func (ui UI) makeAssetFilter(ctx context.Context, values url.Values) (AssetFilter, error) {
+	filter := AssetFilter{}
+
+	if disposed := values.Get("disposed"); disposed != "" {
+		disposed, err := strconv.ParseBool(disposed)
+		if err != nil {
+			return AssetFilter{}, fmt.Errorf("%w: unable to convert disposed to boolean", xerrors.ErrBadRequest)
+		}
+
+		filter.Disposed = sql.NullBool{Valid: true, Bool: disposed}
+	}
+
+	return filter, nil
+}
+
+func (ui UI) disposeAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params AssetDisposalParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		slog.Error("Unable to decode form", "error", err)
+		http.Error(w, "unable to decode form", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := ui.service.disposeAsset(r.Context(), id, params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to dispose asset", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.EntryUpdated(w)
+	http.Redirect(w, r, asset.Redirect(), http.StatusFound)
+}
+
+func (ui UI) assetScheduleView(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if value := r.URL.Query().Get("year"); value != "" {
+		var err error
+		year, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "malformatted year", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := ui.service.assetHistorySchedule(r.Context(), year)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to create asset history schedule", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := assetScheduleData{
+		Message:   flash.Get(w, r),
+		Year:      year,
+		Resources: rows,
+	}
+
+	if err := ui.templates["asset-schedule"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) depreciationRunListView(w http.ResponseWriter, r *http.Request) {
+	runs, err := ui.service.depreciationRuns(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query depreciation runs", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	data := depreciationRunsData{
+		Message:   flash.Get(w, r),
+		Resources: runs,
+	}
+
+	if err := ui.templates["depreciation-run-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) createDepreciationRun(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params DepreciationRunParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		slog.Error("Unable to decode form", "error", err)
+		http.Error(w, "unable to decode form", http.StatusBadRequest)
+		return
+	}
+
+	run, err := ui.service.createDepreciationRun(r.Context(), params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to create depreciation run", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.EntryCreated(w)
+	http.Redirect(w, r, run.Redirect(), http.StatusFound)
 }
 
 func parseDocumentForm(values url.Values) (DocumentParams, error) {
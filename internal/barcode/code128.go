@@ -0,0 +1,125 @@
+// Package barcode encodes barcodes as modules, the narrowest bars and spaces of a symbol. Linear barcodes are a row of
+// modules, QR codes a grid. Drawing them is left to the caller, see pdf.Document.Bars and pdf.Document.Matrix.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Code 128 symbol values with a special meaning.
+const (
+	code128CodeB  = 100
+	code128CodeC  = 99
+	code128FNC1   = 102
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes s as Code 128 barcode, a true module is a bar. Runs of at least four digits are encoded in code set C
+// with two digits per symbol, everything else in code set B, which covers printable ASCII. The quiet zones of ten
+// modules on both sides are not included.
+func Code128(s string) ([]bool, error) {
+	return code128(s, false)
+}
+
+// GS1128 encodes a GS1 element string like Code128, marked as GS1-128 by a leading FNC1. Application identifiers are
+// given without parentheses, e.g. "01" followed by a GTIN-14. Only the leading FNC1 is added, so only the last element
+// may have a variable length.
+func GS1128(elements string) ([]bool, error) {
+	return code128(elements, true)
+}
+
+func code128(s string, fnc1 bool) ([]bool, error) {
+	if s == "" {
+		return nil, errors.New("code 128 requires at least one character")
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 32 || s[i] > 126 {
+			return nil, fmt.Errorf("code 128 can not encode %q", s[i])
+		}
+	}
+
+	symbols := code128Symbols(s)
+	if fnc1 {
+		symbols = slices.Insert(symbols, 1, code128FNC1)
+	}
+
+	checksum := symbols[0]
+	for i, symbol := range symbols[1:] {
+		checksum += (i + 1) * symbol
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, symbol := range symbols {
+		bar := true
+		for _, width := range code128Patterns[symbol] {
+			for i := rune(0); i < width-'0'; i++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+
+	return modules, nil
+}
+
+// code128Symbols returns the start symbol and the data symbols of s. Code set C is used for even runs of at least four
+// digits and for strings of digits only, an odd digit before a run is encoded in code set B.
+func code128Symbols(s string) []int {
+	codeC := digitRun(s) >= 4 || digitRun(s) == len(s) && len(s)%2 == 0
+
+	symbols := []int{code128StartB}
+	if codeC {
+		symbols[0] = code128StartC
+	}
+
+	for i := 0; i < len(s); {
+		digits := digitRun(s[i:])
+		switch {
+		case codeC && digits < 2:
+			symbols = append(symbols, code128CodeB)
+			codeC = false
+		case !codeC && digits >= 4 && digits%2 == 0:
+			symbols = append(symbols, code128CodeC)
+			codeC = true
+		}
+
+		if codeC {
+			symbols = append(symbols, int(s[i]-'0')*10+int(s[i+1]-'0'))
+			i += 2
+		} else {
+			symbols = append(symbols, int(s[i])-32)
+			i++
+		}
+	}
+
+	return symbols
+}
+
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// code128Patterns are the widths of the bars and spaces of every symbol value in modules, starting with a bar. Every
+// symbol is eleven modules wide, the stop symbol thirteen.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
@@ -0,0 +1,86 @@
+package barcode
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCode128(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		gs1  bool
+		// Symbols including the start symbol, the checksum and the stop symbol.
+		want []int
+	}{
+		{name: "code set B", s: "ABC", want: []int{104, 33, 34, 35, 1, 106}},
+		{name: "even digits only", s: "1234", want: []int{105, 12, 34, 82, 106}},
+		{name: "odd digits only", s: "123", want: []int{104, 17, 18, 19, 8, 106}},
+		{name: "switch to code set C", s: "AB123456", want: []int{104, 33, 34, 99, 12, 34, 56, 26, 106}},
+		{name: "switch to code set B", s: "12345", want: []int{105, 12, 34, 100, 21, 54, 106}},
+		{name: "odd run", s: "A12345B", want: []int{104, 33, 17, 99, 23, 45, 100, 34, 78, 106}},
+		{name: "GS1-128", s: "0104012345678901", gs1: true, want: []int{105, 102, 1, 4, 1, 23, 45, 67, 89, 1, 49, 106}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encode := Code128
+			if tt.gs1 {
+				encode = GS1128
+			}
+
+			modules, err := encode(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := code128Decode(t, modules); !slices.Equal(got, tt.want) {
+				t.Errorf("symbols of %q = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode128Invalid(t *testing.T) {
+	for _, s := range []string{"", "line\nbreak", "Straße"} {
+		if _, err := Code128(s); err == nil {
+			t.Errorf("Code128(%q) succeeded, want error", s)
+		}
+	}
+}
+
+// code128Decode returns the symbol values of modules.
+func code128Decode(t *testing.T, modules []bool) []int {
+	t.Helper()
+
+	var symbols []int
+	for len(modules) > 0 {
+		width := 11
+		if len(modules) == 13 {
+			width = 13
+		}
+		if len(modules) < width {
+			t.Fatalf("%v modules left, want %v", len(modules), width)
+		}
+
+		var pattern strings.Builder
+		run := 1
+		for i := 1; i <= width; i++ {
+			if i < width && modules[i] == modules[i-1] {
+				run++
+				continue
+			}
+			pattern.WriteByte(byte('0' + run))
+			run = 1
+		}
+
+		symbol := slices.Index(code128Patterns[:], pattern.String())
+		if symbol < 0 || !modules[0] {
+			t.Fatalf("%v is not a symbol", pattern.String())
+		}
+		symbols = append(symbols, symbol)
+		modules = modules[width:]
+	}
+
+	return symbols
+}
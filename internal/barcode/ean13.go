@@ -0,0 +1,58 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// EAN13 encodes a GTIN-13, or a GTIN-12 which is encoded with a leading zero, as EAN-13 barcode of 95 modules. The check
+// digit has to be correct. The quiet zones, eleven modules left and seven right, are not included.
+func EAN13(gtin string) ([]bool, error) {
+	if len(gtin) == 12 {
+		gtin = "0" + gtin
+	}
+	if len(gtin) != 13 || !ValidGTIN(gtin) {
+		return nil, fmt.Errorf("%v is not a valid GTIN-13", gtin)
+	}
+
+	var modules []bool
+	add := func(pattern string) {
+		for i := 0; i < len(pattern); i++ {
+			modules = append(modules, pattern[i] == '1')
+		}
+	}
+
+	// The first digit is not encoded as bars, it selects which digits of the left half use the G codes.
+	parity := ean13Parity[gtin[0]-'0']
+	add("101")
+	for i := 1; i <= 6; i++ {
+		digit := gtin[i] - '0'
+		if parity[i-1] == 'G' {
+			add(ean13GCodes[digit])
+		} else {
+			add(ean13LCodes[digit])
+		}
+	}
+	add("01010")
+	for i := 7; i <= 12; i++ {
+		add(ean13RCodes[gtin[i]-'0'])
+	}
+	add("101")
+
+	return modules, nil
+}
+
+var ean13Parity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+var ean13LCodes = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+var ean13GCodes = [10]string{
+	"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111",
+}
+
+var ean13RCodes = [10]string{
+	"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100",
+}
@@ -0,0 +1,51 @@
+package barcode
+
+import (
+	"testing"
+)
+
+func TestEAN13(t *testing.T) {
+	tests := []struct {
+		gtin string
+		want string
+	}{
+		{
+			gtin: "4006381333931",
+			want: "10100011010100111010111101111010001001011001101010100001010000101000010111010010000101100110101",
+		},
+		{
+			gtin: "036000291452",
+			want: "10100011010111101010111100011010001101000110101010110110011101001100110101110010011101101100101",
+		},
+	}
+
+	for _, tt := range tests {
+		modules, err := EAN13(tt.gtin)
+		if err != nil {
+			t.Errorf("EAN13(%q) error = %v", tt.gtin, err)
+			continue
+		}
+		if got := modulesString(modules); got != tt.want {
+			t.Errorf("EAN13(%q) =\n%v, want\n%v", tt.gtin, got, tt.want)
+		}
+	}
+}
+
+func TestEAN13Invalid(t *testing.T) {
+	for _, gtin := range []string{"4006381333932", "96385074", "10614141000415", ""} {
+		if _, err := EAN13(gtin); err == nil {
+			t.Errorf("EAN13(%q) succeeded, want error", gtin)
+		}
+	}
+}
+
+func modulesString(modules []bool) string {
+	b := make([]byte, len(modules))
+	for i, dark := range modules {
+		b[i] = '0'
+		if dark {
+			b[i] = '1'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,53 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CheckDigit returns the GS1 check digit of digits, which are all digits of a GTIN or another GS1 key except the check
+// digit itself.
+func CheckDigit(digits string) (byte, error) {
+	if digits == "" {
+		return 0, errors.New("check digits require at least one digit")
+	}
+
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		digit := digits[len(digits)-1-i]
+		if digit < '0' || digit > '9' {
+			return 0, fmt.Errorf("%q is not a digit", digit)
+		}
+
+		// Weights alternate between 3 and 1, starting with 3 at the rightmost digit.
+		weight := 1
+		if i%2 == 0 {
+			weight = 3
+		}
+		sum += int(digit-'0') * weight
+	}
+
+	return byte('0' + (10-sum%10)%10), nil
+}
+
+// ValidGTIN reports whether gtin is a GTIN-8, GTIN-12, GTIN-13 or GTIN-14 with a correct check digit.
+func ValidGTIN(gtin string) bool {
+	switch len(gtin) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	check, err := CheckDigit(gtin[:len(gtin)-1])
+	return err == nil && check == gtin[len(gtin)-1]
+}
+
+// GTIN14 pads a valid GTIN with leading zeros to 14 digits, the form used in GS1 element strings.
+func GTIN14(gtin string) (string, error) {
+	if !ValidGTIN(gtin) {
+		return "", fmt.Errorf("%v is not a valid GTIN", gtin)
+	}
+
+	return strings.Repeat("0", 14-len(gtin)) + gtin, nil
+}
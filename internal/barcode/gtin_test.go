@@ -0,0 +1,79 @@
+package barcode
+
+import (
+	"testing"
+)
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+		err    bool
+	}{
+		{digits: "400638133393", want: '1'},
+		{digits: "03600029145", want: '2'},
+		{digits: "9638507", want: '4'},
+		{digits: "1061414100041", want: '5'},
+		{digits: "000000000000", want: '0'},
+		{digits: "", err: true},
+		{digits: "40063813339a", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := CheckDigit(tt.digits)
+		if (err != nil) != tt.err {
+			t.Errorf("CheckDigit(%q) error = %v, want error %v", tt.digits, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("CheckDigit(%q) = %q, want %q", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestValidGTIN(t *testing.T) {
+	tests := []struct {
+		gtin string
+		want bool
+	}{
+		{gtin: "96385074", want: true},
+		{gtin: "036000291452", want: true},
+		{gtin: "4006381333931", want: true},
+		{gtin: "10614141000415", want: true},
+		{gtin: "4006381333932", want: false},
+		{gtin: "400638133393", want: false},
+		{gtin: "40063813339a1", want: false},
+		{gtin: "9638507", want: false},
+		{gtin: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidGTIN(tt.gtin); got != tt.want {
+			t.Errorf("ValidGTIN(%q) = %v, want %v", tt.gtin, got, tt.want)
+		}
+	}
+}
+
+func TestGTIN14(t *testing.T) {
+	tests := []struct {
+		gtin string
+		want string
+		err  bool
+	}{
+		{gtin: "96385074", want: "00000096385074"},
+		{gtin: "4006381333931", want: "04006381333931"},
+		{gtin: "10614141000415", want: "10614141000415"},
+		{gtin: "4006381333932", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := GTIN14(tt.gtin)
+		if (err != nil) != tt.err {
+			t.Errorf("GTIN14(%q) error = %v, want error %v", tt.gtin, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GTIN14(%q) = %q, want %q", tt.gtin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package barcode
+
+import (
+	"fmt"
+)
+
+// qrBlocks describes the error correction blocks of a QR code version at level M: every block has ecc error correction
+// codewords, the first group has count1 blocks with data1 data codewords, the second count2 blocks with one more.
+type qrBlocks struct {
+	ecc    int
+	count1 int
+	data1  int
+	count2 int
+}
+
+// QR encodes data as QR code in byte mode with error correction level M, which restores up to 15% of the symbol. The
+// smallest version that fits is used. The result is indexed by row and column, a true module is dark. The quiet zone of
+// four modules on every side is not included.
+func QR(data []byte) ([][]bool, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if qrHeaderBits(v)+8*len(data) <= 8*qrDataCodewords(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%v bytes do not fit into a QR code", len(data))
+	}
+
+	qr := newQRSymbol(version)
+	qr.drawCodewords(qrCodewords(version, data))
+
+	// Choose the mask with the lowest penalty, the format bits are part of the evaluated symbol.
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormatBits(mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask)
+	}
+	qr.applyMask(best)
+	qr.drawFormatBits(best)
+
+	return qr.modules, nil
+}
+
+func qrHeaderBits(version int) int {
+	// Mode indicator and character count.
+	if version <= 9 {
+		return 4 + 8
+	}
+	return 4 + 16
+}
+
+func qrDataCodewords(version int) int {
+	blocks := qrLevelM[version-1]
+	return blocks.count1*blocks.data1 + blocks.count2*(blocks.data1+1)
+}
+
+// qrCodewords returns the data codewords of data followed by the error correction codewords, interleaved over the
+// blocks of the version.
+func qrCodewords(version int, data []byte) []byte {
+	var bits qrBitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), qrHeaderBits(version)-4)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * qrDataCodewords(version)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := bits.bytes()
+
+	blocks := qrLevelM[version-1]
+	generator := reedSolomonGenerator(blocks.ecc)
+	var dataBlocks, eccBlocks [][]byte
+	for i := 0; i < blocks.count1+blocks.count2; i++ {
+		size := blocks.data1
+		if i >= blocks.count1 {
+			size++
+		}
+		dataBlocks = append(dataBlocks, codewords[:size])
+		eccBlocks = append(eccBlocks, reedSolomonRemainder(codewords[:size], generator))
+		codewords = codewords[size:]
+	}
+
+	var result []byte
+	for i := 0; i <= blocks.data1; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < blocks.ecc; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+
+	return result
+}
+
+type qrBitBuffer []bool
+
+func (bits *qrBitBuffer) append(value int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bits = append(*bits, value>>i&1 == 1)
+	}
+}
+
+func (bits qrBitBuffer) bytes() []byte {
+	result := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) with the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(a byte, b byte) byte {
+	var product byte
+	for i := 7; i >= 0; i-- {
+		carry := product & 0x80
+		product <<= 1
+		if carry != 0 {
+			product ^= 0x1d
+		}
+		if b>>i&1 == 1 {
+			product ^= a
+		}
+	}
+	return product
+}
+
+// reedSolomonGenerator returns the coefficients of the generator polynomial of the given degree without the leading
+// one, highest power first.
+func reedSolomonGenerator(degree int) []byte {
+	generator := make([]byte, degree)
+	generator[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		// Multiply by (x - root), subtraction is addition in GF(2^8).
+		for j := 0; j < degree; j++ {
+			generator[j] = gfMultiply(generator[j], root)
+			if j+1 < degree {
+				generator[j] ^= generator[j+1]
+			}
+		}
+		root = gfMultiply(root, 2)
+	}
+
+	return generator
+}
+
+func reedSolomonRemainder(data []byte, generator []byte) []byte {
+	remainder := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, coefficient := range generator {
+			remainder[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return remainder
+}
+
+// qrSymbol is a QR code under construction, function marks the modules of the finder, timing and alignment patterns and
+// of the format and version information, which are not masked.
+type qrSymbol struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+func newQRSymbol(version int) *qrSymbol {
+	size := 17 + 4*version
+	qr := &qrSymbol{
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+	for i := range qr.modules {
+		qr.modules[i] = make([]bool, size)
+		qr.function[i] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		qr.set(6, i, i%2 == 0)
+		qr.set(i, 6, i%2 == 0)
+	}
+
+	qr.drawFinder(3, 3)
+	qr.drawFinder(size-4, 3)
+	qr.drawFinder(3, size-4)
+
+	positions := qrAlignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Alignment patterns are left out where they would overlap a finder pattern.
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			qr.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format bits, they are drawn once the mask is known.
+	qr.drawFormatBits(0)
+
+	if version >= 7 {
+		bits := version<<12 | bchRemainder(version, 0x1f25, 12)
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 == 1
+			a, b := size-11+i%3, i/3
+			qr.set(a, b, dark)
+			qr.set(b, a, dark)
+		}
+	}
+
+	return qr
+}
+
+// set sets a function module, x is the column and y the row.
+func (qr *qrSymbol) set(x int, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+// drawFinder draws a finder pattern with its separator around the center (x, y).
+func (qr *qrSymbol) drawFinder(x int, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			distance := max(abs(dx), abs(dy))
+			if 0 <= x+dx && x+dx < qr.size && 0 <= y+dy && y+dy < qr.size {
+				qr.set(x+dx, y+dy, distance != 2 && distance != 4)
+			}
+		}
+	}
+}
+
+func (qr *qrSymbol) drawAlignment(x int, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			qr.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information of level M and the mask, and the dark module.
+func (qr *qrSymbol) drawFormatBits(mask int) {
+	// Level M is encoded as 00.
+	data := mask
+	bits := (data<<10 | bchRemainder(data, 0x537, 10)) ^ 0x5412
+	bit := func(i int) bool {
+		return bits>>i&1 == 1
+	}
+
+	for i := 0; i <= 5; i++ {
+		qr.set(8, i, bit(i))
+	}
+	qr.set(8, 7, bit(6))
+	qr.set(8, 8, bit(7))
+	qr.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		qr.set(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.set(8, qr.size-15+i, bit(i))
+	}
+	qr.set(8, qr.size-8, true)
+}
+
+// drawCodewords places the codewords in two module wide columns zigzagging up and down from the bottom right corner,
+// skipping the function modules.
+func (qr *qrSymbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern is skipped entirely.
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < qr.size; vertical++ {
+			y := vertical
+			if upward {
+				y = qr.size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if qr.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				qr.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the mask, applying it twice restores the symbol.
+func (qr *qrSymbol) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.function[y][x] {
+				continue
+			}
+
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty rates how hard the symbol is to read: long runs of one color, 2x2 blocks of one color, patterns that look
+// like finder patterns and an unbalanced share of dark modules.
+func (qr *qrSymbol) penalty() int {
+	penalty := 0
+	line := make([]bool, qr.size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < qr.size; i++ {
+			for j := 0; j < qr.size; j++ {
+				if vertical {
+					line[j] = qr.modules[j][i]
+				} else {
+					line[j] = qr.modules[i][j]
+				}
+			}
+			penalty += qrLinePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				color := qr.modules[y][x]
+				if qr.modules[y-1][x] == color && qr.modules[y][x-1] == color && qr.modules[y-1][x-1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	total := qr.size * qr.size
+	deviation := abs(dark*20 - total*10)
+	penalty += (deviation / total) * 10
+
+	return penalty
+}
+
+var qrFinderLike = [2][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func qrLinePenalty(line []bool) int {
+	penalty := 0
+
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += run - 2
+		}
+		run = 1
+	}
+
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pattern := range qrFinderLike {
+			match := true
+			for j, dark := range pattern {
+				if line[i+j] != dark {
+					match = false
+					break
+				}
+			}
+			if match {
+				penalty += 40
+			}
+		}
+	}
+
+	return penalty
+}
+
+// bchRemainder returns the BCH error correction bits of data, the remainder of data shifted by n bits divided by the
+// generator polynomial of degree n.
+func bchRemainder(data int, generator int, n int) int {
+	remainder := data << n
+	for i := bitLength(remainder) - 1; i >= n; i-- {
+		if remainder>>i&1 == 1 {
+			remainder ^= generator << (i - n)
+		}
+	}
+	return remainder
+}
+
+func bitLength(n int) int {
+	length := 0
+	for ; n > 0; n >>= 1 {
+		length++
+	}
+	return length
+}
+
+// qrAlignmentPositions returns the row and column centers of the alignment patterns.
+func qrAlignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+
+	count := version/7 + 2
+	step := (version*4 + count*2 + 1) / (count*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, 17+4*version-7; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// qrLevelM are the error correction blocks of the versions 1 to 40 at level M.
+var qrLevelM = [40]qrBlocks{
+	{10, 1, 16, 0}, {16, 1, 28, 0}, {26, 1, 44, 0}, {18, 2, 32, 0}, {24, 2, 43, 0},
+	{16, 4, 27, 0}, {18, 4, 31, 0}, {22, 2, 38, 2}, {22, 3, 36, 2}, {26, 4, 43, 1},
+	{30, 1, 50, 4}, {22, 6, 36, 2}, {22, 8, 37, 1}, {24, 4, 40, 5}, {24, 5, 41, 5},
+	{28, 7, 45, 3}, {28, 10, 46, 1}, {26, 9, 43, 4}, {26, 3, 44, 11}, {26, 3, 41, 13},
+	{26, 17, 42, 0}, {28, 17, 46, 0}, {28, 4, 47, 14}, {28, 6, 45, 14}, {28, 8, 47, 13},
+	{28, 19, 46, 4}, {28, 22, 45, 3}, {28, 3, 45, 23}, {28, 21, 45, 7}, {28, 19, 47, 10},
+	{28, 2, 46, 29}, {28, 10, 46, 23}, {28, 14, 46, 21}, {28, 14, 46, 23}, {28, 12, 47, 26},
+	{28, 6, 47, 34}, {28, 29, 46, 14}, {28, 13, 46, 32}, {28, 40, 47, 7}, {28, 18, 47, 31},
+}
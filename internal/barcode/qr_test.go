@@ -0,0 +1,59 @@
+package barcode
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The golden symbols in testdata were checked against rsc.io/qr and decode with ZXing. Dark modules are written as #.
+func TestQR(t *testing.T) {
+	tests := []struct {
+		golden string
+		data   string
+	}{
+		{golden: "testdata/qr-v1.txt", data: "ITEM-4711"},
+		{golden: "testdata/qr-v7.txt", data: "https://id.gs1.org/01/04012345678901/10/BATCH-2024-0001/21/SERIAL-0000000001?linktype=all&label=goods-receipt"},
+		{golden: "testdata/qr-v10.txt", data: strings.Repeat("Apex pallet label 0123456789. ", 7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			want, err := os.ReadFile(tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			modules, err := QR([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			for _, row := range modules {
+				for _, dark := range row {
+					if dark {
+						got.WriteByte('#')
+					} else {
+						got.WriteByte('.')
+					}
+				}
+				got.WriteByte('\n')
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("QR(%q) =\n%s\nwant\n%s", tt.data, got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestQRTooLong(t *testing.T) {
+	// Version 40 at level M holds 2331 bytes.
+	if _, err := QR(make([]byte, 2331)); err != nil {
+		t.Errorf("QR of 2331 bytes error = %v", err)
+	}
+	if _, err := QR(make([]byte, 2332)); err == nil {
+		t.Error("QR of 2332 bytes succeeded, want error")
+	}
+}
@@ -0,0 +1,108 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+// Store persists binary objects. Objects are addressed by a key derived from their content.
+type Store interface {
+	// Put stores the content of r and returns the key under which it can be opened again.
+	Put(ctx context.Context, r io.Reader) (Object, error)
+	// Open returns a reader for the object stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type Object struct {
+	Key  string
+	Size int64
+}
+
+// LocalStore stores objects on the local filesystem. Keys are SHA-256 hashes of the content, storing the same content twice
+// results in a single file.
+type LocalStore struct {
+	root string
+}
+
+func MakeLocalStore(root string) (LocalStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return LocalStore{}, fmt.Errorf("unable to create blob directory: %w", err)
+	}
+
+	return LocalStore{
+		root: root,
+	}, nil
+}
+
+func (s LocalStore) Put(ctx context.Context, r io.Reader) (Object, error) {
+	tmp, err := os.CreateTemp(s.root, "upload-*")
+	if err != nil {
+		return Object{}, fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Object{}, fmt.Errorf("unable to write object: %w", err)
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(key)
+
+	if _, err := os.Stat(path); err == nil {
+		return Object{Key: key, Size: size}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return Object{}, fmt.Errorf("unable to create object directory: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Object{}, fmt.Errorf("unable to move object into place: %w", err)
+	}
+
+	return Object{Key: key, Size: size}, nil
+}
+
+func (s LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("%w: malformatted blob key", xerrors.ErrBadRequest)
+	}
+
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, xerrors.Join(xerrors.ErrNotFound, err)
+	}
+	if err != nil {
+		return nil, xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return f, nil
+}
+
+// path spreads objects over subdirectories named after the first two characters of the key to keep directories small.
+func (s LocalStore) path(key string) string {
+	return filepath.Join(s.root, key[:2], key)
+}
+
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
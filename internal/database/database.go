@@ -2,15 +2,26 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/tombuente/apex/internal/xerrors"
 )
 
-func One[T any](ctx context.Context, db *pgx.Conn, query string, args ...any) (T, error) {
+// Querier is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx, so queries can run inside and outside of
+// transactions. Beginning a transaction on a pool acquires a connection for the lifetime of the transaction, beginning
+// one on a transaction creates a savepoint.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+func One[T any](ctx context.Context, db Querier, query string, args ...any) (T, error) {
 	var defaultT T
 
 	rows, err := db.Query(ctx, query, args...)
@@ -26,7 +37,7 @@ func One[T any](ctx context.Context, db *pgx.Conn, query string, args ...any) (T
 	return i, nil
 }
 
-func Many[T any](ctx context.Context, db *pgx.Conn, query string, args ...any) ([]T, error) {
+func Many[T any](ctx context.Context, db Querier, query string, args ...any) ([]T, error) {
 	var defaultT []T
 
 	rows, err := db.Query(ctx, query, args...)
@@ -47,6 +58,29 @@ func Many[T any](ctx context.Context, db *pgx.Conn, query string, args ...any) (
 	return is, nil
 }
 
+// Transaction runs fn inside of a transaction. The transaction is committed if fn returns no error, otherwise it is rolled back.
+// On a pool every call runs on its own connection, so concurrent transactions do not interfere with each other.
+func Transaction(ctx context.Context, db Querier, fn func(tx pgx.Tx) error) error {
+	err := pgx.BeginFunc(ctx, db, fn)
+	if err != nil && !errors.Is(err, xerrors.ErrInternal) && !errors.Is(err, xerrors.ErrNotFound) && !errors.Is(err, xerrors.ErrBadRequest) {
+		return wrapError(err)
+	}
+
+	return err
+}
+
+// ContainsPattern returns an ILIKE pattern matching text anywhere, wildcards in text are matched literally. Empty text
+// returns a null pattern, which disables the filter.
+func ContainsPattern(text string) sql.NullString {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return sql.NullString{}
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+	return sql.NullString{Valid: true, String: "%" + escaped + "%"}
+}
+
 func wrapError(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return xerrors.Join(xerrors.ErrNotFound, err)
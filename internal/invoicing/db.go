@@ -0,0 +1,193 @@
+package invoicing
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/database"
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+//go:embed schema.sql
+var Schema string
+
+type Database struct {
+	db database.Querier
+}
+
+func MakeDatabase(db database.Querier) Database {
+	return Database{
+		db: db,
+	}
+}
+
+func (db Database) invoice(ctx context.Context, id int64) (Invoice, error) {
+	const headerQuery = `
+SELECT *
+FROM invoicing.invoices
+WHERE id = $1
+`
+
+	header, err := database.One[InvoiceHeader](ctx, db.db, headerQuery, id)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	const linesQuery = `
+SELECT *
+FROM invoicing.invoice_lines
+WHERE invoice_id = $1
+ORDER BY id ASC
+`
+
+	lines, err := database.Many[InvoiceLine](ctx, db.db, linesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return Invoice{}, err
+	}
+
+	return Invoice{InvoiceHeader: header, Lines: lines}, nil
+}
+
+func (db Database) invoices(ctx context.Context, filter InvoiceFilter) ([]InvoiceSummary, error) {
+	const query = `
+SELECT i.*, COALESCE(SUM(l.net_amount), 0) AS net_total, COALESCE(SUM(l.gross_amount), 0) AS gross_total
+FROM invoicing.invoices i
+LEFT JOIN invoicing.invoice_lines l ON l.invoice_id = i.id
+WHERE
+	(i.customer_id = $1 OR $1 IS NULL) AND
+	(i.status      = $2 OR $2 IS NULL)
+GROUP BY i.id
+ORDER BY i.id DESC
+`
+
+	return database.Many[InvoiceSummary](ctx, db.db, query, filter.CustomerID, filter.Status)
+}
+
+func (db Database) createInvoice(ctx context.Context, header InvoiceHeaderParams, lines []invoiceLineValues) (Invoice, error) {
+	const query = `
+INSERT INTO invoicing.invoices (customer_id, date, due_date, currency_id, receivable_account_id, revenue_account_id, tax_account_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *
+`
+
+	var invoice Invoice
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		invoice.InvoiceHeader, err = database.One[InvoiceHeader](ctx, tx, query, header.CustomerID, header.Date, header.DueDate, header.CurrencyID,
+			header.ReceivableAccountID, header.RevenueAccountID, header.TaxAccountID)
+		if err != nil {
+			return err
+		}
+
+		invoice.Lines, err = insertInvoiceLines(ctx, tx, invoice.ID, lines)
+		return err
+	})
+
+	return invoice, err
+}
+
+// updateInvoice updates the header of a draft invoice and replaces its lines.
+func (db Database) updateInvoice(ctx context.Context, id int64, header InvoiceHeaderParams, lines []invoiceLineValues) (Invoice, error) {
+	const query = `
+UPDATE invoicing.invoices
+SET
+	customer_id           = $2,
+	date                  = $3,
+	due_date              = $4,
+	currency_id           = $5,
+	receivable_account_id = $6,
+	revenue_account_id    = $7,
+	tax_account_id        = $8
+WHERE id = $1 AND status = 'draft'
+RETURNING *
+`
+
+	const deleteLinesQuery = `
+DELETE FROM invoicing.invoice_lines
+WHERE invoice_id = $1
+`
+
+	var invoice Invoice
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		invoice.InvoiceHeader, err = database.One[InvoiceHeader](ctx, tx, query, id, header.CustomerID, header.Date, header.DueDate, header.CurrencyID,
+			header.ReceivableAccountID, header.RevenueAccountID, header.TaxAccountID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteLinesQuery, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		invoice.Lines, err = insertInvoiceLines(ctx, tx, id, lines)
+		return err
+	})
+
+	return invoice, err
+}
+
+func insertInvoiceLines(ctx context.Context, q database.Querier, invoiceID int64, lines []invoiceLineValues) ([]InvoiceLine, error) {
+	const query = `
+INSERT INTO invoicing.invoice_lines (invoice_id, item_id, description, quantity, unit_id, net_price, gross_price, net_amount, gross_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *
+`
+
+	var invoiceLines []InvoiceLine
+	for _, line := range lines {
+		invoiceLine, err := database.One[InvoiceLine](ctx, q, query, invoiceID, line.ItemID, line.Description, line.Quantity, line.UnitID,
+			line.NetPrice, line.GrossPrice, line.NetAmount, line.GrossAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		invoiceLines = append(invoiceLines, invoiceLine)
+	}
+
+	return invoiceLines, nil
+}
+
+// finalizeInvoice finalizes a draft invoice and posts its document in one transaction, so an invoice is never finalized
+// without its document and no document is posted for an invoice that was not finalized.
+func (db Database) finalizeInvoice(ctx context.Context, id int64, document accounting.DocumentParams) (InvoiceHeader, error) {
+	const statusQuery = `
+UPDATE invoicing.invoices
+SET status = $3
+WHERE id = $1 AND status = $2
+RETURNING *
+`
+
+	const documentQuery = `
+UPDATE invoicing.invoices
+SET document_id = $2
+WHERE id = $1
+RETURNING *
+`
+
+	var header InvoiceHeader
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		// Claim the invoice first, so it can not be posted twice.
+		_, err := database.One[InvoiceHeader](ctx, tx, statusQuery, id, InvoiceStatusDraft, InvoiceStatusFinalized)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return fmt.Errorf("%w: invoice has already been finalized", xerrors.ErrBadRequest)
+		}
+		if err != nil {
+			return err
+		}
+
+		posted, err := accounting.InsertDocument(ctx, tx, document)
+		if err != nil {
+			return err
+		}
+
+		header, err = database.One[InvoiceHeader](ctx, tx, documentQuery, id, posted.ID)
+		return err
+	})
+
+	return header, err
+}
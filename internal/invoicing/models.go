@@ -0,0 +1,138 @@
+package invoicing
+
+import (
+	"database/sql"
+	"strconv"
+)
+
+const (
+	InvoiceStatusDraft     = "draft"
+	InvoiceStatusFinalized = "finalized"
+)
+
+type Invoice struct {
+	InvoiceHeader
+	Lines []InvoiceLine `json:"lines"`
+}
+
+// Should only be embedded
+type InvoiceHeader struct {
+	ID                  int64         `json:"id" db:"id"`
+	CustomerID          int64         `json:"customer_id" db:"customer_id"`
+	Date                string        `json:"date" db:"date"`
+	DueDate             string        `json:"due_date" db:"due_date"`
+	CurrencyID          int64         `json:"currency_id" db:"currency_id"`
+	ReceivableAccountID int64         `json:"receivable_account_id" db:"receivable_account_id"`
+	RevenueAccountID    int64         `json:"revenue_account_id" db:"revenue_account_id"`
+	TaxAccountID        int64         `json:"tax_account_id" db:"tax_account_id"`
+	Status              string        `json:"status" db:"status"`
+	DocumentID          sql.NullInt64 `json:"document_id" db:"document_id"`
+}
+
+type InvoiceLine struct {
+	ID          int64   `json:"id" db:"id"`
+	InvoiceID   int64   `json:"invoice_id" db:"invoice_id"`
+	ItemID      int64   `json:"item_id" db:"item_id"`
+	Description string  `json:"description" db:"description"`
+	Quantity    float64 `json:"quantity" db:"quantity"`
+	UnitID      int64   `json:"unit_id" db:"unit_id"`
+	NetPrice    int64   `json:"net_price" db:"net_price"`
+	GrossPrice  int64   `json:"gross_price" db:"gross_price"`
+	NetAmount   int64   `json:"net_amount" db:"net_amount"`
+	GrossAmount int64   `json:"gross_amount" db:"gross_amount"`
+}
+
+// InvoiceSummary is an invoice header together with the totals of its lines.
+type InvoiceSummary struct {
+	InvoiceHeader
+	NetTotal   int64 `json:"net_total" db:"net_total"`
+	GrossTotal int64 `json:"gross_total" db:"gross_total"`
+}
+
+type InvoiceParams struct {
+	InvoiceHeaderParams
+	Lines []InvoiceLineParams
+}
+
+type InvoiceHeaderParams struct {
+	CustomerID          int64
+	Date                string
+	DueDate             string
+	CurrencyID          int64
+	ReceivableAccountID int64
+	RevenueAccountID    int64
+	TaxAccountID        int64
+}
+
+// InvoiceLineParams describe a line, empty fields default to the values of the item. Quantity and prices are in the
+// unit of the line, a unit ID of zero stands for the base unit of the item.
+type InvoiceLineParams struct {
+	ItemID      int64
+	Description string
+	Quantity    float64
+	UnitID      int64
+	NetPrice    sql.NullInt64
+	GrossPrice  sql.NullInt64
+}
+
+// invoiceLineValues are the resolved values of a line, see InvoiceLineParams.
+type invoiceLineValues struct {
+	ItemID      int64
+	Description string
+	Quantity    float64
+	UnitID      int64
+	NetPrice    int64
+	GrossPrice  int64
+	NetAmount   int64
+	GrossAmount int64
+}
+
+type InvoiceFilter struct {
+	CustomerID sql.NullInt64
+	Status     sql.NullString
+}
+
+func (invoice Invoice) NetTotal() int64 {
+	var total int64
+	for _, line := range invoice.Lines {
+		total += line.NetAmount
+	}
+	return total
+}
+
+func (invoice Invoice) GrossTotal() int64 {
+	var total int64
+	for _, line := range invoice.Lines {
+		total += line.GrossAmount
+	}
+	return total
+}
+
+func (invoice Invoice) TaxTotal() int64 {
+	return invoice.GrossTotal() - invoice.NetTotal()
+}
+
+// Reference is the invoice number printed on the invoice and used as document reference.
+func (invoice Invoice) Reference() string {
+	return "INV-" + invoice.GetID()
+}
+
+func (invoice Invoice) Finalized() bool {
+	return invoice.Status == InvoiceStatusFinalized
+}
+
+func (invoice Invoice) GetID() string {
+	return strconv.FormatInt(invoice.ID, 10)
+}
+
+func (invoice Invoice) Redirect() string {
+	return "/invoicing/invoices/" + invoice.GetID()
+}
+
+func (invoice InvoiceSummary) GetID() string {
+	return strconv.FormatInt(invoice.ID, 10)
+}
+
+func (invoice InvoiceSummary) Redirect() string {
+	return "/invoicing/invoices/" + invoice.GetID()
+}
@@ -0,0 +1,37 @@
+CREATE SCHEMA IF NOT EXISTS invoicing;
+GRANT ALL ON SCHEMA invoicing TO postgres;
+
+CREATE TABLE IF NOT EXISTS invoicing.invoices(
+	id                    SERIAL       PRIMARY KEY,
+	customer_id           INTEGER      NOT NULL REFERENCES logistics.customers(id),
+	date                  VARCHAR(255) NOT NULL,
+	due_date              VARCHAR(255) NOT NULL,
+	currency_id           INTEGER      NOT NULL REFERENCES accounting.currencies(id),
+	receivable_account_id INTEGER      NOT NULL REFERENCES accounting.accounts(id),
+	revenue_account_id    INTEGER      NOT NULL REFERENCES accounting.accounts(id),
+	tax_account_id        INTEGER      NOT NULL REFERENCES accounting.accounts(id),
+	status                VARCHAR(255) NOT NULL DEFAULT 'draft',
+	document_id           INTEGER      REFERENCES accounting.documents(id)
+);
+
+CREATE TABLE IF NOT EXISTS invoicing.invoice_lines(
+	id           SERIAL        PRIMARY KEY,
+	invoice_id   INTEGER       NOT NULL REFERENCES invoicing.invoices(id) ON DELETE CASCADE,
+	item_id      INTEGER       NOT NULL REFERENCES logistics.items(id),
+	description  TEXT          NOT NULL,
+	quantity     NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+	unit_id      INTEGER       NOT NULL REFERENCES logistics.units(id),
+	net_price    INTEGER       NOT NULL,
+	gross_price  INTEGER       NOT NULL,
+	net_amount   NUMERIC       NOT NULL,
+	gross_amount NUMERIC       NOT NULL
+);
+
+-- Lines of earlier versions are in the base unit of their item.
+ALTER TABLE invoicing.invoice_lines ADD COLUMN IF NOT EXISTS unit_id INTEGER REFERENCES logistics.units(id);
+
+UPDATE invoicing.invoice_lines SET unit_id = items.base_unit_id
+FROM logistics.items
+WHERE items.id = invoice_lines.item_id AND invoice_lines.unit_id IS NULL;
+
+ALTER TABLE invoicing.invoice_lines ALTER COLUMN unit_id SET NOT NULL;
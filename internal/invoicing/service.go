@@ -0,0 +1,200 @@
+package invoicing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/logistics"
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+// Service creates invoices from logistics items and posts them to the accounting ledger.
+type Service struct {
+	db         Database
+	logistics  logistics.Service
+	accounting accounting.Service
+}
+
+func MakeService(db Database, logistics logistics.Service, accounting accounting.Service) Service {
+	return Service{
+		db:         db,
+		logistics:  logistics,
+		accounting: accounting,
+	}
+}
+
+func (s Service) invoice(ctx context.Context, id int64) (Invoice, error) {
+	return s.db.invoice(ctx, id)
+}
+
+func (s Service) invoices(ctx context.Context, filter InvoiceFilter) ([]InvoiceSummary, error) {
+	return s.db.invoices(ctx, filter)
+}
+
+func (s Service) createInvoice(ctx context.Context, params InvoiceParams) (Invoice, error) {
+	lines, err := s.resolveInvoice(ctx, params)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return s.db.createInvoice(ctx, params.InvoiceHeaderParams, lines)
+}
+
+func (s Service) updateInvoice(ctx context.Context, id int64, params InvoiceParams) (Invoice, error) {
+	invoice, err := s.db.invoice(ctx, id)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if invoice.Finalized() {
+		return Invoice{}, fmt.Errorf("%w: finalized invoices can not be changed", xerrors.ErrBadRequest)
+	}
+
+	lines, err := s.resolveInvoice(ctx, params)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return s.db.updateInvoice(ctx, id, params.InvoiceHeaderParams, lines)
+}
+
+// resolveInvoice validates the params and fills in item defaults of the lines. Prices only default to the prices of
+// items priced in the currency of the invoice.
+func (s Service) resolveInvoice(ctx context.Context, params InvoiceParams) ([]invoiceLineValues, error) {
+	date, err := time.Parse(time.DateOnly, params.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	dueDate, err := time.Parse(time.DateOnly, params.DueDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: due date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if dueDate.Before(date) {
+		return nil, fmt.Errorf("%w: due date can not be before the invoice date", xerrors.ErrBadRequest)
+	}
+
+	if _, err := s.logistics.Customer(ctx, params.CustomerID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown customer", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	currency, err := s.accounting.Currency(ctx, params.CurrencyID)
+	if err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown currency", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if len(params.Lines) == 0 {
+		return nil, fmt.Errorf("%w: invoice needs at least one line", xerrors.ErrBadRequest)
+	}
+
+	lines := make([]invoiceLineValues, 0, len(params.Lines))
+	for i, lineParams := range params.Lines {
+		item, err := s.logistics.Item(ctx, lineParams.ItemID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: line %v references an unknown item", xerrors.ErrBadRequest, i+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		conversion, err := s.logistics.UnitConversion(ctx, item.ID, lineParams.UnitID)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+
+		if err := conversion.Unit.Validate(lineParams.Quantity); err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+
+		// Item prices only default lines of invoices in the currency the item is priced in.
+		if (!lineParams.NetPrice.Valid || !lineParams.GrossPrice.Valid) && item.Currency != currency.ISO {
+			return nil, fmt.Errorf("%w: line %v needs a price, %v is priced in %v and the invoice is in %v", xerrors.ErrBadRequest,
+				i+1, item.Name, item.Currency, currency.ISO)
+		}
+
+		line := invoiceLineValues{
+			ItemID:      item.ID,
+			Description: strings.TrimSpace(lineParams.Description),
+			Quantity:    lineParams.Quantity,
+			UnitID:      conversion.Unit.ID,
+			NetPrice:    int64(math.Round(float64(item.NetPrice) * conversion.Factor)),
+			GrossPrice:  int64(math.Round(float64(item.GrossPrice) * conversion.Factor)),
+		}
+		if line.Description == "" {
+			line.Description = item.Name
+		}
+		if lineParams.NetPrice.Valid {
+			line.NetPrice = lineParams.NetPrice.Int64
+		}
+		if lineParams.GrossPrice.Valid {
+			line.GrossPrice = lineParams.GrossPrice.Int64
+		}
+
+		if line.NetPrice < 0 || line.GrossPrice < line.NetPrice {
+			return nil, fmt.Errorf("%w: gross price of line %v can not be lower than its net price", xerrors.ErrBadRequest, i+1)
+		}
+
+		line.NetAmount = int64(math.Round(line.Quantity * float64(line.NetPrice)))
+		line.GrossAmount = int64(math.Round(line.Quantity * float64(line.GrossPrice)))
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// finalizeInvoice locks an invoice and posts it: the receivable is debited with the gross total, revenue and tax are credited
+// with the net total and the difference.
+func (s Service) finalizeInvoice(ctx context.Context, id int64) (Invoice, error) {
+	invoice, err := s.db.invoice(ctx, id)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	customer, err := s.logistics.Customer(ctx, invoice.CustomerID)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	if invoice.Status != InvoiceStatusDraft {
+		return Invoice{}, fmt.Errorf("%w: invoice has already been finalized", xerrors.ErrBadRequest)
+	}
+
+	description := fmt.Sprintf("Invoice %v to %v", invoice.ID, customer.Name)
+	document := accounting.DocumentParams{
+		DocumentHeaderParams: accounting.DocumentHeaderParams{
+			Description: description,
+			Date:        invoice.Date,
+			PostingDate: invoice.Date,
+			Reference:   invoice.Reference(),
+			CurrencyID:  invoice.CurrencyID,
+		},
+		Positions: []accounting.DocumentPositionParams{
+			{Description: description, AccountID: invoice.ReceivableAccountID, TypeID: accounting.PositionTypeDebit, Amount: invoice.GrossTotal()},
+			{Description: description, AccountID: invoice.RevenueAccountID, TypeID: accounting.PositionTypeCredit, Amount: invoice.NetTotal()},
+		},
+	}
+	if tax := invoice.TaxTotal(); tax != 0 {
+		document.Positions = append(document.Positions, accounting.DocumentPositionParams{
+			Description: "Tax on " + description, AccountID: invoice.TaxAccountID, TypeID: accounting.PositionTypeCredit, Amount: tax,
+		})
+	}
+
+	invoice.InvoiceHeader, err = s.db.finalizeInvoice(ctx, id, document)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return invoice, nil
+}
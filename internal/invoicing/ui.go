@@ -0,0 +1,344 @@
+package invoicing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/flash"
+	"github.com/tombuente/apex/internal/logistics"
+	"github.com/tombuente/apex/internal/pdf"
+	"github.com/tombuente/apex/internal/templates"
+	"github.com/tombuente/apex/internal/xerrors"
+	"github.com/tombuente/apex/internal/xui"
+)
+
+type UI struct {
+	service    Service
+	templates  map[string]*template.Template
+	letterhead pdf.Letterhead
+}
+
+type invoiceData struct {
+	Message    flash.Message
+	Resource   *Invoice
+	Customers  []logistics.Customer
+	Items      []logistics.Item
+	Units      []logistics.Unit
+	Accounts   []accounting.Account
+	Currencies []accounting.Currency
+}
+
+type invoicePrintData struct {
+	Invoice   Invoice
+	Customer  logistics.Customer
+	Address   logistics.Address
+	Currency  accounting.Currency
+	UnitCodes map[int64]string
+}
+
+func NewUIRouter(templateFS fs.FS, service Service, letterhead pdf.Letterhead) (*chi.Mux, error) {
+	ui := UI{
+		service:    service,
+		templates:  make(map[string]*template.Template),
+		letterhead: letterhead,
+	}
+
+	var err error
+	ui.templates, err = templates.Load(templateFS, "invoicing")
+	if err != nil {
+		return nil, fmt.Errorf("unable to load templates: %w", err)
+	}
+
+	r := chi.NewRouter()
+
+	r.Route("/invoices", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalInvoiceData, ui.templates["invoice-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.invoice, ui.makeAdditionalInvoiceData, ui.templates["invoice-detail"]))
+		r.Get("/{id}/print", ui.invoicePrintView)
+		r.Get("/{id}/pdf", ui.invoicePDF)
+		r.Get("/", xui.ListView(ui.makeInvoiceFilter, ui.service.invoices, ui.templates["invoice-list"]))
+		r.Post("/{id}", xui.UpdateWithFormParser(parseInvoiceForm, ui.service.updateInvoice))
+		r.Post("/{id}/finalize", ui.finalizeInvoice)
+		r.Post("/", xui.CreateWithFormParser(parseInvoiceForm, ui.service.createInvoice))
+	})
+
+	return r, nil
+}
+
+func (ui UI) makeAdditionalInvoiceData(ctx context.Context, w http.ResponseWriter, r *http.Request, invoice *Invoice) (invoiceData, error) {
+	customers, err := ui.service.logistics.Customers(ctx, logistics.CustomerFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return invoiceData{}, err
+	}
+
+	items, err := ui.service.logistics.Items(ctx, logistics.ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return invoiceData{}, err
+	}
+
+	units, err := ui.service.logistics.Units(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return invoiceData{}, err
+	}
+
+	accounts, err := ui.service.accounting.Accounts(ctx, accounting.AccountFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return invoiceData{}, err
+	}
+
+	currencies, err := ui.service.accounting.Currencies(ctx)
+	if err != nil {
+		return invoiceData{}, err
+	}
+
+	return invoiceData{
+		Message:    flash.Get(w, r),
+		Resource:   invoice,
+		Customers:  customers,
+		Items:      items,
+		Units:      units,
+		Accounts:   accounts,
+		Currencies: currencies,
+	}, nil
+}
+
+func (ui UI) makeInvoiceFilter(ctx context.Context, values url.Values) (InvoiceFilter, error) {
+	filter := InvoiceFilter{}
+
+	if customerID := values.Get("customer_id"); customerID != "" {
+		customerID, err := strconv.ParseInt(customerID, 10, 64)
+		if err != nil {
+			return InvoiceFilter{}, fmt.Errorf("%w: unable to convert customer id to integer", xerrors.ErrBadRequest)
+		}
+
+		filter.CustomerID = sql.NullInt64{Valid: true, Int64: customerID}
+	}
+
+	if status := values.Get("status"); status != "" {
+		filter.Status = sql.NullString{Valid: true, String: status}
+	}
+
+	return filter, nil
+}
+
+func (ui UI) finalizeInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	invoice, err := ui.service.finalizeInvoice(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to finalize invoice", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The invoice has been finalized and posted."})
+	http.Redirect(w, r, invoice.Redirect(), http.StatusFound)
+}
+
+func (ui UI) invoicePrintView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := ui.makeInvoicePrintData(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to make data", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	if err := ui.templates["invoice-print"].ExecuteTemplate(w, "print", data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) invoicePDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := ui.makeInvoicePrintData(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to make data", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	invoice := data.Invoice
+	title := "Invoice " + invoice.Reference()
+	if !invoice.Finalized() {
+		title += " (draft)"
+	}
+
+	report := pdf.NewReport(ui.letterhead, title)
+	report.Fields([][2]string{
+		{"Customer", data.Customer.Name},
+		{"", data.Address.Street},
+		{"", data.Address.ZIP + " " + data.Address.City},
+		{"", data.Address.Country},
+	})
+	report.Fields([][2]string{
+		{"Invoice number", invoice.Reference()},
+		{"Date", invoice.Date},
+		{"Due date", invoice.DueDate},
+	})
+
+	var rows [][]string
+	for _, line := range invoice.Lines {
+		rows = append(rows, []string{line.Description, strconv.FormatFloat(line.Quantity, 'f', -1, 64) + " " + data.UnitCodes[line.UnitID], strconv.FormatInt(line.NetPrice, 10),
+			strconv.FormatInt(line.NetAmount, 10), strconv.FormatInt(line.GrossAmount, 10)})
+	}
+
+	report.Table([]pdf.Column{
+		{Title: "Description", Width: 5},
+		{Title: "Quantity", Width: 1.5, Align: pdf.Right},
+		{Title: "Net price", Width: 1.5, Align: pdf.Right},
+		{Title: "Net amount", Width: 1.5, Align: pdf.Right},
+		{Title: "Gross amount", Width: 1.5, Align: pdf.Right},
+	}, rows)
+
+	iso := " " + data.Currency.ISO
+	report.Totals([][2]string{
+		{"Net total", strconv.FormatInt(invoice.NetTotal(), 10) + iso},
+		{"Tax", strconv.FormatInt(invoice.TaxTotal(), 10) + iso},
+		{"Gross total", strconv.FormatInt(invoice.GrossTotal(), 10) + iso},
+	})
+
+	xui.ServePDF(w, "invoice-"+invoice.GetID()+".pdf", report)
+}
+
+func (ui UI) makeInvoicePrintData(ctx context.Context, id int64) (invoicePrintData, error) {
+	invoice, err := ui.service.invoice(ctx, id)
+	if err != nil {
+		return invoicePrintData{}, err
+	}
+
+	customer, err := ui.service.logistics.Customer(ctx, invoice.CustomerID)
+	if err != nil {
+		return invoicePrintData{}, err
+	}
+
+	address, err := ui.service.logistics.Address(ctx, customer.AddressID)
+	if err != nil {
+		return invoicePrintData{}, err
+	}
+
+	currencies, err := ui.service.accounting.Currencies(ctx)
+	if err != nil {
+		return invoicePrintData{}, err
+	}
+
+	units, err := ui.service.logistics.Units(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return invoicePrintData{}, err
+	}
+
+	data := invoicePrintData{Invoice: invoice, Customer: customer, Address: address, UnitCodes: make(map[int64]string, len(units))}
+	for _, unit := range units {
+		data.UnitCodes[unit.ID] = unit.Code
+	}
+	for _, currency := range currencies {
+		if currency.ID == invoice.CurrencyID {
+			data.Currency = currency
+		}
+	}
+
+	return data, nil
+}
+
+func parseInvoiceForm(values url.Values) (InvoiceParams, error) {
+	header := InvoiceHeaderParams{
+		Date:    values.Get("date"),
+		DueDate: values.Get("due_date"),
+	}
+
+	ids := []struct {
+		key    string
+		target *int64
+	}{
+		{"customer_id", &header.CustomerID},
+		{"currency_id", &header.CurrencyID},
+		{"receivable_account_id", &header.ReceivableAccountID},
+		{"revenue_account_id", &header.RevenueAccountID},
+		{"tax_account_id", &header.TaxAccountID},
+	}
+	for _, id := range ids {
+		var err error
+		*id.target, err = strconv.ParseInt(values.Get(id.key), 10, 64)
+		if err != nil {
+			return InvoiceParams{}, fmt.Errorf("unable to parse invoice %v to integer: %w", id.key, err)
+		}
+	}
+
+	itemIDs := values["lines[].item_id"]
+	if len(values["lines[].description"]) != len(itemIDs) || len(values["lines[].quantity"]) != len(itemIDs) || len(values["lines[].unit_id"]) != len(itemIDs) ||
+		len(values["lines[].net_price"]) != len(itemIDs) || len(values["lines[].gross_price"]) != len(itemIDs) {
+		return InvoiceParams{}, errors.New("incomplete invoice lines")
+	}
+
+	var lines []InvoiceLineParams
+	for i := 0; i < len(itemIDs); i++ {
+		itemID, err := strconv.ParseInt(itemIDs[i], 10, 64)
+		if err != nil {
+			return InvoiceParams{}, fmt.Errorf("unable to parse line item_id to integer: %w", err)
+		}
+		quantity, err := strconv.ParseFloat(values["lines[].quantity"][i], 64)
+		if err != nil {
+			return InvoiceParams{}, fmt.Errorf("unable to parse line quantity to number: %w", err)
+		}
+
+		unitID, err := strconv.ParseInt(values["lines[].unit_id"][i], 10, 64)
+		if err != nil {
+			return InvoiceParams{}, fmt.Errorf("unable to parse line unit_id to integer: %w", err)
+		}
+
+		line := InvoiceLineParams{ItemID: itemID, Description: values["lines[].description"][i], Quantity: quantity, UnitID: unitID}
+
+		if netPrice := values["lines[].net_price"][i]; netPrice != "" {
+			n, err := strconv.ParseInt(netPrice, 10, 64)
+			if err != nil {
+				return InvoiceParams{}, fmt.Errorf("unable to parse line net_price to integer: %w", err)
+			}
+			line.NetPrice = sql.NullInt64{Valid: true, Int64: n}
+		}
+
+		if grossPrice := values["lines[].gross_price"][i]; grossPrice != "" {
+			n, err := strconv.ParseInt(grossPrice, 10, 64)
+			if err != nil {
+				return InvoiceParams{}, fmt.Errorf("unable to parse line gross_price to integer: %w", err)
+			}
+			line.GrossPrice = sql.NullInt64{Valid: true, Int64: n}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return InvoiceParams{InvoiceHeaderParams: header, Lines: lines}, nil
+}
@@ -2,10 +2,17 @@ package logistics
 
 import (
 	"context"
+	"database/sql"
 	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/tombuente/apex/internal/database"
+	"github.com/tombuente/apex/internal/xerrors"
 )
 
 //go:embed schema.sql
@@ -15,23 +22,133 @@ var Schema string
 var Fixture string
 
 type Database struct {
-	db *pgx.Conn
+	db database.Querier
 }
 
-func MakeDatabase(db *pgx.Conn) Database {
+func MakeDatabase(db database.Querier) Database {
 	return Database{
 		db: db,
 	}
 }
 
-func (db Database) itemCategories(ctx context.Context) ([]ItemCategory, error) {
+// categoryTreeQuery selects all categories together with their path and depth in the category tree.
+const categoryTreeQuery = `
+WITH RECURSIVE tree AS (
+	SELECT item_categories.*, name::TEXT AS path, 0 AS depth
+	FROM logistics.item_categories
+	WHERE parent_id IS NULL
+	UNION ALL
+	SELECT item_categories.*, tree.path || ' / ' || item_categories.name, tree.depth + 1
+	FROM logistics.item_categories
+	JOIN tree ON tree.id = item_categories.parent_id
+)
+`
+
+func (db Database) itemCategory(ctx context.Context, id int64) (ItemCategory, error) {
+	const query = categoryTreeQuery + `
+SELECT *
+FROM tree
+WHERE id = $1
+`
+
+	return database.One[ItemCategory](ctx, db.db, query, id)
+}
+
+// itemCategories returns categories in tree order, every category directly follows its parent.
+func (db Database) itemCategories(ctx context.Context, filter ItemCategoryFilter) ([]ItemCategory, error) {
+	const query = categoryTreeQuery + `
+SELECT *
+FROM tree
+WHERE (parent_id = $1 OR $1 IS NULL)
+ORDER BY path ASC
+`
+
+	return database.Many[ItemCategory](ctx, db.db, query, filter.parentID)
+}
+
+// categoryAncestors returns a category followed by its parent, the parent of its parent and so on.
+func (db Database) categoryAncestors(ctx context.Context, id int64) ([]ItemCategory, error) {
 	const query = `
+WITH RECURSIVE ancestors AS (
+	SELECT item_categories.*, 0 AS depth
+	FROM logistics.item_categories
+	WHERE id = $1
+	UNION ALL
+	SELECT item_categories.*, ancestors.depth + 1
+	FROM logistics.item_categories
+	JOIN ancestors ON ancestors.parent_id = item_categories.id
+)
 SELECT *
-FROM logistics.item_categories
-ORDER BY id ASC
+FROM ancestors
+ORDER BY depth ASC
+`
+
+	return database.Many[ItemCategory](ctx, db.db, query, id)
+}
+
+func (db Database) createItemCategory(ctx context.Context, params ItemCategoryParams) (ItemCategory, error) {
+	const query = `
+INSERT INTO logistics.item_categories (name, parent_id, default_base_unit_id, default_tracking)
+VALUES ($1, $2, $3, $4)
+RETURNING *
+`
+
+	return database.One[ItemCategory](ctx, db.db, query, params.Name, nullID(params.ParentID), nullID(params.DefaultBaseUnitID), params.DefaultTracking)
+}
+
+// updateItemCategory updates a category. Categories are locked while the parent is changed, so two concurrent moves can
+// not create a cycle that each of them alone would not.
+func (db Database) updateItemCategory(ctx context.Context, id int64, params ItemCategoryParams) (ItemCategory, error) {
+	const lockQuery = `
+LOCK TABLE logistics.item_categories IN SHARE ROW EXCLUSIVE MODE
+`
+
+	const cycleQuery = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id
+	FROM logistics.item_categories
+	WHERE id = $1
+	UNION
+	SELECT item_categories.id, item_categories.parent_id
+	FROM logistics.item_categories
+	JOIN ancestors ON ancestors.parent_id = item_categories.id
+)
+SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2) AS cyclic
+`
+
+	const query = `
+UPDATE logistics.item_categories
+SET
+	name                 = $2,
+	parent_id            = $3,
+	default_base_unit_id = $4,
+	default_tracking     = $5
+WHERE id = $1
+RETURNING *
 `
 
-	return database.Many[ItemCategory](ctx, db.db, query)
+	var category ItemCategory
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		if params.ParentID != 0 {
+			cycle, err := database.One[cycleCheck](ctx, tx, cycleQuery, params.ParentID, id)
+			if err != nil {
+				return err
+			}
+			if cycle.Cyclic {
+				return fmt.Errorf("%w: a category can not be moved below itself or one of its subcategories", xerrors.ErrBadRequest)
+			}
+		}
+
+		var err error
+		category, err = database.One[ItemCategory](ctx, tx, query, id, params.Name, nullID(params.ParentID), nullID(params.DefaultBaseUnitID), params.DefaultTracking)
+		return err
+	})
+
+	return category, err
 }
 
 func (db Database) item(ctx context.Context, id int64) (Item, error) {
@@ -44,143 +161,3068 @@ WHERE id = $1
 	return database.One[Item](ctx, db.db, query, id)
 }
 
+// items returns the items matching the filter, filtering by a category includes the items of all of its subcategories.
 func (db Database) items(ctx context.Context, filter ItemFilter) ([]Item, error) {
 	const query = `
+WITH RECURSIVE subcategories AS (
+	SELECT id
+	FROM logistics.item_categories
+	WHERE id = $3
+	UNION ALL
+	SELECT item_categories.id
+	FROM logistics.item_categories
+	JOIN subcategories ON subcategories.id = item_categories.parent_id
+)
 SELECT *
 FROM logistics.items
 WHERE
 	(name        LIKE $1 OR $1 IS NULL) AND
 	(sku         LIKE $2 OR $2 IS NULL) AND
-	(category_id = $3 OR $3 IS NULL) AND
+	(category_id IN (SELECT id FROM subcategories) OR $3 IS NULL) AND
 	(gross_price = $4 OR $4 IS NULL) AND
-	(net_price   = $5 OR $5 IS NULL)
+	(net_price   = $5 OR $5 IS NULL) AND
+	(tracking    = $6 OR $6 IS NULL) AND
+	(parent_id   = $7 OR $7 IS NULL) AND
+	(LPAD(gtin, 14, '0') = LPAD($8, 14, '0') OR $8 IS NULL)
 ORDER BY id ASC
 `
 
-	return database.Many[Item](ctx, db.db, query, filter.name, filter.sku, filter.categoryID, filter.grossPrice, filter.netPrice)
+	return database.Many[Item](ctx, db.db, query, filter.name, filter.sku, filter.categoryID, filter.grossPrice, filter.netPrice,
+		filter.tracking, filter.parentID, filter.gtin)
 }
 
 func (db Database) createItem(ctx context.Context, params ItemParams) (Item, error) {
+	var item Item
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		item, err = insertItem(ctx, tx, params, sql.NullInt64{})
+		return err
+	})
+
+	return item, err
+}
+
+// createVariants inserts the variants of an item in one transaction.
+func (db Database) createVariants(ctx context.Context, parentID int64, variants []ItemParams) ([]Item, error) {
+	var items []Item
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		for _, params := range variants {
+			item, err := insertItem(ctx, tx, params, nullID(parentID))
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+
+	return items, err
+}
+
+func insertItem(ctx context.Context, q database.Querier, params ItemParams, parentID sql.NullInt64) (Item, error) {
 	const query = `
-INSERT INTO logistics.items (name, sku, category_id, gross_price, net_price)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO logistics.items (name, sku, category_id, gross_price, net_price, base_unit_id, tracking, parent_id, tax_category_id,
+	currency, price_entry, gtin)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *
 `
 
-	return database.One[Item](ctx, db.db, query, params.Name, params.SKU, params.CategoryID, params.GrossPrice, params.NetPrice)
+	item, err := database.One[Item](ctx, q, query, params.Name, params.SKU, params.CategoryID, params.GrossPrice, params.NetPrice,
+		params.BaseUnitID, params.Tracking, parentID, params.TaxCategoryID, params.Currency, params.PriceEntry, params.GTIN)
+	if err != nil {
+		return Item{}, err
+	}
+
+	return item, setAttributeValues(ctx, q, item.ID, params.Attributes)
 }
 
 func (db Database) updateItem(ctx context.Context, id int64, params ItemParams) (Item, error) {
 	const query = `
 UPDATE logistics.items
 SET
-	name        = $2,
-	sku         = $3,
-	category_id = $4,
-	gross_price = $5,
-	net_price   = $6
+	name            = $2,
+	sku             = $3,
+	category_id     = $4,
+	gross_price     = $5,
+	net_price       = $6,
+	base_unit_id    = $7,
+	tracking        = $8,
+	tax_category_id = $9,
+	currency        = $10,
+	price_entry     = $11,
+	gtin            = $12
 WHERE id = $1
 RETURNING *
 `
 
-	return database.One[Item](ctx, db.db, query, id, params.Name, params.SKU, params.CategoryID, params.GrossPrice, params.NetPrice)
+	var item Item
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		item, err = database.One[Item](ctx, tx, query, id, params.Name, params.SKU, params.CategoryID, params.GrossPrice, params.NetPrice,
+			params.BaseUnitID, params.Tracking, params.TaxCategoryID, params.Currency, params.PriceEntry, params.GTIN)
+		if err != nil {
+			return err
+		}
+
+		return setAttributeValues(ctx, tx, id, params.Attributes)
+	})
+
+	return item, err
 }
 
-func (db Database) address(ctx context.Context, id int64) (Address, error) {
+// setAttributeValues replaces the attribute values of an item.
+func setAttributeValues(ctx context.Context, q database.Querier, itemID int64, values map[int64]string) error {
+	const deleteQuery = `
+DELETE FROM logistics.item_attribute_values
+WHERE item_id = $1
+`
+	const query = `
+INSERT INTO logistics.item_attribute_values (item_id, attribute_id, value)
+VALUES ($1, $2, $3)
+`
+
+	if _, err := q.Exec(ctx, deleteQuery, itemID); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	for attributeID, value := range values {
+		if _, err := q.Exec(ctx, query, itemID, attributeID, value); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+	}
+
+	return nil
+}
+
+func (db Database) itemAttributeValues(ctx context.Context, itemID int64) ([]ItemAttributeValue, error) {
 	const query = `
 SELECT *
-FROM logistics.addresses
+FROM logistics.item_attribute_values
+WHERE item_id = $1
+ORDER BY attribute_id ASC
+`
+
+	return database.Many[ItemAttributeValue](ctx, db.db, query, itemID)
+}
+
+func (db Database) itemAttribute(ctx context.Context, id int64) (ItemAttribute, error) {
+	const query = `
+SELECT *
+FROM logistics.item_attributes
 WHERE id = $1
 `
 
-	return database.One[Address](ctx, db.db, query, id)
+	return database.One[ItemAttribute](ctx, db.db, query, id)
 }
 
-func (db Database) addresses(ctx context.Context, filter AddressFilter) ([]Address, error) {
+// itemAttributes returns the attributes that apply to the items of a category, the attributes of the category and of
+// its ancestors, or all attributes if categoryID is not valid.
+func (db Database) itemAttributes(ctx context.Context, categoryID sql.NullInt64) ([]ItemAttribute, error) {
 	const query = `
+WITH RECURSIVE ancestors AS (
+	SELECT id, parent_id
+	FROM logistics.item_categories
+	WHERE id = $1
+	UNION ALL
+	SELECT item_categories.id, item_categories.parent_id
+	FROM logistics.item_categories
+	JOIN ancestors ON ancestors.parent_id = item_categories.id
+)
 SELECT *
-FROM logistics.addresses
-WHERE
-	(zip       LIKE $1 OR $1 IS NULL) AND
-	(city      LIKE $2 OR $2 IS NULL) AND
-	(street    LIKE $3 OR $3 IS NULL) AND
-	(country   LIKE $4 OR $4 IS NULL) AND
-	(latitude  =    $5 OR $5 IS NULL) AND
-	(longitude =    $6 OR $6 IS NULL)
+FROM logistics.item_attributes
+WHERE (category_id IN (SELECT id FROM ancestors) OR $1 IS NULL)
 ORDER BY id ASC
 `
 
-	return database.Many[Address](ctx, db.db, query, filter.zip, filter.city, filter.street, filter.country, filter.latitude, filter.longitude)
+	return database.Many[ItemAttribute](ctx, db.db, query, categoryID)
 }
 
-func (db Database) createAddress(ctx context.Context, params AddressParams) (Address, error) {
+func (db Database) createItemAttribute(ctx context.Context, categoryID int64, params ItemAttributeParams) (ItemAttribute, error) {
 	const query = `
-INSERT INTO logistics.addresses (zip, city, street, country, longitude, latitude)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO logistics.item_attributes (category_id, name, type, options, required)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *
 `
 
-	return database.One[Address](ctx, db.db, query, params.ZIP, params.City, params.Street, params.Country, params.Latitude, params.Longitude)
+	options := params.optionList()
+	if options == nil {
+		options = []string{}
+	}
+
+	return database.One[ItemAttribute](ctx, db.db, query, categoryID, params.Name, params.Type, options, params.Required)
 }
 
-func (db Database) updateAddress(ctx context.Context, id int64, params AddressParams) (Address, error) {
+// deleteItemAttribute deletes an attribute of a category together with its values.
+func (db Database) deleteItemAttribute(ctx context.Context, categoryID int64, id int64) error {
 	const query = `
-UPDATE logistics.addresses
+DELETE FROM logistics.item_attributes
+WHERE category_id = $1 AND id = $2
+`
+
+	if _, err := db.db.Exec(ctx, query, categoryID, id); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (db Database) taxCategory(ctx context.Context, id int64) (TaxCategory, error) {
+	const query = `
+SELECT *
+FROM logistics.tax_categories
+WHERE id = $1
+`
+
+	return database.One[TaxCategory](ctx, db.db, query, id)
+}
+
+func (db Database) taxCategories(ctx context.Context, filter TaxCategoryFilter) ([]TaxCategory, error) {
+	const query = `
+SELECT *
+FROM logistics.tax_categories
+ORDER BY name ASC
+`
+
+	return database.Many[TaxCategory](ctx, db.db, query)
+}
+
+func (db Database) createTaxCategory(ctx context.Context, params TaxCategoryParams) (TaxCategory, error) {
+	const query = `
+INSERT INTO logistics.tax_categories (name, rate)
+VALUES ($1, $2)
+RETURNING *
+`
+
+	return database.One[TaxCategory](ctx, db.db, query, params.Name, params.Rate)
+}
+
+// updateTaxCategory updates a tax category and recalculates the derived prices of its items in the same transaction.
+func (db Database) updateTaxCategory(ctx context.Context, id int64, params TaxCategoryParams) (TaxCategory, error) {
+	const query = `
+UPDATE logistics.tax_categories
 SET
-	zip       = $2,
-	city      = $3,
-	street    = $4,
-	country   = $5,
-	latitude  = $6,
-	longitude = $7
+	name = $2,
+	rate = $3
 WHERE id = $1
 RETURNING *
 `
-	return database.One[Address](ctx, db.db, query, id, params.ZIP, params.City, params.Street, params.Country, params.Latitude, params.Longitude)
+
+	var category TaxCategory
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		if category, err = database.One[TaxCategory](ctx, tx, query, id, params.Name, params.Rate); err != nil {
+			return err
+		}
+
+		return recalculateItemPrices(ctx, tx, nullID(id), sql.NullString{})
+	})
+
+	return category, err
 }
 
-func (db Database) plant(ctx context.Context, id int64) (Plant, error) {
+// roundingIncrement returns the rounding increment of a currency, one minor unit if it has no rounding.
+func (db Database) roundingIncrement(ctx context.Context, currency string) (int64, error) {
+	const query = `
+SELECT COALESCE((SELECT increment FROM logistics.currency_roundings WHERE currency = $1), 1) AS increment
+`
+
+	rounding, err := database.One[CurrencyRounding](ctx, db.db, query, currency)
+	return rounding.Increment, err
+}
+
+func (db Database) currencyRoundings(ctx context.Context, _ CurrencyRoundingFilter) ([]CurrencyRounding, error) {
 	const query = `
 SELECT *
-FROM logistics.plants
+FROM logistics.currency_roundings
+ORDER BY currency ASC
+`
+
+	return database.Many[CurrencyRounding](ctx, db.db, query)
+}
+
+// setCurrencyRounding creates or updates the rounding of a currency and recalculates the derived prices of all items in
+// the currency.
+func (db Database) setCurrencyRounding(ctx context.Context, params CurrencyRoundingParams) (CurrencyRounding, error) {
+	const query = `
+INSERT INTO logistics.currency_roundings (currency, increment)
+VALUES ($1, $2)
+ON CONFLICT (currency) DO UPDATE SET increment = EXCLUDED.increment
+RETURNING *
+`
+
+	var rounding CurrencyRounding
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		if rounding, err = database.One[CurrencyRounding](ctx, tx, query, params.Currency, params.Increment); err != nil {
+			return err
+		}
+
+		return recalculateItemPrices(ctx, tx, sql.NullInt64{}, sql.NullString{Valid: true, String: params.Currency})
+	})
+
+	return rounding, err
+}
+
+// deleteCurrencyRounding deletes the rounding of a currency and recalculates the derived prices of all items in the
+// currency.
+func (db Database) deleteCurrencyRounding(ctx context.Context, currency string) error {
+	const query = `
+DELETE FROM logistics.currency_roundings
+WHERE currency = $1
+`
+
+	return database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, currency); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		return recalculateItemPrices(ctx, tx, sql.NullInt64{}, sql.NullString{Valid: true, String: currency})
+	})
+}
+
+// recalculateItemPrices derives the prices of the items of a tax category or in a currency again, see itemPrices.
+func recalculateItemPrices(ctx context.Context, q database.Querier, taxCategoryID sql.NullInt64, currency sql.NullString) error {
+	const query = `
+SELECT
+	items.id,
+	items.price_entry,
+	items.net_price,
+	items.gross_price,
+	tax_categories.rate,
+	COALESCE(roundings.increment, 1) AS increment
+FROM logistics.items
+JOIN logistics.tax_categories ON tax_categories.id = items.tax_category_id
+LEFT JOIN logistics.currency_roundings roundings ON roundings.currency = items.currency
+WHERE
+	(items.tax_category_id = $1 OR $1 IS NULL) AND
+	(items.currency        = $2 OR $2 IS NULL)
+ORDER BY items.id ASC
+FOR UPDATE OF items
+`
+	const updateQuery = `
+UPDATE logistics.items
+SET
+	net_price   = $2,
+	gross_price = $3
 WHERE id = $1
 `
 
-	return database.One[Plant](ctx, db.db, query, id)
+	items, err := database.Many[itemPriceBasis](ctx, q, query, taxCategoryID, currency)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		net, gross := itemPrices(item)
+		if net == item.NetPrice && gross == item.GrossPrice {
+			continue
+		}
+
+		if _, err := q.Exec(ctx, updateQuery, item.ID, net, gross); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+	}
+
+	return nil
 }
 
-func (db Database) plants(ctx context.Context, filter PlantFilter) ([]Plant, error) {
+func (db Database) unit(ctx context.Context, id int64) (Unit, error) {
 	const query = `
 SELECT *
-FROM logistics.plants
-WHERE
-	(name       LIKE $1 OR $1 IS NULL) AND
-	(address_id =    $2 OR $2 IS NULL)
-ORDER BY id ASC
+FROM logistics.units
+WHERE id = $1
 `
 
-	return database.Many[Plant](ctx, db.db, query, filter.name, filter.addressID)
+	return database.One[Unit](ctx, db.db, query, id)
 }
 
-func (db Database) createPlant(ctx context.Context, params PlantParams) (Plant, error) {
+func (db Database) units(ctx context.Context, filter UnitFilter) ([]Unit, error) {
 	const query = `
-INSERT INTO logistics.plants (name, address_id)
-VALUES ($1, $2)
+SELECT *
+FROM logistics.units
+ORDER BY code ASC
+`
+
+	return database.Many[Unit](ctx, db.db, query)
+}
+
+func (db Database) createUnit(ctx context.Context, params UnitParams) (Unit, error) {
+	const query = `
+INSERT INTO logistics.units (code, name, decimals)
+VALUES ($1, $2, $3)
 RETURNING *
 `
 
-	return database.One[Plant](ctx, db.db, query, params.Name, params.AddressID)
+	return database.One[Unit](ctx, db.db, query, params.Code, params.Name, params.Decimals)
 }
 
-func (db Database) updatePlant(ctx context.Context, id int64, params PlantParams) (Plant, error) {
+func (db Database) updateUnit(ctx context.Context, id int64, params UnitParams) (Unit, error) {
 	const query = `
-UPDATE logistics.plants
+UPDATE logistics.units
 SET
-	name       = $2,
-	address_id = $3
+	code     = $2,
+	name     = $3,
+	decimals = $4
 WHERE id = $1
 RETURNING *
 `
 
-	return database.One[Plant](ctx, db.db, query, id, params.Name, params.AddressID)
+	return database.One[Unit](ctx, db.db, query, id, params.Code, params.Name, params.Decimals)
+}
+
+func (db Database) itemUnit(ctx context.Context, itemID int64, unitID int64) (ItemUnit, error) {
+	const query = `
+SELECT item_units.*, units.code, units.name, units.decimals
+FROM logistics.item_units
+JOIN logistics.units ON units.id = item_units.unit_id
+WHERE item_units.item_id = $1 AND item_units.unit_id = $2
+`
+
+	return database.One[ItemUnit](ctx, db.db, query, itemID, unitID)
+}
+
+func (db Database) itemUnits(ctx context.Context, itemID int64) ([]ItemUnit, error) {
+	const query = `
+SELECT item_units.*, units.code, units.name, units.decimals
+FROM logistics.item_units
+JOIN logistics.units ON units.id = item_units.unit_id
+WHERE item_units.item_id = $1
+ORDER BY units.code ASC
+`
+
+	return database.Many[ItemUnit](ctx, db.db, query, itemID)
+}
+
+// setItemUnit creates the alternative unit of an item or updates its factor if it already exists.
+func (db Database) setItemUnit(ctx context.Context, itemID int64, params ItemUnitParams) (ItemUnit, error) {
+	const query = `
+INSERT INTO logistics.item_units (item_id, unit_id, factor)
+VALUES ($1, $2, $3)
+ON CONFLICT (item_id, unit_id) DO UPDATE SET factor = EXCLUDED.factor
+RETURNING *
+`
+
+	return database.One[ItemUnit](ctx, db.db, query, itemID, params.UnitID, params.Factor)
+}
+
+func (db Database) deleteItemUnit(ctx context.Context, itemID int64, unitID int64) error {
+	const query = `
+DELETE FROM logistics.item_units
+WHERE item_id = $1 AND unit_id = $2
+`
+
+	if _, err := db.db.Exec(ctx, query, itemID, unitID); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// bomLines returns the bill of materials of an item, or of all items if itemID is not valid.
+func (db Database) bomLines(ctx context.Context, itemID sql.NullInt64) ([]BOMLine, error) {
+	const query = `
+SELECT lines.*, items.name AS component_name, items.sku AS component_sku, units.code AS unit_code
+FROM logistics.bom_lines lines
+JOIN logistics.items items ON items.id = lines.component_id
+JOIN logistics.units units ON units.id = items.base_unit_id
+WHERE lines.item_id = $1 OR $1 IS NULL
+ORDER BY lines.item_id ASC, items.name ASC
+`
+
+	return database.Many[BOMLine](ctx, db.db, query, itemID)
+}
+
+// setBOMLine adds the component to the bill of materials of an item or updates its quantity if it already is a
+// component. It returns ErrBadRequest if the item already is a component of the component, at any level. The table is
+// locked against concurrent changes, which could create a cycle together.
+func (db Database) setBOMLine(ctx context.Context, itemID int64, componentID int64, quantity float64) error {
+	const lockQuery = `
+LOCK TABLE logistics.bom_lines IN SHARE ROW EXCLUSIVE MODE
+`
+	const cycleQuery = `
+WITH RECURSIVE components AS (
+	SELECT component_id
+	FROM logistics.bom_lines
+	WHERE item_id = $1
+	UNION
+	SELECT lines.component_id
+	FROM logistics.bom_lines lines
+	JOIN components ON components.component_id = lines.item_id
+)
+SELECT EXISTS (SELECT 1 FROM components WHERE component_id = $2) AS cyclic
+`
+	const query = `
+INSERT INTO logistics.bom_lines (item_id, component_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (item_id, component_id) DO UPDATE SET quantity = EXCLUDED.quantity
+`
+
+	return database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		cycle, err := database.One[cycleCheck](ctx, tx, cycleQuery, componentID, itemID)
+		if err != nil {
+			return err
+		}
+		if cycle.Cyclic {
+			return fmt.Errorf("%w: the item is a component of the selected component, the bill of materials would be cyclic", xerrors.ErrBadRequest)
+		}
+
+		if _, err := tx.Exec(ctx, query, itemID, componentID, quantity); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		return nil
+	})
+}
+
+// cycleCheck is used to scan the result of the cycle checks of bills of materials and categories.
+type cycleCheck struct {
+	Cyclic bool `db:"cyclic"`
+}
+
+func (db Database) deleteBOMLine(ctx context.Context, itemID int64, componentID int64) error {
+	const query = `
+DELETE FROM logistics.bom_lines
+WHERE item_id = $1 AND component_id = $2
+`
+
+	if _, err := db.db.Exec(ctx, query, itemID, componentID); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return nil
+}
+
+func (db Database) address(ctx context.Context, id int64) (Address, error) {
+	const query = `
+SELECT *
+FROM logistics.addresses
+WHERE id = $1
+`
+
+	return database.One[Address](ctx, db.db, query, id)
+}
+
+// addresses returns the addresses matching filter. Boxes with a minimum longitude greater than their maximum longitude
+// cross the antimeridian.
+func (db Database) addresses(ctx context.Context, filter AddressFilter) ([]Address, error) {
+	const query = `
+SELECT *
+FROM logistics.addresses
+WHERE
+	(zip       ILIKE $1 OR $1 IS NULL) AND
+	(city      ILIKE $2 OR $2 IS NULL) AND
+	(street    ILIKE $3 OR $3 IS NULL) AND
+	(country   ILIKE $4 OR $4 IS NULL) AND
+	(latitude  >=    $5 OR $5 IS NULL) AND
+	(latitude  <=    $6 OR $6 IS NULL) AND
+	(
+		(longitude >= $7 OR $7 IS NULL) AND (longitude <= $8 OR $8 IS NULL) OR
+		($7 > $8 AND (longitude >= $7 OR longitude <= $8))
+	) AND
+	(COALESCE($5, $6, $7, $8) IS NULL OR latitude <> 0 OR longitude <> 0)
+ORDER BY id ASC
+`
+
+	return database.Many[Address](ctx, db.db, query, filter.zip, filter.city, filter.street, filter.country,
+		filter.box.minLatitude, filter.box.maxLatitude, filter.box.minLongitude, filter.box.maxLongitude)
+}
+
+func (db Database) country(ctx context.Context, code string) (Country, error) {
+	const query = `
+SELECT *
+FROM logistics.countries
+WHERE code = $1
+`
+
+	return database.One[Country](ctx, db.db, query, code)
+}
+
+// countryByName returns the country with the code or name, names are compared case-insensitively.
+func (db Database) countryByName(ctx context.Context, name string) (Country, error) {
+	const query = `
+SELECT *
+FROM logistics.countries
+WHERE code = upper($1) OR lower(name) = lower($1)
+ORDER BY code = upper($1) DESC
+LIMIT 1
+`
+
+	return database.One[Country](ctx, db.db, query, name)
+}
+
+func (db Database) countries(ctx context.Context, _ CountryFilter) ([]Country, error) {
+	const query = `
+SELECT *
+FROM logistics.countries
+ORDER BY name ASC
+`
+
+	return database.Many[Country](ctx, db.db, query)
+}
+
+// equalAddress returns the first address with the same country, postal code, city and street, city and street are
+// compared case-insensitively.
+func (db Database) equalAddress(ctx context.Context, params AddressParams) (Address, error) {
+	const query = `
+SELECT *
+FROM logistics.addresses
+WHERE country = $1 AND zip = $2 AND lower(city) = lower($3) AND lower(street) = lower($4)
+ORDER BY id ASC
+LIMIT 1
+`
+
+	return database.One[Address](ctx, db.db, query, params.Country, params.ZIP, params.City, params.Street)
+}
+
+func (db Database) createAddress(ctx context.Context, params AddressParams) (Address, error) {
+	const query = `
+INSERT INTO logistics.addresses (zip, city, street, country, latitude, longitude)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
+`
+
+	return database.One[Address](ctx, db.db, query, params.ZIP, params.City, params.Street, params.Country, params.Latitude, params.Longitude)
+}
+
+func (db Database) updateAddress(ctx context.Context, id int64, params AddressParams) (Address, error) {
+	const query = `
+UPDATE logistics.addresses
+SET
+	zip       = $2,
+	city      = $3,
+	street    = $4,
+	country   = $5,
+	latitude  = $6,
+	longitude = $7
+WHERE id = $1
+RETURNING *
+`
+	return database.One[Address](ctx, db.db, query, id, params.ZIP, params.City, params.Street, params.Country, params.Latitude, params.Longitude)
+}
+
+// geocodePostalCode returns the center of the places of a postal code. If city matches places of the postal code, only
+// they are used.
+func (db Database) geocodePostalCode(ctx context.Context, postalCode GeocodeQuery) (Coordinates, error) {
+	const query = `
+WITH matches AS (
+	SELECT latitude, longitude, lower(place) = lower($3) AS city_match
+	FROM logistics.postal_codes
+	WHERE country = upper($1) AND zip = $2
+)
+SELECT AVG(latitude) AS latitude, AVG(longitude) AS longitude
+FROM matches
+WHERE city_match OR NOT EXISTS (SELECT 1 FROM matches WHERE city_match)
+HAVING COUNT(*) > 0
+`
+
+	return database.One[Coordinates](ctx, db.db, query, postalCode.Country, postalCode.ZIP, postalCode.City)
+}
+
+func (db Database) postalCodeCountries(ctx context.Context) ([]PostalCodeCountry, error) {
+	const query = `
+SELECT country, COUNT(*) AS places
+FROM logistics.postal_codes
+GROUP BY country
+ORDER BY country ASC
+`
+
+	return database.Many[PostalCodeCountry](ctx, db.db, query)
+}
+
+// importPostalCodes replaces the postal codes of every country contained in codes.
+func (db Database) importPostalCodes(ctx context.Context, codes []PostalCode) error {
+	countries := make([]string, 0)
+	rows := make([][]any, 0, len(codes))
+	for _, code := range codes {
+		if !slices.Contains(countries, code.Country) {
+			countries = append(countries, code.Country)
+		}
+		rows = append(rows, []any{code.Country, code.ZIP, code.Place, code.Latitude, code.Longitude})
+	}
+
+	return database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM logistics.postal_codes WHERE country = ANY($1)`, countries); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		columns := []string{"country", "zip", "place", "latitude", "longitude"}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"logistics", "postal_codes"}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		return nil
+	})
+}
+
+// plantLocations returns the plants whose address has coordinates.
+func (db Database) plantLocations(ctx context.Context) ([]PlantLocation, error) {
+	const query = `
+SELECT plants.*, addresses.street, addresses.zip, addresses.city, addresses.latitude, addresses.longitude
+FROM logistics.plants
+JOIN logistics.addresses ON addresses.id = plants.address_id
+WHERE addresses.latitude <> 0 OR addresses.longitude <> 0
+ORDER BY plants.id ASC
+`
+
+	return database.Many[PlantLocation](ctx, db.db, query)
+}
+
+// plantLocationsInBox returns the plants whose address lies in box, it uses the coordinate index of the addresses.
+func (db Database) plantLocationsInBox(ctx context.Context, box BoundingBox) ([]PlantLocation, error) {
+	const query = `
+SELECT plants.*, addresses.street, addresses.zip, addresses.city, addresses.latitude, addresses.longitude
+FROM logistics.plants
+JOIN logistics.addresses ON addresses.id = plants.address_id
+WHERE
+	(addresses.latitude <> 0 OR addresses.longitude <> 0) AND
+	addresses.latitude BETWEEN $1 AND $2 AND
+	(addresses.longitude BETWEEN $3 AND $4 OR ($3 > $4 AND (addresses.longitude >= $3 OR addresses.longitude <= $4)))
+ORDER BY plants.id ASC
+`
+
+	return database.Many[PlantLocation](ctx, db.db, query, box.MinLatitude, box.MaxLatitude, box.MinLongitude, box.MaxLongitude)
+}
+
+func (db Database) plant(ctx context.Context, id int64) (Plant, error) {
+	const query = `
+SELECT *
+FROM logistics.plants
+WHERE id = $1
+`
+
+	return database.One[Plant](ctx, db.db, query, id)
+}
+
+func (db Database) plants(ctx context.Context, filter PlantFilter) ([]Plant, error) {
+	const query = `
+SELECT plants.*
+FROM logistics.plants
+JOIN logistics.addresses ON addresses.id = plants.address_id
+WHERE
+	(plants.name          ILIKE $1 OR $1 IS NULL) AND
+	(plants.address_id    =     $2 OR $2 IS NULL) AND
+	(addresses.street ILIKE $3 OR addresses.zip ILIKE $3 OR addresses.city ILIKE $3 OR $3 IS NULL) AND
+	(addresses.latitude   >=    $4 OR $4 IS NULL) AND
+	(addresses.latitude   <=    $5 OR $5 IS NULL) AND
+	(
+		(addresses.longitude >= $6 OR $6 IS NULL) AND (addresses.longitude <= $7 OR $7 IS NULL) OR
+		($6 > $7 AND (addresses.longitude >= $6 OR addresses.longitude <= $7))
+	) AND
+	(COALESCE($4, $5, $6, $7) IS NULL OR addresses.latitude <> 0 OR addresses.longitude <> 0)
+ORDER BY plants.id ASC
+`
+
+	return database.Many[Plant](ctx, db.db, query, filter.name, filter.addressID, filter.address,
+		filter.box.minLatitude, filter.box.maxLatitude, filter.box.minLongitude, filter.box.maxLongitude)
+}
+
+func (db Database) createPlant(ctx context.Context, params PlantParams) (Plant, error) {
+	const query = `
+INSERT INTO logistics.plants (name, address_id, allow_negative_stock)
+VALUES ($1, $2, $3)
+RETURNING *
+`
+
+	return database.One[Plant](ctx, db.db, query, params.Name, params.AddressID, params.AllowNegativeStock)
+}
+
+func (db Database) updatePlant(ctx context.Context, id int64, params PlantParams) (Plant, error) {
+	const query = `
+UPDATE logistics.plants
+SET
+	name                 = $2,
+	address_id           = $3,
+	allow_negative_stock = $4
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Plant](ctx, db.db, query, id, params.Name, params.AddressID, params.AllowNegativeStock)
+}
+
+func (db Database) customer(ctx context.Context, id int64) (Customer, error) {
+	const query = `
+SELECT *
+FROM logistics.customers
+WHERE id = $1
+`
+
+	return database.One[Customer](ctx, db.db, query, id)
+}
+
+func (db Database) customers(ctx context.Context, filter CustomerFilter) ([]Customer, error) {
+	const query = `
+SELECT *
+FROM logistics.customers
+WHERE
+	(name       LIKE $1 OR $1 IS NULL) AND
+	(address_id =    $2 OR $2 IS NULL) AND
+	(group_id   =    $3 OR $3 IS NULL)
+ORDER BY id ASC
+`
+
+	return database.Many[Customer](ctx, db.db, query, filter.name, filter.addressID, filter.groupID)
+}
+
+func (db Database) createCustomer(ctx context.Context, params CustomerParams) (Customer, error) {
+	const query = `
+INSERT INTO logistics.customers (name, address_id, group_id)
+VALUES ($1, $2, $3)
+RETURNING *
+`
+
+	return database.One[Customer](ctx, db.db, query, params.Name, params.AddressID, nullID(params.GroupID))
+}
+
+func (db Database) updateCustomer(ctx context.Context, id int64, params CustomerParams) (Customer, error) {
+	const query = `
+UPDATE logistics.customers
+SET
+	name       = $2,
+	address_id = $3,
+	group_id   = $4
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Customer](ctx, db.db, query, id, params.Name, params.AddressID, nullID(params.GroupID))
+}
+
+func (db Database) customerGroup(ctx context.Context, id int64) (CustomerGroup, error) {
+	const query = `
+SELECT *
+FROM logistics.customer_groups
+WHERE id = $1
+`
+
+	return database.One[CustomerGroup](ctx, db.db, query, id)
+}
+
+func (db Database) customerGroups(ctx context.Context, filter CustomerGroupFilter) ([]CustomerGroup, error) {
+	const query = `
+SELECT *
+FROM logistics.customer_groups
+ORDER BY name ASC
+`
+
+	return database.Many[CustomerGroup](ctx, db.db, query)
+}
+
+func (db Database) createCustomerGroup(ctx context.Context, params CustomerGroupParams) (CustomerGroup, error) {
+	const query = `
+INSERT INTO logistics.customer_groups (name)
+VALUES ($1)
+RETURNING *
+`
+
+	return database.One[CustomerGroup](ctx, db.db, query, params.Name)
+}
+
+func (db Database) updateCustomerGroup(ctx context.Context, id int64, params CustomerGroupParams) (CustomerGroup, error) {
+	const query = `
+UPDATE logistics.customer_groups
+SET name = $2
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[CustomerGroup](ctx, db.db, query, id, params.Name)
+}
+
+func (db Database) priceList(ctx context.Context, id int64) (PriceList, error) {
+	const query = `
+SELECT *
+FROM logistics.price_lists
+WHERE id = $1
+`
+
+	return database.One[PriceList](ctx, db.db, query, id)
+}
+
+func (db Database) priceLists(ctx context.Context, filter PriceListFilter) ([]PriceList, error) {
+	const query = `
+SELECT *
+FROM logistics.price_lists
+WHERE
+	(customer_group_id = $1 OR $1 IS NULL) AND
+	(customer_id       = $2 OR $2 IS NULL)
+ORDER BY name ASC
+`
+
+	return database.Many[PriceList](ctx, db.db, query, filter.customerGroupID, filter.customerID)
+}
+
+func (db Database) createPriceList(ctx context.Context, params PriceListParams) (PriceList, error) {
+	const query = `
+INSERT INTO logistics.price_lists (name, currency, valid_from, valid_to, customer_group_id, customer_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
+`
+
+	return database.One[PriceList](ctx, db.db, query, params.Name, params.Currency, params.ValidFrom, params.ValidTo,
+		nullID(params.CustomerGroupID), nullID(params.CustomerID))
+}
+
+func (db Database) updatePriceList(ctx context.Context, id int64, params PriceListParams) (PriceList, error) {
+	const query = `
+UPDATE logistics.price_lists
+SET
+	name              = $2,
+	currency          = $3,
+	valid_from        = $4,
+	valid_to          = $5,
+	customer_group_id = $6,
+	customer_id       = $7
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[PriceList](ctx, db.db, query, id, params.Name, params.Currency, params.ValidFrom, params.ValidTo,
+		nullID(params.CustomerGroupID), nullID(params.CustomerID))
+}
+
+// prices returns the prices of a list ordered by item and quantity scale.
+func (db Database) prices(ctx context.Context, priceListID int64) ([]Price, error) {
+	const query = `
+SELECT prices.*, items.name AS item_name, items.sku AS item_sku
+FROM logistics.prices
+JOIN logistics.items ON items.id = prices.item_id
+WHERE prices.price_list_id = $1
+ORDER BY items.name ASC, prices.min_quantity ASC
+`
+
+	return database.Many[Price](ctx, db.db, query, priceListID)
+}
+
+// setPrice creates the scale price of an item in a list or updates the price if the scale already exists.
+func (db Database) setPrice(ctx context.Context, priceListID int64, params PriceParams) (Price, error) {
+	const query = `
+INSERT INTO logistics.prices (price_list_id, item_id, min_quantity, price)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (price_list_id, item_id, min_quantity) DO UPDATE SET price = EXCLUDED.price
+RETURNING *
+`
+
+	return database.One[Price](ctx, db.db, query, priceListID, params.ItemID, params.MinQuantity, params.Price)
+}
+
+func (db Database) deletePrice(ctx context.Context, priceListID int64, id int64) error {
+	const query = `
+DELETE FROM logistics.prices
+WHERE price_list_id = $1 AND id = $2
+`
+
+	if _, err := db.db.Exec(ctx, query, priceListID, id); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// priceCandidates returns the scale prices of an item that apply to the quantity, date and currency in the general
+// lists, the lists of the customer group and the lists of the customer.
+func (db Database) priceCandidates(ctx context.Context, query PriceQuery, customerGroupID sql.NullInt64) ([]priceCandidate, error) {
+	const sqlQuery = `
+SELECT
+	lists.id AS price_list_id,
+	lists.currency,
+	lists.valid_from,
+	lists.customer_group_id,
+	lists.customer_id,
+	prices.min_quantity,
+	prices.price
+FROM logistics.prices
+JOIN logistics.price_lists lists ON lists.id = prices.price_list_id
+WHERE
+	prices.item_id = $1 AND
+	prices.min_quantity <= $2 AND
+	(lists.valid_from = '' OR lists.valid_from <= $3) AND
+	(lists.valid_to   = '' OR lists.valid_to   >= $3) AND
+	(
+		(lists.customer_id IS NULL AND lists.customer_group_id IS NULL) OR
+		lists.customer_id = $4 OR
+		lists.customer_group_id = $5
+	) AND
+	lists.currency = $6
+`
+
+	return database.Many[priceCandidate](ctx, db.db, sqlQuery, query.ItemID, query.Quantity, query.Date, nullID(query.CustomerID), customerGroupID,
+		query.Currency)
+}
+
+func (db Database) goodsMovement(ctx context.Context, id int64) (GoodsMovement, error) {
+	const query = `
+SELECT
+	movements.*,
+	ARRAY(
+		SELECT serial_numbers.number
+		FROM logistics.goods_movement_serial_numbers serials
+		JOIN logistics.serial_numbers serial_numbers ON serial_numbers.id = serials.serial_number_id
+		WHERE serials.goods_movement_id = movements.id
+		ORDER BY serial_numbers.number ASC
+	) AS serial_numbers
+FROM logistics.goods_movements movements
+WHERE movements.id = $1
+`
+
+	return database.One[GoodsMovement](ctx, db.db, query, id)
+}
+
+func (db Database) goodsMovements(ctx context.Context, filter GoodsMovementFilter) ([]GoodsMovement, error) {
+	const query = `
+SELECT *
+FROM logistics.goods_movements
+WHERE
+	(type    = $1 OR $1 IS NULL) AND
+	(item_id = $2 OR $2 IS NULL) AND
+	(from_plant_id = $3 OR to_plant_id = $3 OR $3 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[GoodsMovement](ctx, db.db, query, filter.movementType, filter.itemID, filter.plantID)
+}
+
+// createGoodsMovement records a goods movement. Movements taking stock out of a plant lock the plant and movements
+// putting stock into a bin lock the bin until the transaction ends, so concurrent movements can not both pass the checks
+// and together drive the stock negative or overfill the bin. Movements of items in a plant with an open inventory count
+// are rejected.
+func (db Database) createGoodsMovement(ctx context.Context, params GoodsMovementParams) (GoodsMovement, error) {
+	var movement GoodsMovement
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		movement, err = insertGoodsMovement(ctx, tx, params)
+		return err
+	})
+
+	return movement, err
+}
+
+// insertGoodsMovement checks and inserts a goods movement, it has to run inside of a transaction, see createGoodsMovement.
+func insertGoodsMovement(ctx context.Context, q database.Querier, params GoodsMovementParams) (GoodsMovement, error) {
+	const itemQuery = `
+SELECT *
+FROM logistics.items
+WHERE id = $1
+`
+	const query = `
+INSERT INTO logistics.goods_movements (type, date, item_id, from_plant_id, to_plant_id, from_bin_id, to_bin_id, purchase_order_line_id,
+	delivery_line_id, batch_id, quantity, reference, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *
+`
+	const serialQuery = `
+INSERT INTO logistics.goods_movement_serial_numbers (goods_movement_id, serial_number_id)
+VALUES ($1, $2)
+`
+
+	item, err := database.One[Item](ctx, q, itemQuery, params.ItemID)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+
+	batchID, err := movementBatch(ctx, q, item, params)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+
+	serialNumberIDs, err := movementSerialNumbers(ctx, q, item, params)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+
+	if params.FromPlantID != 0 {
+		if err := checkStock(ctx, q, params.ItemID, params.FromPlantID, params.FromBinID, batchID, params.Quantity); err != nil {
+			return GoodsMovement{}, err
+		}
+	}
+
+	if params.ToBinID != 0 {
+		if err := checkBinCapacity(ctx, q, params.ToBinID, item, params.Quantity); err != nil {
+			return GoodsMovement{}, err
+		}
+	}
+
+	movement, err := database.One[GoodsMovement](ctx, q, query, params.Type, params.Date, params.ItemID, nullID(params.FromPlantID),
+		nullID(params.ToPlantID), nullID(params.FromBinID), nullID(params.ToBinID), nullID(params.PurchaseOrderLineID),
+		nullID(params.DeliveryLineID), nullID(batchID), params.Quantity, params.Reference, params.Note)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+
+	// Checked after the insert, see createInventoryCount.
+	if err := checkInventoryCount(ctx, q, item, params.FromPlantID, params.ToPlantID); err != nil {
+		return GoodsMovement{}, err
+	}
+
+	for _, serialNumberID := range serialNumberIDs {
+		if _, err := q.Exec(ctx, serialQuery, movement.ID, serialNumberID); err != nil {
+			return GoodsMovement{}, xerrors.Join(xerrors.ErrInternal, err)
+		}
+	}
+
+	return movement, nil
+}
+
+// movementBatch returns the ID of the batch a movement of a batch managed item moves, or zero for other items. Batches
+// are created by movements into a plant, movements out of a plant need an existing batch.
+func movementBatch(ctx context.Context, q database.Querier, item Item, params GoodsMovementParams) (int64, error) {
+	const batchQuery = `
+SELECT *
+FROM logistics.batches
+WHERE id = $1
+`
+	const numberQuery = `
+SELECT *
+FROM logistics.batches
+WHERE item_id = $1 AND number = $2
+`
+	const createQuery = `
+INSERT INTO logistics.batches (item_id, number, manufacture_date, expiry_date)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (item_id, number) DO UPDATE SET number = EXCLUDED.number
+RETURNING *
+`
+
+	if item.Tracking != TrackingBatch {
+		if params.BatchID != 0 || params.BatchNumber != "" {
+			return 0, fmt.Errorf("%w: item %v is not batch managed", xerrors.ErrBadRequest, item.Name)
+		}
+		return 0, nil
+	}
+
+	if params.BatchID != 0 {
+		batch, err := database.One[Batch](ctx, q, batchQuery, params.BatchID)
+		if err != nil {
+			return 0, err
+		}
+		if batch.ItemID != item.ID {
+			return 0, fmt.Errorf("%w: batch %v is not a batch of item %v", xerrors.ErrBadRequest, batch.Number, item.Name)
+		}
+		return batch.ID, nil
+	}
+
+	if params.BatchNumber == "" {
+		return 0, fmt.Errorf("%w: item %v is batch managed, a batch number is required", xerrors.ErrBadRequest, item.Name)
+	}
+
+	if params.FromPlantID == 0 {
+		batch, err := database.One[Batch](ctx, q, createQuery, item.ID, params.BatchNumber, params.ManufactureDate, params.ExpiryDate)
+		return batch.ID, err
+	}
+
+	batch, err := database.One[Batch](ctx, q, numberQuery, item.ID, params.BatchNumber)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return 0, fmt.Errorf("%w: item %v has no batch %v", xerrors.ErrBadRequest, item.Name, params.BatchNumber)
+	}
+
+	return batch.ID, err
+}
+
+// movementSerialNumbers returns the IDs of the serial numbers a movement of a serialized item moves. The serial numbers
+// are locked, movements into a plant create unknown serial numbers and reject serial numbers that are already in stock,
+// movements out of a plant reject serial numbers that are not in stock in the source plant and bin.
+func movementSerialNumbers(ctx context.Context, q database.Querier, item Item, params GoodsMovementParams) ([]int64, error) {
+	const lockQuery = `
+SELECT *
+FROM logistics.serial_numbers
+WHERE item_id = $1 AND number = $2
+FOR UPDATE
+`
+	const createQuery = `
+INSERT INTO logistics.serial_numbers (item_id, number)
+VALUES ($1, $2)
+ON CONFLICT (item_id, number) DO UPDATE SET number = EXCLUDED.number
+RETURNING *
+`
+	const stockQuery = `
+SELECT *
+FROM logistics.serial_number_stock
+WHERE serial_number_id = $1
+`
+
+	numbers := params.serialNumbers()
+	if item.Tracking != TrackingSerial {
+		if len(numbers) > 0 {
+			return nil, fmt.Errorf("%w: item %v is not serialized", xerrors.ErrBadRequest, item.Name)
+		}
+		return nil, nil
+	}
+
+	if float64(len(numbers)) != params.Quantity {
+		return nil, fmt.Errorf("%w: item %v is serialized, %v serial numbers are required", xerrors.ErrBadRequest, item.Name, params.Quantity)
+	}
+
+	ids := make([]int64, 0, len(numbers))
+	for _, number := range numbers {
+		var serialNumber SerialNumber
+		var err error
+		if params.FromPlantID == 0 {
+			serialNumber, err = database.One[SerialNumber](ctx, q, createQuery, item.ID, number)
+		} else {
+			serialNumber, err = database.One[SerialNumber](ctx, q, lockQuery, item.ID, number)
+			if errors.Is(err, xerrors.ErrNotFound) {
+				return nil, fmt.Errorf("%w: item %v has no serial number %v", xerrors.ErrBadRequest, item.Name, number)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		location, err := database.One[serialNumberLocation](ctx, q, stockQuery, serialNumber.ID)
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return nil, err
+		}
+		inStock := err == nil
+
+		if params.FromPlantID == 0 && inStock {
+			return nil, fmt.Errorf("%w: serial number %v is already in stock", xerrors.ErrBadRequest, number)
+		}
+		if params.FromPlantID != 0 && (!inStock || location.PlantID != params.FromPlantID || location.BinID.Int64 != params.FromBinID) {
+			return nil, fmt.Errorf("%w: serial number %v is not in stock where it is taken from", xerrors.ErrBadRequest, number)
+		}
+
+		ids = append(ids, serialNumber.ID)
+	}
+
+	return ids, nil
+}
+
+// serialNumberLocation is used to scan rows of the serial number stock.
+type serialNumberLocation struct {
+	SerialNumberID int64         `db:"serial_number_id"`
+	PlantID        int64         `db:"plant_id"`
+	BinID          sql.NullInt64 `db:"bin_id"`
+}
+
+// checkStock locks the plant and returns ErrBadRequest if taking quantity out of the bin, or out of the stock outside
+// of bins if binID is zero, would make its stock of the item negative, unless the plant allows negative stock. A
+// batchID that is not zero restricts the check to the batch.
+func checkStock(ctx context.Context, q database.Querier, itemID int64, plantID int64, binID int64, batchID int64, quantity float64) error {
+	const plantQuery = `
+SELECT *
+FROM logistics.plants
+WHERE id = $1
+FOR UPDATE
+`
+	const stockQuery = `
+SELECT COALESCE(SUM(quantity), 0) AS quantity
+FROM logistics.stock
+WHERE item_id = $1 AND plant_id = $2 AND bin_id IS NOT DISTINCT FROM $3 AND (batch_id = $4 OR $4 IS NULL)
+`
+
+	plant, err := database.One[Plant](ctx, q, plantQuery, plantID)
+	if err != nil {
+		return err
+	}
+	if plant.AllowNegativeStock {
+		return nil
+	}
+
+	stock, err := database.One[stockQuantity](ctx, q, stockQuery, itemID, plantID, nullID(binID), nullID(batchID))
+	if err != nil {
+		return err
+	}
+
+	if stock.Quantity < quantity {
+		if batchID != 0 {
+			return fmt.Errorf("%w: insufficient stock of the batch, %v available", xerrors.ErrBadRequest, stock.Quantity)
+		}
+		if binID != 0 {
+			return fmt.Errorf("%w: insufficient stock in bin, %v available", xerrors.ErrBadRequest, stock.Quantity)
+		}
+		return fmt.Errorf("%w: insufficient stock outside of bins in plant %v, %v available", xerrors.ErrBadRequest, plant.Name,
+			stock.Quantity)
+	}
+
+	return nil
+}
+
+// checkBinCapacity locks the bin and returns ErrBadRequest if it is blocked or if adding quantity base units of the item
+// would exceed its capacity. Quantities are compared in the capacity unit of the bin, items that can not be converted
+// to it can not be stored in a limited bin.
+func checkBinCapacity(ctx context.Context, q database.Querier, binID int64, item Item, quantity float64) error {
+	const binQuery = `
+SELECT bins.*, COALESCE(units.code, '') AS capacity_unit_code
+FROM logistics.bins
+LEFT JOIN logistics.units ON units.id = bins.capacity_unit_id
+WHERE bins.id = $1
+FOR UPDATE OF bins
+`
+	const factorQuery = `
+SELECT CASE WHEN items.base_unit_id = $2 THEN 1 ELSE item_units.factor END AS factor
+FROM logistics.items
+LEFT JOIN logistics.item_units ON item_units.item_id = items.id AND item_units.unit_id = $2
+WHERE items.id = $1
+`
+
+	bin, err := database.One[Bin](ctx, q, binQuery, binID)
+	if err != nil {
+		return err
+	}
+
+	if bin.Blocked {
+		return fmt.Errorf("%w: bin %v is blocked", xerrors.ErrBadRequest, bin.Name)
+	}
+	if bin.Capacity == 0 {
+		return nil
+	}
+
+	conversion, err := database.One[unitFactor](ctx, q, factorQuery, item.ID, bin.CapacityUnitID)
+	if err != nil {
+		return err
+	}
+	if !conversion.Factor.Valid {
+		return fmt.Errorf("%w: %v can not be converted to %v, the capacity unit of bin %v", xerrors.ErrBadRequest, item.Name,
+			bin.CapacityUnitCode, bin.Name)
+	}
+
+	load, err := binLoad(ctx, q, binID, bin.CapacityUnitID.Int64)
+	if err != nil {
+		return err
+	}
+	if len(load.Unconvertible) > 0 {
+		return fmt.Errorf("%w: bin %v holds items that can not be converted to %v: %v", xerrors.ErrBadRequest, bin.Name,
+			bin.CapacityUnitCode, strings.Join(load.Unconvertible, ", "))
+	}
+
+	if load.Quantity+quantity/conversion.Factor.Float64 > bin.Capacity {
+		return fmt.Errorf("%w: bin %v can only take %v %v more", xerrors.ErrBadRequest, bin.Name, bin.Capacity-load.Quantity,
+			bin.CapacityUnitCode)
+	}
+
+	return nil
+}
+
+func (db Database) binLoad(ctx context.Context, binID int64, unitID int64) (BinLoad, error) {
+	return binLoad(ctx, db.db, binID, unitID)
+}
+
+// binLoad converts the stock of a bin to the unit. Stock of items with another base unit is converted with their
+// alternative unit, one alternative unit equals factor base units.
+func binLoad(ctx context.Context, q database.Querier, binID int64, unitID int64) (BinLoad, error) {
+	const query = `
+SELECT
+	COALESCE(SUM(stock.quantity / COALESCE(item_units.factor, 1))
+		FILTER (WHERE items.base_unit_id = $2 OR item_units.factor IS NOT NULL), 0) AS quantity,
+	COALESCE(ARRAY_AGG(DISTINCT items.name)
+		FILTER (WHERE items.base_unit_id <> $2 AND item_units.factor IS NULL AND stock.quantity <> 0), '{}') AS unconvertible
+FROM logistics.stock
+JOIN logistics.items ON items.id = stock.item_id
+LEFT JOIN logistics.item_units ON item_units.item_id = stock.item_id AND item_units.unit_id = $2
+WHERE stock.bin_id = $1
+`
+
+	return database.One[BinLoad](ctx, q, query, binID, unitID)
+}
+
+// checkInventoryCount returns ErrBadRequest if the item is counted by an open inventory count in one of the plants.
+func checkInventoryCount(ctx context.Context, q database.Querier, item Item, fromPlantID int64, toPlantID int64) error {
+	const query = `
+SELECT counts.*
+FROM logistics.inventory_counts counts
+WHERE
+	counts.status = 'open' AND
+	counts.plant_id IN ($2, $3) AND
+	EXISTS (
+		SELECT 1
+		FROM logistics.inventory_count_lines lines
+		WHERE lines.inventory_count_id = counts.id AND lines.item_id = $1
+	)
+LIMIT 1
+`
+
+	count, err := database.One[InventoryCountHeader](ctx, q, query, item.ID, nullID(fromPlantID), nullID(toPlantID))
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: item %v is blocked by the open inventory count %v", xerrors.ErrBadRequest, item.Name, count.Reference())
+}
+
+// stockQuantity is used to scan aggregated stock quantities.
+type stockQuantity struct {
+	Quantity float64 `db:"quantity"`
+}
+
+// unitFactor is used to scan the factor of a unit conversion, it is null if there is no conversion.
+type unitFactor struct {
+	Factor sql.NullFloat64 `db:"factor"`
+}
+
+func (db Database) stock(ctx context.Context, filter StockFilter) ([]Stock, error) {
+	const query = `
+SELECT
+	stock.item_id,
+	items.name AS item_name,
+	items.sku  AS item_sku,
+	stock.plant_id,
+	plants.name AS plant_name,
+	stock.bin_id,
+	bins.name AS bin_name,
+	storage_locations.name AS storage_location_name,
+	stock.batch_id,
+	batches.number AS batch_number,
+	batches.expiry_date,
+	stock.quantity,
+	units.code AS unit_code
+FROM logistics.stock
+JOIN logistics.items                  ON items.id             = stock.item_id
+JOIN logistics.units                  ON units.id             = items.base_unit_id
+JOIN logistics.plants                 ON plants.id            = stock.plant_id
+LEFT JOIN logistics.bins              ON bins.id              = stock.bin_id
+LEFT JOIN logistics.storage_locations ON storage_locations.id = bins.storage_location_id
+LEFT JOIN logistics.batches           ON batches.id           = stock.batch_id
+WHERE
+	stock.quantity <> 0 AND
+	(stock.item_id  = $1 OR $1 IS NULL) AND
+	(stock.plant_id = $2 OR $2 IS NULL) AND
+	(stock.bin_id   = $3 OR $3 IS NULL)
+ORDER BY items.name ASC, plants.name ASC, storage_locations.name ASC NULLS FIRST, bins.name ASC NULLS FIRST,
+	NULLIF(batches.expiry_date, '') ASC NULLS LAST, batches.number ASC
+`
+
+	return database.Many[Stock](ctx, db.db, query, filter.itemID, filter.plantID, filter.binID)
+}
+
+func (db Database) storageLocation(ctx context.Context, id int64) (StorageLocation, error) {
+	const query = `
+SELECT *
+FROM logistics.storage_locations
+WHERE id = $1
+`
+
+	return database.One[StorageLocation](ctx, db.db, query, id)
+}
+
+func (db Database) storageLocations(ctx context.Context, filter StorageLocationFilter) ([]StorageLocation, error) {
+	const query = `
+SELECT *
+FROM logistics.storage_locations
+WHERE (plant_id = $1 OR $1 IS NULL)
+ORDER BY name ASC
+`
+
+	return database.Many[StorageLocation](ctx, db.db, query, filter.plantID)
+}
+
+func (db Database) createStorageLocation(ctx context.Context, params StorageLocationParams) (StorageLocation, error) {
+	const query = `
+INSERT INTO logistics.storage_locations (plant_id, name)
+VALUES ($1, $2)
+RETURNING *
+`
+
+	return database.One[StorageLocation](ctx, db.db, query, params.PlantID, params.Name)
+}
+
+func (db Database) updateStorageLocation(ctx context.Context, id int64, params StorageLocationParams) (StorageLocation, error) {
+	const query = `
+UPDATE logistics.storage_locations
+SET name = $2
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[StorageLocation](ctx, db.db, query, id, params.Name)
+}
+
+func (db Database) bin(ctx context.Context, id int64) (Bin, error) {
+	const query = `
+SELECT bins.*, storage_locations.plant_id, storage_locations.name AS storage_location_name,
+	COALESCE(units.code, '') AS capacity_unit_code
+FROM logistics.bins
+JOIN logistics.storage_locations ON storage_locations.id = bins.storage_location_id
+LEFT JOIN logistics.units ON units.id = bins.capacity_unit_id
+WHERE bins.id = $1
+`
+
+	return database.One[Bin](ctx, db.db, query, id)
+}
+
+func (db Database) bins(ctx context.Context, filter BinFilter) ([]Bin, error) {
+	const query = `
+SELECT bins.*, storage_locations.plant_id, storage_locations.name AS storage_location_name,
+	COALESCE(units.code, '') AS capacity_unit_code
+FROM logistics.bins
+JOIN logistics.storage_locations ON storage_locations.id = bins.storage_location_id
+LEFT JOIN logistics.units ON units.id = bins.capacity_unit_id
+WHERE
+	(storage_locations.plant_id = $1 OR $1 IS NULL) AND
+	(bins.storage_location_id   = $2 OR $2 IS NULL)
+ORDER BY storage_locations.plant_id ASC, storage_locations.name ASC, bins.name ASC
+`
+
+	return database.Many[Bin](ctx, db.db, query, filter.plantID, filter.storageLocationID)
+}
+
+func (db Database) createBin(ctx context.Context, params BinParams) (Bin, error) {
+	const query = `
+INSERT INTO logistics.bins (storage_location_id, name, capacity, capacity_unit_id, blocked)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
+`
+
+	return database.One[Bin](ctx, db.db, query, params.StorageLocationID, params.Name, params.Capacity, nullID(params.CapacityUnitID),
+		params.Blocked)
+}
+
+func (db Database) updateBin(ctx context.Context, id int64, params BinParams) (Bin, error) {
+	const query = `
+UPDATE logistics.bins
+SET
+	name             = $2,
+	capacity         = $3,
+	capacity_unit_id = $4,
+	blocked          = $5
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Bin](ctx, db.db, query, id, params.Name, params.Capacity, nullID(params.CapacityUnitID), params.Blocked)
+}
+
+// nullID maps the zero ID used by forms for "none" to NULL.
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Valid: id != 0, Int64: id}
+}
+
+func (db Database) supplier(ctx context.Context, id int64) (Supplier, error) {
+	const query = `
+SELECT *
+FROM logistics.suppliers
+WHERE id = $1
+`
+
+	return database.One[Supplier](ctx, db.db, query, id)
+}
+
+func (db Database) suppliers(ctx context.Context, filter SupplierFilter) ([]Supplier, error) {
+	const query = `
+SELECT *
+FROM logistics.suppliers
+WHERE (name LIKE $1 OR $1 IS NULL)
+ORDER BY id ASC
+`
+
+	return database.Many[Supplier](ctx, db.db, query, filter.name)
+}
+
+func (db Database) createSupplier(ctx context.Context, params SupplierParams) (Supplier, error) {
+	const query = `
+INSERT INTO logistics.suppliers (name, address_id)
+VALUES ($1, $2)
+RETURNING *
+`
+
+	return database.One[Supplier](ctx, db.db, query, params.Name, params.AddressID)
+}
+
+func (db Database) updateSupplier(ctx context.Context, id int64, params SupplierParams) (Supplier, error) {
+	const query = `
+UPDATE logistics.suppliers
+SET
+	name       = $2,
+	address_id = $3
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Supplier](ctx, db.db, query, id, params.Name, params.AddressID)
+}
+
+func (db Database) purchaseOrder(ctx context.Context, id int64) (PurchaseOrder, error) {
+	return queryPurchaseOrder(ctx, db.db, id, false)
+}
+
+// queryPurchaseOrder queries a purchase order together with the received quantities of its lines. If lock is set, the
+// order is locked until the end of the transaction q belongs to.
+func queryPurchaseOrder(ctx context.Context, q database.Querier, id int64, lock bool) (PurchaseOrder, error) {
+	headerQuery := `
+SELECT *
+FROM logistics.purchase_orders
+WHERE id = $1
+`
+	if lock {
+		headerQuery += "FOR UPDATE\n"
+	}
+
+	header, err := database.One[PurchaseOrderHeader](ctx, q, headerQuery, id)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	const linesQuery = `
+SELECT
+	lines.*,
+	COALESCE((
+		SELECT SUM(movements.quantity)
+		FROM logistics.goods_movements movements
+		WHERE movements.purchase_order_line_id = lines.id
+	), 0) AS received_quantity
+FROM logistics.purchase_order_lines lines
+WHERE lines.purchase_order_id = $1
+ORDER BY lines.id ASC
+`
+
+	lines, err := database.Many[PurchaseOrderLine](ctx, q, linesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return PurchaseOrder{}, err
+	}
+
+	return PurchaseOrder{PurchaseOrderHeader: header, Lines: lines}, nil
+}
+
+func (db Database) purchaseOrders(ctx context.Context, filter PurchaseOrderFilter) ([]PurchaseOrderHeader, error) {
+	const query = `
+SELECT *
+FROM logistics.purchase_orders
+WHERE
+	(supplier_id = $1 OR $1 IS NULL) AND
+	(status      = $2 OR $2 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[PurchaseOrderHeader](ctx, db.db, query, filter.supplierID, filter.status)
+}
+
+func (db Database) createPurchaseOrder(ctx context.Context, header PurchaseOrderHeaderParams, lines []orderLineValues) (PurchaseOrder, error) {
+	var order PurchaseOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		order, err = insertPurchaseOrder(ctx, tx, header, lines)
+		return err
+	})
+
+	return order, err
+}
+
+// insertPurchaseOrder inserts a purchase order with its lines, it has to run inside of a transaction.
+func insertPurchaseOrder(ctx context.Context, q database.Querier, header PurchaseOrderHeaderParams, lines []orderLineValues) (PurchaseOrder, error) {
+	const query = `
+INSERT INTO logistics.purchase_orders (supplier_id, plant_id, date, delivery_date, currency)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
+`
+
+	var order PurchaseOrder
+	var err error
+	order.PurchaseOrderHeader, err = database.One[PurchaseOrderHeader](ctx, q, query, header.SupplierID, header.PlantID, header.Date, header.DeliveryDate,
+		header.Currency)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	order.Lines, err = insertPurchaseOrderLines(ctx, q, order.ID, lines)
+	return order, err
+}
+
+// updatePurchaseOrder replaces header and lines of a purchase order, as long as nothing has been received against it.
+func (db Database) updatePurchaseOrder(ctx context.Context, id int64, header PurchaseOrderHeaderParams, lines []orderLineValues) (PurchaseOrder, error) {
+	const query = `
+UPDATE logistics.purchase_orders
+SET
+	supplier_id   = $2,
+	plant_id      = $3,
+	date          = $4,
+	delivery_date = $5,
+	currency      = $6
+WHERE id = $1
+RETURNING *
+`
+	const deleteLinesQuery = `
+DELETE FROM logistics.purchase_order_lines
+WHERE purchase_order_id = $1
+`
+
+	var order PurchaseOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryPurchaseOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Editable() {
+			return fmt.Errorf("%w: purchase order can only be changed while it is open", xerrors.ErrBadRequest)
+		}
+
+		order.PurchaseOrderHeader, err = database.One[PurchaseOrderHeader](ctx, tx, query, id, header.SupplierID, header.PlantID, header.Date, header.DeliveryDate,
+			header.Currency)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteLinesQuery, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		order.Lines, err = insertPurchaseOrderLines(ctx, tx, id, lines)
+		return err
+	})
+
+	return order, err
+}
+
+func insertPurchaseOrderLines(ctx context.Context, q database.Querier, orderID int64, lines []orderLineValues) ([]PurchaseOrderLine, error) {
+	const query = `
+INSERT INTO logistics.purchase_order_lines (purchase_order_id, item_id, quantity, unit_id, base_quantity, price)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
+`
+
+	var orderLines []PurchaseOrderLine
+	for _, line := range lines {
+		orderLine, err := database.One[PurchaseOrderLine](ctx, q, query, orderID, line.ItemID, line.Quantity, line.UnitID, line.BaseQuantity, line.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		orderLines = append(orderLines, orderLine)
+	}
+
+	return orderLines, nil
+}
+
+func (db Database) cancelPurchaseOrder(ctx context.Context, id int64) (PurchaseOrderHeader, error) {
+	const query = `
+UPDATE logistics.purchase_orders
+SET status = 'cancelled'
+WHERE id = $1 AND status IN ('open', 'partially_received')
+RETURNING *
+`
+
+	order, err := database.One[PurchaseOrderHeader](ctx, db.db, query, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return PurchaseOrderHeader{}, fmt.Errorf("%w: only open purchase orders can be cancelled", xerrors.ErrBadRequest)
+	}
+
+	return order, err
+}
+
+// receivePurchaseOrder posts receipts against the lines of a purchase order and updates its status. The order is locked
+// while receiving, so concurrent receipts can not together exceed the open quantity of a line.
+func (db Database) receivePurchaseOrder(ctx context.Context, id int64, movements []GoodsMovementParams) (PurchaseOrder, error) {
+	const statusQuery = `
+UPDATE logistics.purchase_orders
+SET status = $2
+WHERE id = $1
+`
+
+	var order PurchaseOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryPurchaseOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Receivable() {
+			return fmt.Errorf("%w: goods can only be received against open purchase orders", xerrors.ErrBadRequest)
+		}
+
+		lines := make(map[int64]*PurchaseOrderLine, len(current.Lines))
+		for i := range current.Lines {
+			lines[current.Lines[i].ID] = &current.Lines[i]
+		}
+
+		for _, movement := range movements {
+			line, ok := lines[movement.PurchaseOrderLineID]
+			if !ok {
+				return fmt.Errorf("%w: line %v does not belong to the purchase order", xerrors.ErrBadRequest, movement.PurchaseOrderLineID)
+			}
+			// Compare in thousandths, quantities are stored with three decimals.
+			if math.Round(movement.Quantity*1000) > math.Round(line.OpenQuantity()*1000) {
+				return fmt.Errorf("%w: only %v of line %v are still open", xerrors.ErrBadRequest, line.OpenQuantity(), line.ID)
+			}
+
+			if _, err := insertGoodsMovement(ctx, tx, movement); err != nil {
+				return err
+			}
+			line.ReceivedQuantity += movement.Quantity
+		}
+
+		current.Status = PurchaseOrderStatusReceived
+		for _, line := range current.Lines {
+			if line.OpenQuantity() > 0 {
+				current.Status = PurchaseOrderStatusPartiallyReceived
+			}
+		}
+
+		if _, err := tx.Exec(ctx, statusQuery, id, current.Status); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		order = current
+		return nil
+	})
+
+	return order, err
+}
+
+func (db Database) salesOrder(ctx context.Context, id int64) (SalesOrder, error) {
+	return querySalesOrder(ctx, db.db, id, false)
+}
+
+// querySalesOrder queries a sales order together with the delivered and shipped quantities of its lines. If lock is
+// set, the order is locked until the end of the transaction q belongs to.
+func querySalesOrder(ctx context.Context, q database.Querier, id int64, lock bool) (SalesOrder, error) {
+	headerQuery := `
+SELECT *
+FROM logistics.sales_orders
+WHERE id = $1
+`
+	if lock {
+		headerQuery += "FOR UPDATE\n"
+	}
+
+	header, err := database.One[SalesOrderHeader](ctx, q, headerQuery, id)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+
+	const linesQuery = `
+SELECT
+	lines.*,
+	COALESCE((
+		SELECT SUM(delivery_lines.quantity)
+		FROM logistics.delivery_lines delivery_lines
+		JOIN logistics.deliveries deliveries ON deliveries.id = delivery_lines.delivery_id
+		WHERE delivery_lines.sales_order_line_id = lines.id AND deliveries.status <> 'cancelled'
+	), 0) AS delivered_quantity,
+	COALESCE((
+		SELECT SUM(delivery_lines.quantity)
+		FROM logistics.delivery_lines delivery_lines
+		JOIN logistics.deliveries deliveries ON deliveries.id = delivery_lines.delivery_id
+		WHERE delivery_lines.sales_order_line_id = lines.id AND deliveries.status = 'shipped'
+	), 0) AS shipped_quantity
+FROM logistics.sales_order_lines lines
+WHERE lines.sales_order_id = $1
+ORDER BY lines.id ASC
+`
+
+	lines, err := database.Many[SalesOrderLine](ctx, q, linesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return SalesOrder{}, err
+	}
+
+	return SalesOrder{SalesOrderHeader: header, Lines: lines}, nil
+}
+
+func (db Database) salesOrders(ctx context.Context, filter SalesOrderFilter) ([]SalesOrderHeader, error) {
+	const query = `
+SELECT *
+FROM logistics.sales_orders
+WHERE
+	(customer_id = $1 OR $1 IS NULL) AND
+	(status      = $2 OR $2 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[SalesOrderHeader](ctx, db.db, query, filter.customerID, filter.status)
+}
+
+func (db Database) createSalesOrder(ctx context.Context, header SalesOrderHeaderParams, lines []orderLineValues) (SalesOrder, error) {
+	const query = `
+INSERT INTO logistics.sales_orders (customer_id, plant_id, shipping_address_id, date, requested_delivery_date, currency)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
+`
+
+	var order SalesOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		var err error
+		order.SalesOrderHeader, err = database.One[SalesOrderHeader](ctx, tx, query, header.CustomerID, header.PlantID,
+			header.ShippingAddressID, header.Date, header.RequestedDeliveryDate, header.Currency)
+		if err != nil {
+			return err
+		}
+
+		order.Lines, err = insertSalesOrderLines(ctx, tx, order.ID, lines)
+		return err
+	})
+
+	return order, err
+}
+
+// updateSalesOrder replaces header and lines of a sales order, as long as no delivery has ever been created for it.
+func (db Database) updateSalesOrder(ctx context.Context, id int64, header SalesOrderHeaderParams, lines []orderLineValues) (SalesOrder, error) {
+	const query = `
+UPDATE logistics.sales_orders
+SET
+	customer_id             = $2,
+	plant_id                = $3,
+	shipping_address_id     = $4,
+	date                    = $5,
+	requested_delivery_date = $6,
+	currency                = $7
+WHERE id = $1
+RETURNING *
+`
+	const deliveriesQuery = `
+SELECT *
+FROM logistics.deliveries
+WHERE sales_order_id = $1
+`
+	const deleteLinesQuery = `
+DELETE FROM logistics.sales_order_lines
+WHERE sales_order_id = $1
+`
+
+	var order SalesOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := querySalesOrder(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Editable() {
+			return fmt.Errorf("%w: sales order can only be changed before it is delivered", xerrors.ErrBadRequest)
+		}
+
+		// Cancelled deliveries still reference the lines.
+		_, err = database.Many[DeliveryHeader](ctx, tx, deliveriesQuery, id)
+		if err == nil {
+			return fmt.Errorf("%w: sales order can not be changed after deliveries have been created for it", xerrors.ErrBadRequest)
+		}
+		if !errors.Is(err, xerrors.ErrNotFound) {
+			return err
+		}
+
+		order.SalesOrderHeader, err = database.One[SalesOrderHeader](ctx, tx, query, id, header.CustomerID, header.PlantID,
+			header.ShippingAddressID, header.Date, header.RequestedDeliveryDate, header.Currency)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, deleteLinesQuery, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		order.Lines, err = insertSalesOrderLines(ctx, tx, id, lines)
+		return err
+	})
+
+	return order, err
+}
+
+func insertSalesOrderLines(ctx context.Context, q database.Querier, orderID int64, lines []orderLineValues) ([]SalesOrderLine, error) {
+	const query = `
+INSERT INTO logistics.sales_order_lines (sales_order_id, item_id, quantity, unit_id, base_quantity, price)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *
+`
+
+	var orderLines []SalesOrderLine
+	for _, line := range lines {
+		orderLine, err := database.One[SalesOrderLine](ctx, q, query, orderID, line.ItemID, line.Quantity, line.UnitID, line.BaseQuantity, line.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		orderLines = append(orderLines, orderLine)
+	}
+
+	return orderLines, nil
+}
+
+func (db Database) cancelSalesOrder(ctx context.Context, id int64) (SalesOrderHeader, error) {
+	const query = `
+UPDATE logistics.sales_orders
+SET status = 'cancelled'
+WHERE id = $1 AND status = 'ordered'
+RETURNING *
+`
+
+	order, err := database.One[SalesOrderHeader](ctx, db.db, query, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return SalesOrderHeader{}, fmt.Errorf("%w: only sales orders without deliveries can be cancelled", xerrors.ErrBadRequest)
+	}
+
+	return order, err
+}
+
+// updateSalesOrderStatus locks the sales order and derives its status from its deliveries. It has to run inside of a
+// transaction.
+func updateSalesOrderStatus(ctx context.Context, q database.Querier, id int64) error {
+	const query = `
+UPDATE logistics.sales_orders
+SET status = $2
+WHERE id = $1
+RETURNING *
+`
+
+	order, err := querySalesOrder(ctx, q, id, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.One[SalesOrderHeader](ctx, q, query, id, order.deliveryStatus())
+	return err
+}
+
+func (db Database) delivery(ctx context.Context, id int64) (Delivery, error) {
+	return queryDelivery(ctx, db.db, id, false)
+}
+
+// queryDelivery queries a delivery with its lines. If lock is set, the delivery is locked until the end of the
+// transaction q belongs to.
+func queryDelivery(ctx context.Context, q database.Querier, id int64, lock bool) (Delivery, error) {
+	headerQuery := `
+SELECT deliveries.*, orders.plant_id
+FROM logistics.deliveries deliveries
+JOIN logistics.sales_orders orders ON orders.id = deliveries.sales_order_id
+WHERE deliveries.id = $1
+`
+	if lock {
+		headerQuery += "FOR UPDATE OF deliveries\n"
+	}
+
+	header, err := database.One[DeliveryHeader](ctx, q, headerQuery, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	const linesQuery = `
+SELECT
+	lines.*,
+	order_lines.item_id,
+	batches.number AS batch_number,
+	ARRAY(
+		SELECT serial_numbers.number
+		FROM logistics.delivery_line_serial_numbers serials
+		JOIN logistics.serial_numbers serial_numbers ON serial_numbers.id = serials.serial_number_id
+		WHERE serials.delivery_line_id = lines.id
+		ORDER BY serial_numbers.number ASC
+	) AS serial_numbers
+FROM logistics.delivery_lines lines
+JOIN logistics.sales_order_lines order_lines ON order_lines.id = lines.sales_order_line_id
+LEFT JOIN logistics.batches batches ON batches.id = lines.batch_id
+WHERE lines.delivery_id = $1
+ORDER BY lines.id ASC
+`
+
+	lines, err := database.Many[DeliveryLine](ctx, q, linesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return Delivery{}, err
+	}
+
+	return Delivery{DeliveryHeader: header, Lines: lines}, nil
+}
+
+func (db Database) deliveries(ctx context.Context, filter DeliveryFilter) ([]DeliveryHeader, error) {
+	const query = `
+SELECT deliveries.*, orders.plant_id
+FROM logistics.deliveries deliveries
+JOIN logistics.sales_orders orders ON orders.id = deliveries.sales_order_id
+WHERE
+	(deliveries.sales_order_id = $1 OR $1 IS NULL) AND
+	(orders.plant_id           = $2 OR $2 IS NULL) AND
+	(deliveries.status         = $3 OR $3 IS NULL)
+ORDER BY deliveries.id DESC
+`
+
+	return database.Many[DeliveryHeader](ctx, db.db, query, filter.salesOrderID, filter.plantID, filter.status)
+}
+
+// createDelivery creates a delivery for the lines of a sales order and updates the status of the order. The order is
+// locked while the delivery is created, so concurrent deliveries can not together exceed the open quantity of a line.
+func (db Database) createDelivery(ctx context.Context, orderID int64, date string, lines []deliveryLineValues) (Delivery, error) {
+	const query = `
+INSERT INTO logistics.deliveries (sales_order_id, date)
+VALUES ($1, $2)
+RETURNING *
+`
+	const lineQuery = `
+INSERT INTO logistics.delivery_lines (delivery_id, sales_order_line_id, quantity, bin_id, batch_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *
+`
+	const serialQuery = `
+INSERT INTO logistics.delivery_line_serial_numbers (delivery_line_id, serial_number_id)
+VALUES ($1, $2)
+`
+
+	var delivery Delivery
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		order, err := querySalesOrder(ctx, tx, orderID, true)
+		if err != nil {
+			return err
+		}
+		if !order.Deliverable() {
+			return fmt.Errorf("%w: deliveries can only be created for open sales orders", xerrors.ErrBadRequest)
+		}
+
+		orderLines := make(map[int64]*SalesOrderLine, len(order.Lines))
+		for i := range order.Lines {
+			orderLines[order.Lines[i].ID] = &order.Lines[i]
+		}
+
+		header, err := database.One[DeliveryHeader](ctx, tx, query, orderID, date)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			orderLine, ok := orderLines[line.SalesOrderLineID]
+			if !ok {
+				return fmt.Errorf("%w: line %v does not belong to the sales order", xerrors.ErrBadRequest, line.SalesOrderLineID)
+			}
+			// Compare in thousandths, quantities are stored with three decimals.
+			if math.Round(line.Quantity*1000) > math.Round(orderLine.OpenQuantity()*1000) {
+				return fmt.Errorf("%w: only %v of line %v are still open", xerrors.ErrBadRequest, orderLine.OpenQuantity(), orderLine.ID)
+			}
+
+			deliveryLine, err := database.One[DeliveryLine](ctx, tx, lineQuery, header.ID, line.SalesOrderLineID, line.Quantity, line.BinID, line.BatchID)
+			if err != nil {
+				return err
+			}
+			for _, serialNumberID := range line.SerialNumberIDs {
+				if _, err := tx.Exec(ctx, serialQuery, deliveryLine.ID, serialNumberID); err != nil {
+					return xerrors.Join(xerrors.ErrInternal, err)
+				}
+			}
+			orderLine.DeliveredQuantity += line.Quantity
+		}
+
+		if err := updateSalesOrderStatus(ctx, tx, orderID); err != nil {
+			return err
+		}
+
+		delivery, err = queryDelivery(ctx, tx, header.ID, false)
+		return err
+	})
+
+	return delivery, err
+}
+
+// shipDelivery issues the goods of an open delivery from the plant of its sales order and updates the status of the
+// order.
+func (db Database) shipDelivery(ctx context.Context, id int64) (Delivery, error) {
+	const query = `
+UPDATE logistics.deliveries
+SET status = 'shipped'
+WHERE id = $1
+`
+
+	var delivery Delivery
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryDelivery(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: only open deliveries can be shipped", xerrors.ErrBadRequest)
+		}
+
+		for _, line := range current.Lines {
+			_, err := insertGoodsMovement(ctx, tx, GoodsMovementParams{
+				Type:           MovementIssue,
+				Date:           current.Date,
+				ItemID:         line.ItemID,
+				FromPlantID:    current.PlantID,
+				FromBinID:      line.BinID.Int64,
+				Quantity:       line.Quantity,
+				Reference:      current.Reference(),
+				DeliveryLineID: line.ID,
+				BatchID:        line.BatchID.Int64,
+				SerialNumbers:  strings.Join(line.SerialNumbers, "\n"),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		if err := updateSalesOrderStatus(ctx, tx, current.SalesOrderID); err != nil {
+			return err
+		}
+
+		current.Status = DeliveryStatusShipped
+		delivery = current
+		return nil
+	})
+
+	return delivery, err
+}
+
+// cancelDelivery cancels an open delivery, its quantities are open on the sales order again.
+func (db Database) cancelDelivery(ctx context.Context, id int64) (Delivery, error) {
+	const query = `
+UPDATE logistics.deliveries
+SET status = 'cancelled'
+WHERE id = $1
+`
+
+	var delivery Delivery
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryDelivery(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: only open deliveries can be cancelled", xerrors.ErrBadRequest)
+		}
+
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		if err := updateSalesOrderStatus(ctx, tx, current.SalesOrderID); err != nil {
+			return err
+		}
+
+		current.Status = DeliveryStatusCancelled
+		delivery = current
+		return nil
+	})
+
+	return delivery, err
+}
+
+// pickingList returns the lines of the open deliveries of a plant ordered by bin, so they can be picked in one walk.
+// If deliveryID is valid only the lines of that delivery are returned.
+func (db Database) pickingList(ctx context.Context, plantID int64, deliveryID sql.NullInt64) ([]PickingListLine, error) {
+	const query = `
+SELECT
+	deliveries.id AS delivery_id,
+	deliveries.date,
+	items.name AS item_name,
+	items.sku,
+	lines.quantity,
+	units.code AS unit_code,
+	storage_locations.name AS storage_location_name,
+	bins.name AS bin_name,
+	batches.number AS batch_number,
+	ARRAY(
+		SELECT serial_numbers.number
+		FROM logistics.delivery_line_serial_numbers serials
+		JOIN logistics.serial_numbers serial_numbers ON serial_numbers.id = serials.serial_number_id
+		WHERE serials.delivery_line_id = lines.id
+		ORDER BY serial_numbers.number ASC
+	) AS serial_numbers
+FROM logistics.delivery_lines lines
+JOIN logistics.deliveries deliveries ON deliveries.id = lines.delivery_id
+JOIN logistics.sales_orders orders ON orders.id = deliveries.sales_order_id
+JOIN logistics.sales_order_lines order_lines ON order_lines.id = lines.sales_order_line_id
+JOIN logistics.items items ON items.id = order_lines.item_id
+JOIN logistics.units units ON units.id = items.base_unit_id
+LEFT JOIN logistics.bins bins ON bins.id = lines.bin_id
+LEFT JOIN logistics.storage_locations storage_locations ON storage_locations.id = bins.storage_location_id
+LEFT JOIN logistics.batches batches ON batches.id = lines.batch_id
+WHERE
+	orders.plant_id = $1 AND
+	deliveries.status = 'open' AND
+	(deliveries.id = $2 OR $2 IS NULL)
+ORDER BY storage_locations.name ASC NULLS LAST, bins.name ASC NULLS LAST, items.name ASC, deliveries.id ASC
+`
+
+	return database.Many[PickingListLine](ctx, db.db, query, plantID, deliveryID)
+}
+
+func (db Database) shipment(ctx context.Context, id int64) (Shipment, error) {
+	return queryShipment(ctx, db.db, id, false)
+}
+
+// queryShipment queries a shipment with its packages. If lock is set, the shipment is locked until the end of the
+// transaction q belongs to.
+func queryShipment(ctx context.Context, q database.Querier, id int64, lock bool) (Shipment, error) {
+	headerQuery := `
+SELECT *
+FROM logistics.shipments
+WHERE id = $1
+`
+	if lock {
+		headerQuery += "FOR UPDATE\n"
+	}
+
+	header, err := database.One[ShipmentHeader](ctx, q, headerQuery, id)
+	if err != nil {
+		return Shipment{}, err
+	}
+
+	const packagesQuery = `
+SELECT *
+FROM logistics.shipment_packages
+WHERE shipment_id = $1
+ORDER BY id ASC
+`
+
+	packages, err := database.Many[ShipmentPackage](ctx, q, packagesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return Shipment{}, err
+	}
+
+	return Shipment{ShipmentHeader: header, Packages: packages}, nil
+}
+
+func (db Database) shipments(ctx context.Context, filter ShipmentFilter) ([]ShipmentHeader, error) {
+	const query = `
+SELECT *
+FROM logistics.shipments
+WHERE
+	(delivery_id = $1 OR $1 IS NULL) AND
+	(carrier     = $2 OR $2 IS NULL) AND
+	(status      = $3 OR $3 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[ShipmentHeader](ctx, db.db, query, filter.deliveryID, filter.carrier, filter.status)
+}
+
+// createShipment creates a shipment for a delivery, the delivery is locked so it can not be cancelled at the same time.
+func (db Database) createShipment(ctx context.Context, deliveryID int64, params ShipmentParams) (Shipment, error) {
+	const query = `
+INSERT INTO logistics.shipments (delivery_id, carrier, service)
+VALUES ($1, $2, $3)
+RETURNING *
+`
+
+	var shipment Shipment
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		delivery, err := queryDelivery(ctx, tx, deliveryID, true)
+		if err != nil {
+			return err
+		}
+		if delivery.Status == DeliveryStatusCancelled {
+			return fmt.Errorf("%w: cancelled deliveries can not be shipped", xerrors.ErrBadRequest)
+		}
+
+		shipment.ShipmentHeader, err = database.One[ShipmentHeader](ctx, tx, query, deliveryID, params.Carrier, params.Service)
+		return err
+	})
+
+	return shipment, err
+}
+
+// changeShipmentPackages runs change on an open shipment, the shipment is locked so packages can not change while the
+// label is created.
+func (db Database) changeShipmentPackages(ctx context.Context, id int64, change func(tx pgx.Tx) error) (Shipment, error) {
+	var shipment Shipment
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryShipment(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: packages can only be changed while the shipment is open", xerrors.ErrBadRequest)
+		}
+
+		if err := change(tx); err != nil {
+			return err
+		}
+
+		shipment, err = queryShipment(ctx, tx, id, false)
+		return err
+	})
+
+	return shipment, err
+}
+
+func (db Database) createShipmentPackage(ctx context.Context, shipmentID int64, params ShipmentPackageParams) (Shipment, error) {
+	const query = `
+INSERT INTO logistics.shipment_packages (shipment_id, length, width, height, weight)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+	return db.changeShipmentPackages(ctx, shipmentID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, shipmentID, params.Length, params.Width, params.Height, params.Weight); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+		return nil
+	})
+}
+
+func (db Database) deleteShipmentPackage(ctx context.Context, shipmentID int64, packageID int64) (Shipment, error) {
+	const query = `
+DELETE FROM logistics.shipment_packages
+WHERE shipment_id = $1 AND id = $2
+`
+
+	return db.changeShipmentPackages(ctx, shipmentID, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, shipmentID, packageID)
+		if err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return xerrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// labelShipment stores the tracking numbers of an open shipment and marks it as labeled. label has to have a tracking
+// number for every package of the shipment.
+func (db Database) labelShipment(ctx context.Context, id int64, label CarrierLabel) (Shipment, error) {
+	const query = `
+UPDATE logistics.shipments
+SET
+	tracking_number = $2,
+	status          = 'labeled'
+WHERE id = $1
+`
+	const packageQuery = `
+UPDATE logistics.shipment_packages
+SET tracking_number = $2
+WHERE id = $1
+`
+
+	var shipment Shipment
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryShipment(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: labels can only be created for open shipments", xerrors.ErrBadRequest)
+		}
+		if len(label.PackageTrackingNumbers) != len(current.Packages) {
+			return fmt.Errorf("%w: the packages of the shipment changed while the label was created", xerrors.ErrBadRequest)
+		}
+
+		if _, err := tx.Exec(ctx, query, id, label.TrackingNumber); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+		for i, pkg := range current.Packages {
+			if _, err := tx.Exec(ctx, packageQuery, pkg.ID, label.PackageTrackingNumbers[i]); err != nil {
+				return xerrors.Join(xerrors.ErrInternal, err)
+			}
+		}
+
+		shipment, err = queryShipment(ctx, tx, id, false)
+		return err
+	})
+
+	return shipment, err
+}
+
+// cancelShipment cancels a shipment that has not been cancelled yet, its tracking numbers are kept.
+func (db Database) cancelShipment(ctx context.Context, id int64) (Shipment, error) {
+	const query = `
+UPDATE logistics.shipments
+SET status = 'cancelled'
+WHERE id = $1
+`
+
+	var shipment Shipment
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryShipment(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if current.Status == ShipmentStatusCancelled {
+			return fmt.Errorf("%w: the shipment has already been cancelled", xerrors.ErrBadRequest)
+		}
+
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		current.Status = ShipmentStatusCancelled
+		shipment = current
+		return nil
+	})
+
+	return shipment, err
+}
+
+func (db Database) batch(ctx context.Context, id int64) (Batch, error) {
+	const query = `
+SELECT *
+FROM logistics.batches
+WHERE id = $1
+`
+
+	return database.One[Batch](ctx, db.db, query, id)
+}
+
+func (db Database) batches(ctx context.Context, filter BatchFilter) ([]Batch, error) {
+	const query = `
+SELECT *
+FROM logistics.batches
+WHERE
+	(item_id = $1 OR $1 IS NULL) AND
+	(number LIKE $2 OR $2 IS NULL)
+ORDER BY item_id ASC, NULLIF(expiry_date, '') ASC NULLS LAST, number ASC
+`
+
+	return database.Many[Batch](ctx, db.db, query, filter.itemID, filter.number)
+}
+
+func (db Database) updateBatch(ctx context.Context, id int64, params BatchParams) (Batch, error) {
+	const query = `
+UPDATE logistics.batches
+SET
+	manufacture_date = $2,
+	expiry_date      = $3
+WHERE id = $1
+RETURNING *
+`
+
+	return database.One[Batch](ctx, db.db, query, id, params.ManufactureDate, params.ExpiryDate)
+}
+
+// batchStock returns the batches of an item with stock, first expiring first. If plantID is valid only stock in that
+// plant is returned.
+func (db Database) batchStock(ctx context.Context, itemID int64, plantID sql.NullInt64) ([]BatchStock, error) {
+	const query = `
+SELECT batches.*, stock.plant_id, SUM(stock.quantity) AS quantity
+FROM logistics.stock stock
+JOIN logistics.batches batches ON batches.id = stock.batch_id
+WHERE stock.item_id = $1 AND (stock.plant_id = $2 OR $2 IS NULL)
+GROUP BY batches.id, stock.plant_id
+HAVING SUM(stock.quantity) > 0
+ORDER BY NULLIF(batches.expiry_date, '') ASC NULLS LAST, batches.id ASC, stock.plant_id ASC
+`
+
+	return database.Many[BatchStock](ctx, db.db, query, itemID, plantID)
+}
+
+func (db Database) serialNumber(ctx context.Context, id int64) (SerialNumber, error) {
+	const query = `
+SELECT serial_numbers.*, stock.plant_id, stock.bin_id
+FROM logistics.serial_numbers serial_numbers
+LEFT JOIN logistics.serial_number_stock stock ON stock.serial_number_id = serial_numbers.id
+WHERE serial_numbers.id = $1
+`
+
+	return database.One[SerialNumber](ctx, db.db, query, id)
+}
+
+func (db Database) serialNumbers(ctx context.Context, filter SerialNumberFilter) ([]SerialNumber, error) {
+	const query = `
+SELECT serial_numbers.*, stock.plant_id, stock.bin_id
+FROM logistics.serial_numbers serial_numbers
+LEFT JOIN logistics.serial_number_stock stock ON stock.serial_number_id = serial_numbers.id
+WHERE
+	(serial_numbers.item_id = $1 OR $1 IS NULL) AND
+	(serial_numbers.number LIKE $2 OR $2 IS NULL) AND
+	(stock.plant_id = $3 OR $3 IS NULL)
+ORDER BY serial_numbers.item_id ASC, serial_numbers.number ASC
+`
+
+	return database.Many[SerialNumber](ctx, db.db, query, filter.itemID, filter.number, filter.plantID)
+}
+
+// trace returns every goods movement of a batch or a serial number, oldest first.
+func (db Database) trace(ctx context.Context, batchID sql.NullInt64, serialNumberID sql.NullInt64) ([]TraceMovement, error) {
+	const query = `
+SELECT
+	movements.*,
+	purchase_orders.id AS purchase_order_id,
+	suppliers.name AS supplier_name,
+	deliveries.id AS delivery_id,
+	customers.name AS customer_name
+FROM logistics.goods_movements movements
+LEFT JOIN logistics.purchase_order_lines purchase_order_lines ON purchase_order_lines.id = movements.purchase_order_line_id
+LEFT JOIN logistics.purchase_orders purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id
+LEFT JOIN logistics.suppliers suppliers ON suppliers.id = purchase_orders.supplier_id
+LEFT JOIN logistics.delivery_lines delivery_lines ON delivery_lines.id = movements.delivery_line_id
+LEFT JOIN logistics.deliveries deliveries ON deliveries.id = delivery_lines.delivery_id
+LEFT JOIN logistics.sales_orders sales_orders ON sales_orders.id = deliveries.sales_order_id
+LEFT JOIN logistics.customers customers ON customers.id = sales_orders.customer_id
+WHERE
+	(movements.batch_id = $1 OR $1 IS NULL) AND
+	($2::INTEGER IS NULL OR EXISTS (
+		SELECT 1
+		FROM logistics.goods_movement_serial_numbers serials
+		WHERE serials.goods_movement_id = movements.id AND serials.serial_number_id = $2
+	))
+ORDER BY movements.id ASC
+`
+
+	return database.Many[TraceMovement](ctx, db.db, query, batchID, serialNumberID)
+}
+
+func (db Database) planningParameters(ctx context.Context, filter PlanningParametersFilter) ([]PlanningParameters, error) {
+	const query = `
+SELECT *
+FROM logistics.planning_parameters
+WHERE
+	(item_id  = $1 OR $1 IS NULL) AND
+	(plant_id = $2 OR $2 IS NULL)
+ORDER BY item_id ASC, plant_id ASC
+`
+
+	return database.Many[PlanningParameters](ctx, db.db, query, filter.itemID, filter.plantID)
+}
+
+// setPlanningParameters creates the planning parameters of an item in a plant or updates them if they already exist.
+func (db Database) setPlanningParameters(ctx context.Context, itemID int64, params PlanningParametersParams) (PlanningParameters, error) {
+	const query = `
+INSERT INTO logistics.planning_parameters (item_id, plant_id, reorder_point, safety_stock, lot_size, lead_time_days, supplier_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (item_id, plant_id) DO UPDATE SET
+	reorder_point  = EXCLUDED.reorder_point,
+	safety_stock   = EXCLUDED.safety_stock,
+	lot_size       = EXCLUDED.lot_size,
+	lead_time_days = EXCLUDED.lead_time_days,
+	supplier_id    = EXCLUDED.supplier_id
+RETURNING *
+`
+
+	return database.One[PlanningParameters](ctx, db.db, query, itemID, params.PlantID, params.ReorderPoint, params.SafetyStock,
+		params.LotSize, params.LeadTimeDays, nullID(params.SupplierID))
+}
+
+func (db Database) deletePlanningParameters(ctx context.Context, itemID int64, plantID int64) error {
+	const query = `
+DELETE FROM logistics.planning_parameters
+WHERE item_id = $1 AND plant_id = $2
+`
+
+	if _, err := db.db.Exec(ctx, query, itemID, plantID); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	return nil
+}
+
+// projections returns the projected stock of every item and plant with planning parameters. Cancelled orders are
+// ignored, open quantities are never negative.
+func (db Database) projections(ctx context.Context, filter PlanningParametersFilter) ([]Projection, error) {
+	const query = `
+SELECT
+	parameters.*,
+	items.name AS item_name,
+	plants.name AS plant_name,
+	units.code AS unit_code,
+	units.decimals,
+	COALESCE((
+		SELECT SUM(stock.quantity)
+		FROM logistics.stock stock
+		WHERE stock.item_id = parameters.item_id AND stock.plant_id = parameters.plant_id
+	), 0) AS on_hand,
+	COALESCE((
+		SELECT SUM(GREATEST(lines.base_quantity - COALESCE((
+			SELECT SUM(movements.quantity)
+			FROM logistics.goods_movements movements
+			WHERE movements.purchase_order_line_id = lines.id
+		), 0), 0))
+		FROM logistics.purchase_order_lines lines
+		JOIN logistics.purchase_orders orders ON orders.id = lines.purchase_order_id
+		WHERE lines.item_id = parameters.item_id AND orders.plant_id = parameters.plant_id AND orders.status <> 'cancelled'
+	), 0) AS open_supply,
+	COALESCE((
+		SELECT SUM(GREATEST(lines.base_quantity - COALESCE((
+			SELECT SUM(delivery_lines.quantity)
+			FROM logistics.delivery_lines delivery_lines
+			JOIN logistics.deliveries deliveries ON deliveries.id = delivery_lines.delivery_id
+			WHERE delivery_lines.sales_order_line_id = lines.id AND deliveries.status = 'shipped'
+		), 0), 0))
+		FROM logistics.sales_order_lines lines
+		JOIN logistics.sales_orders orders ON orders.id = lines.sales_order_id
+		WHERE lines.item_id = parameters.item_id AND orders.plant_id = parameters.plant_id AND orders.status <> 'cancelled'
+	), 0) AS open_demand
+FROM logistics.planning_parameters parameters
+JOIN logistics.items items ON items.id = parameters.item_id
+JOIN logistics.plants plants ON plants.id = parameters.plant_id
+JOIN logistics.units units ON units.id = items.base_unit_id
+WHERE
+	(parameters.item_id  = $1 OR $1 IS NULL) AND
+	(parameters.plant_id = $2 OR $2 IS NULL)
+ORDER BY items.name ASC, plants.name ASC
+`
+
+	return database.Many[Projection](ctx, db.db, query, filter.itemID, filter.plantID)
+}
+
+func (db Database) purchaseProposal(ctx context.Context, id int64) (PurchaseProposal, error) {
+	const query = `
+SELECT *
+FROM logistics.purchase_proposals
+WHERE id = $1
+`
+
+	return database.One[PurchaseProposal](ctx, db.db, query, id)
+}
+
+func (db Database) purchaseProposals(ctx context.Context, filter PurchaseProposalFilter) ([]PurchaseProposal, error) {
+	const query = `
+SELECT *
+FROM logistics.purchase_proposals
+WHERE
+	(item_id  = $1 OR $1 IS NULL) AND
+	(plant_id = $2 OR $2 IS NULL) AND
+	(status   = $3 OR $3 IS NULL)
+ORDER BY delivery_date ASC, id ASC
+`
+
+	return database.Many[PurchaseProposal](ctx, db.db, query, filter.itemID, filter.plantID, filter.status)
+}
+
+// replacePurchaseProposals deletes all open purchase proposals and inserts the proposals of a new planning run. The table
+// is locked, so concurrent runs do not leave duplicate proposals.
+func (db Database) replacePurchaseProposals(ctx context.Context, proposals []PurchaseProposal) ([]PurchaseProposal, error) {
+	const lockQuery = `
+LOCK TABLE logistics.purchase_proposals IN SHARE ROW EXCLUSIVE MODE
+`
+	const deleteQuery = `
+DELETE FROM logistics.purchase_proposals
+WHERE status = 'open'
+`
+	const query = `
+INSERT INTO logistics.purchase_proposals (item_id, plant_id, supplier_id, quantity, delivery_date, projected_quantity, below_safety_stock)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *
+`
+
+	var created []PurchaseProposal
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		if _, err := tx.Exec(ctx, deleteQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		for _, proposal := range proposals {
+			proposal, err := database.One[PurchaseProposal](ctx, tx, query, proposal.ItemID, proposal.PlantID, proposal.SupplierID,
+				proposal.Quantity, proposal.DeliveryDate, proposal.ProjectedQuantity, proposal.BelowSafetyStock)
+			if err != nil {
+				return err
+			}
+
+			created = append(created, proposal)
+		}
+
+		return nil
+	})
+
+	return created, err
+}
+
+func (db Database) updatePurchaseProposal(ctx context.Context, id int64, params PurchaseProposalParams) (PurchaseProposal, error) {
+	const query = `
+UPDATE logistics.purchase_proposals
+SET
+	supplier_id   = $2,
+	quantity      = $3,
+	delivery_date = $4
+WHERE id = $1 AND status = 'open'
+RETURNING *
+`
+
+	proposal, err := database.One[PurchaseProposal](ctx, db.db, query, id, nullID(params.SupplierID), params.Quantity, params.DeliveryDate)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return PurchaseProposal{}, fmt.Errorf("%w: only open purchase proposals can be changed", xerrors.ErrBadRequest)
+	}
+
+	return proposal, err
+}
+
+func (db Database) discardPurchaseProposal(ctx context.Context, id int64) (PurchaseProposal, error) {
+	const query = `
+UPDATE logistics.purchase_proposals
+SET status = 'discarded'
+WHERE id = $1 AND status = 'open'
+RETURNING *
+`
+
+	proposal, err := database.One[PurchaseProposal](ctx, db.db, query, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return PurchaseProposal{}, fmt.Errorf("%w: only open purchase proposals can be discarded", xerrors.ErrBadRequest)
+	}
+
+	return proposal, err
+}
+
+// acceptPurchaseProposal creates the purchase order of an open proposal and marks the proposal as accepted. The
+// proposal is locked, so it can only be accepted once.
+func (db Database) acceptPurchaseProposal(ctx context.Context, id int64, header PurchaseOrderHeaderParams, lines []orderLineValues) (PurchaseOrder, error) {
+	const lockQuery = `
+SELECT *
+FROM logistics.purchase_proposals
+WHERE id = $1
+FOR UPDATE
+`
+	const query = `
+UPDATE logistics.purchase_proposals
+SET status = 'accepted', purchase_order_id = $2
+WHERE id = $1
+`
+
+	var order PurchaseOrder
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		proposal, err := database.One[PurchaseProposal](ctx, tx, lockQuery, id)
+		if err != nil {
+			return err
+		}
+		if !proposal.Open() {
+			return fmt.Errorf("%w: only open purchase proposals can be accepted", xerrors.ErrBadRequest)
+		}
+
+		order, err = insertPurchaseOrder(ctx, tx, header, lines)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, query, id, order.ID); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		return nil
+	})
+
+	return order, err
+}
+
+func (db Database) inventoryCount(ctx context.Context, id int64) (InventoryCount, error) {
+	return queryInventoryCount(ctx, db.db, id, false)
+}
+
+// queryInventoryCount queries an inventory count with its lines. If lock is set, the count is locked until the end of
+// the transaction q belongs to.
+func queryInventoryCount(ctx context.Context, q database.Querier, id int64, lock bool) (InventoryCount, error) {
+	headerQuery := `
+SELECT *
+FROM logistics.inventory_counts
+WHERE id = $1
+`
+	if lock {
+		headerQuery += "FOR UPDATE\n"
+	}
+
+	header, err := database.One[InventoryCountHeader](ctx, q, headerQuery, id)
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	const linesQuery = `
+SELECT
+	lines.*,
+	items.name AS item_name,
+	items.sku AS item_sku,
+	items.tracking,
+	units.code AS unit_code,
+	units.decimals,
+	bins.name AS bin_name,
+	batches.number AS batch_number
+FROM logistics.inventory_count_lines lines
+JOIN logistics.items items ON items.id = lines.item_id
+JOIN logistics.units units ON units.id = items.base_unit_id
+LEFT JOIN logistics.bins bins ON bins.id = lines.bin_id
+LEFT JOIN logistics.batches batches ON batches.id = lines.batch_id
+WHERE lines.inventory_count_id = $1
+ORDER BY items.name ASC, bins.name ASC NULLS FIRST, batches.number ASC NULLS FIRST, lines.id ASC
+`
+
+	lines, err := database.Many[InventoryCountLine](ctx, q, linesQuery, id)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return InventoryCount{}, err
+	}
+
+	return InventoryCount{InventoryCountHeader: header, Lines: lines}, nil
+}
+
+func (db Database) inventoryCounts(ctx context.Context, filter InventoryCountFilter) ([]InventoryCountHeader, error) {
+	const query = `
+SELECT *
+FROM logistics.inventory_counts
+WHERE
+	(plant_id = $1 OR $1 IS NULL) AND
+	(status   = $2 OR $2 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[InventoryCountHeader](ctx, db.db, query, filter.plantID, filter.status)
+}
+
+// createInventoryCount creates an inventory count with a line per stock position of the items in the plant. Goods
+// movements are locked out while the book quantities are recorded. Movements check for open counts after they have
+// been inserted, so a movement either finished before the book quantities are recorded or sees the count and fails.
+func (db Database) createInventoryCount(ctx context.Context, params InventoryCountParams) (InventoryCount, error) {
+	const lockQuery = `
+LOCK TABLE logistics.goods_movements IN SHARE MODE
+`
+	const countLockQuery = `
+LOCK TABLE logistics.inventory_counts IN SHARE ROW EXCLUSIVE MODE
+`
+	const openQuery = `
+SELECT DISTINCT items.*
+FROM logistics.inventory_count_lines lines
+JOIN logistics.inventory_counts counts ON counts.id = lines.inventory_count_id
+JOIN logistics.items items ON items.id = lines.item_id
+WHERE counts.status = 'open' AND counts.plant_id = $1 AND lines.item_id = ANY($2)
+`
+	const query = `
+INSERT INTO logistics.inventory_counts (plant_id, date, note)
+VALUES ($1, $2, $3)
+RETURNING *
+`
+	const linesQuery = `
+INSERT INTO logistics.inventory_count_lines (inventory_count_id, item_id, bin_id, batch_id, book_quantity)
+SELECT $1, items.id, stock.bin_id, stock.batch_id, COALESCE(stock.quantity, 0)
+FROM logistics.items items
+LEFT JOIN logistics.stock stock ON stock.item_id = items.id AND stock.plant_id = $2 AND stock.quantity <> 0
+WHERE items.id = ANY($3)
+`
+
+	var id int64
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+		if _, err := tx.Exec(ctx, countLockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		counted, err := database.Many[Item](ctx, tx, openQuery, params.PlantID, params.ItemIDs)
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return err
+		}
+		if len(counted) > 0 {
+			return fmt.Errorf("%w: item %v is already counted by an open inventory count of the plant", xerrors.ErrBadRequest, counted[0].Name)
+		}
+
+		header, err := database.One[InventoryCountHeader](ctx, tx, query, params.PlantID, params.Date, params.Note)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, linesQuery, header.ID, params.PlantID, params.ItemIDs); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		id = header.ID
+		return nil
+	})
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	return db.inventoryCount(ctx, id)
+}
+
+// setCountedQuantities stores counted quantities of lines of an open inventory count.
+func (db Database) setCountedQuantities(ctx context.Context, id int64, quantities CountedQuantities) (InventoryCount, error) {
+	const query = `
+UPDATE logistics.inventory_count_lines
+SET counted_quantity = $3
+WHERE id = $1 AND inventory_count_id = $2
+`
+
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryInventoryCount(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: only open inventory counts can be counted", xerrors.ErrBadRequest)
+		}
+
+		for lineID, quantity := range quantities {
+			tag, err := tx.Exec(ctx, query, lineID, id, quantity)
+			if err != nil {
+				return xerrors.Join(xerrors.ErrInternal, err)
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("%w: line %v does not belong to the inventory count", xerrors.ErrBadRequest, lineID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	return db.inventoryCount(ctx, id)
+}
+
+// approveInventoryCount closes a fully counted inventory count and posts the differences as adjustments dated date.
+// The count is closed before the adjustments are inserted, so they are not blocked by it.
+func (db Database) approveInventoryCount(ctx context.Context, id int64, date string) (InventoryCount, error) {
+	const query = `
+UPDATE logistics.inventory_counts
+SET status = 'approved', approved_at = now()
+WHERE id = $1
+`
+	const lineQuery = `
+UPDATE logistics.inventory_count_lines
+SET movement_id = $2
+WHERE id = $1
+`
+
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		current, err := queryInventoryCount(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if !current.Open() {
+			return fmt.Errorf("%w: only open inventory counts can be approved", xerrors.ErrBadRequest)
+		}
+		if !current.Counted() {
+			return fmt.Errorf("%w: every line has to be counted before the inventory count can be approved", xerrors.ErrBadRequest)
+		}
+
+		if _, err := tx.Exec(ctx, query, id); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		for _, line := range current.Lines {
+			difference := line.Difference()
+			if difference == 0 {
+				continue
+			}
+
+			params := GoodsMovementParams{
+				Type:      MovementAdjustment,
+				Date:      date,
+				ItemID:    line.ItemID,
+				Quantity:  math.Abs(difference),
+				Reference: current.Reference(),
+				Note:      fmt.Sprintf("Book quantity %v, counted %v", line.BookQuantity, line.CountedQuantity.Float64),
+				BatchID:   line.BatchID.Int64,
+			}
+			if difference > 0 {
+				params.ToPlantID, params.ToBinID = current.PlantID, line.BinID.Int64
+			} else {
+				params.FromPlantID, params.FromBinID = current.PlantID, line.BinID.Int64
+			}
+			if line.Tracking == TrackingBatch && !line.BatchID.Valid {
+				return fmt.Errorf("%w: stock of %v found without a batch has to be received with its batch number", xerrors.ErrBadRequest, line.ItemName)
+			}
+
+			movement, err := insertGoodsMovement(ctx, tx, params)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, lineQuery, line.ID, movement.ID); err != nil {
+				return xerrors.Join(xerrors.ErrInternal, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	return db.inventoryCount(ctx, id)
+}
+
+func (db Database) cancelInventoryCount(ctx context.Context, id int64) (InventoryCountHeader, error) {
+	const query = `
+UPDATE logistics.inventory_counts
+SET status = 'cancelled'
+WHERE id = $1 AND status = 'open'
+RETURNING *
+`
+
+	count, err := database.One[InventoryCountHeader](ctx, db.db, query, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return InventoryCountHeader{}, fmt.Errorf("%w: only open inventory counts can be cancelled", xerrors.ErrBadRequest)
+	}
+
+	return count, err
 }
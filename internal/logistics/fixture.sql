@@ -1,9 +1,31 @@
+INSERT INTO logistics.units (id, code, name, decimals)
+VALUES
+    (1, 'PCE', 'Piece', 0),
+    (2, 'KG', 'Kilogram', 3),
+    (3, 'M', 'Metre', 3),
+    (4, 'L', 'Litre', 3),
+    (5, 'BOX', 'Box', 0)
+ON CONFLICT DO NOTHING;
+
 INSERT INTO logistics.item_categories (id, name)
 VALUES (1, 'None')
 ON CONFLICT DO NOTHING;
 
+INSERT INTO logistics.tax_categories (id, name, rate)
+VALUES
+    (1, 'Standard', 19),
+    (2, 'Reduced', 7),
+    (3, 'Exempt', 0)
+ON CONFLICT DO NOTHING;
+
+-- Keep the sequences ahead of the fixed IDs above, so created rows do not collide with them.
+SELECT setval(pg_get_serial_sequence('logistics.units', 'id'), GREATEST((SELECT MAX(id) FROM logistics.units), 1));
+SELECT setval(pg_get_serial_sequence('logistics.item_categories', 'id'), GREATEST((SELECT MAX(id) FROM logistics.item_categories), 1));
+SELECT setval(pg_get_serial_sequence('logistics.tax_categories', 'id'), GREATEST((SELECT MAX(id) FROM logistics.tax_categories), 1));
+
 INSERT INTO logistics.addresses (zip, city, street, country)
-VALUES ('44444', 'a city', 'a street', 'a country')
+VALUES ('44444', 'a city', 'a street', 'DE');
 
-INSERT INTO logistics.addresses (name, sku, category_id, gross_price, net_price)
-VALUES ('an item', 1, 499.99, 899.99)
+INSERT INTO logistics.items (name, sku, category_id, gross_price, net_price, base_unit_id)
+VALUES ('an item', 'an-item', 1, 89999, 75629, 1)
+ON CONFLICT DO NOTHING;
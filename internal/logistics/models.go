@@ -2,7 +2,94 @@ package logistics
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+const (
+	PurchaseOrderStatusOpen              = "open"
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	PurchaseOrderStatusReceived          = "received"
+	PurchaseOrderStatusCancelled         = "cancelled"
+)
+
+const (
+	SalesOrderStatusOrdered            = "ordered"
+	SalesOrderStatusPartiallyDelivered = "partially_delivered"
+	SalesOrderStatusDelivered          = "delivered"
+	SalesOrderStatusShipped            = "shipped"
+	SalesOrderStatusCancelled          = "cancelled"
+)
+
+const (
+	DeliveryStatusOpen      = "open"
+	DeliveryStatusShipped   = "shipped"
+	DeliveryStatusCancelled = "cancelled"
+)
+
+// Barcodes of item labels.
+const (
+	BarcodeCode128 = "code128"
+	BarcodeEAN13   = "ean13"
+	BarcodeQR      = "qr"
+)
+
+// Contents of item label barcodes.
+const (
+	LabelContentSKU  = "sku"
+	LabelContentGTIN = "gtin"
+)
+
+const (
+	ShipmentStatusOpen      = "open"
+	ShipmentStatusLabeled   = "labeled"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+const (
+	ProposalStatusOpen      = "open"
+	ProposalStatusAccepted  = "accepted"
+	ProposalStatusDiscarded = "discarded"
+)
+
+const (
+	CountStatusOpen      = "open"
+	CountStatusApproved  = "approved"
+	CountStatusCancelled = "cancelled"
+)
+
+// Tracking modes of items. Batch managed items are moved in batches, serialized items with a serial number per unit.
+const (
+	TrackingNone   = "none"
+	TrackingBatch  = "batch"
+	TrackingSerial = "serial"
+)
+
+// Price entries of items, the entered price is either the net or the gross price.
+const (
+	PriceEntryNet   = "net"
+	PriceEntryGross = "gross"
+)
+
+// Types of item attributes. Enum attributes take one of their options, variants are generated from them.
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+)
+
+const (
+	MovementReceipt    = "receipt"
+	MovementIssue      = "issue"
+	MovementTransfer   = "transfer"
+	MovementAdjustment = "adjustment"
 )
 
 type Item struct {
@@ -12,6 +99,16 @@ type Item struct {
 	CategoryID int64  `db:"category_id" json:"category_id"`
 	GrossPrice int64  `db:"gross_price" json:"gross_price"`
 	NetPrice   int64  `db:"net_price" json:"net_price"`
+	BaseUnitID int64  `db:"base_unit_id" json:"base_unit_id"`
+	Tracking   string `db:"tracking" json:"tracking"`
+	// ParentID is the item a variant was generated from, it is empty for all other items.
+	ParentID sql.NullInt64 `db:"parent_id" json:"parent_id"`
+	// PriceEntry names the price that was entered, the other one is derived with the rate of the tax category.
+	TaxCategoryID int64  `db:"tax_category_id" json:"tax_category_id"`
+	Currency      string `db:"currency" json:"currency"`
+	PriceEntry    string `db:"price_entry" json:"price_entry"`
+	// GTIN is the GS1 Global Trade Item Number with 8, 12, 13 or 14 digits, it is empty if the item has none.
+	GTIN string `db:"gtin" json:"gtin"`
 }
 
 type ItemParams struct {
@@ -20,6 +117,17 @@ type ItemParams struct {
 	CategoryID int64  `json:"category_id" form:"category_id"`
 	GrossPrice int64  `json:"gross_price" form:"gross_price"`
 	NetPrice   int64  `json:"net_price" form:"net_price"`
+	BaseUnitID int64  `json:"base_unit_id" form:"base_unit_id"`
+	Tracking   string `json:"tracking" form:"tracking"`
+	// Attributes are the attribute values by attribute ID, they replace all values of the item.
+	Attributes    map[int64]string `json:"attributes" form:"attributes"`
+	TaxCategoryID int64            `json:"tax_category_id" form:"tax_category_id"`
+	Currency      string           `json:"currency" form:"currency"`
+	// PriceEntry names the entered price, the other one is derived from it. Without a price entry both prices are
+	// taken as given and have to agree with the tax rate.
+	PriceEntry string `json:"price_entry" form:"price_entry"`
+	// GTIN may contain spaces and hyphens, they are removed.
+	GTIN string `json:"gtin" form:"gtin"`
 }
 
 type ItemFilter struct {
@@ -28,13 +136,164 @@ type ItemFilter struct {
 	categoryID sql.NullInt64
 	grossPrice sql.NullInt64
 	netPrice   sql.NullInt64
+	tracking   sql.NullString
+	parentID   sql.NullInt64
+	// gtin matches GTINs of any length that identify the same trade item.
+	gtin sql.NullString
+}
+
+type Unit struct {
+	ID       int64  `db:"id" json:"id"`
+	Code     string `db:"code" json:"code"`
+	Name     string `db:"name" json:"name"`
+	Decimals int    `db:"decimals" json:"decimals"`
+}
+
+type UnitParams struct {
+	Code     string `form:"code" json:"code"`
+	Name     string `form:"name" json:"name"`
+	Decimals int    `form:"decimals" json:"decimals"`
+}
+
+type UnitFilter struct{}
+
+// ItemUnit is an alternative unit of an item, one unit equals Factor base units of the item.
+type ItemUnit struct {
+	ItemID   int64   `db:"item_id" json:"item_id"`
+	UnitID   int64   `db:"unit_id" json:"unit_id"`
+	Factor   float64 `db:"factor" json:"factor"`
+	Code     string  `db:"code" json:"code"`
+	Name     string  `db:"name" json:"name"`
+	Decimals int     `db:"decimals" json:"decimals"`
+}
+
+type ItemUnitParams struct {
+	UnitID int64   `form:"unit_id" json:"unit_id"`
+	Factor float64 `form:"factor" json:"factor"`
+}
+
+// BOMLine is a line of the bill of materials of an item, one base unit of the item is assembled from Quantity base units
+// of the component. The component name, SKU and unit code are read from the component.
+type BOMLine struct {
+	ItemID        int64   `db:"item_id" json:"item_id"`
+	ComponentID   int64   `db:"component_id" json:"component_id"`
+	Quantity      float64 `db:"quantity" json:"quantity"`
+	ComponentName string  `db:"component_name" json:"component_name"`
+	ComponentSKU  string  `db:"component_sku" json:"component_sku"`
+	UnitCode      string  `db:"unit_code" json:"unit_code"`
+}
+
+// BOMLineParams use a unit ID of zero for quantities in the base unit of the component.
+type BOMLineParams struct {
+	ComponentID int64   `form:"component_id" json:"component_id"`
+	Quantity    float64 `form:"quantity" json:"quantity"`
+	UnitID      int64   `form:"unit_id" json:"unit_id"`
+}
+
+// BOMExplosion is the multi-level bill of materials of Quantity base units of an item. Lines are ordered depth first,
+// Requirements are the totals of the components that are not assembled themselves. Costs are rolled up from the net
+// prices of these components.
+type BOMExplosion struct {
+	Item         Item
+	Quantity     float64
+	UnitCode     string
+	Lines        []ExplosionLine
+	Requirements []ComponentRequirement
+	Cost         int64
+}
+
+// ExplosionLine is a component on Level of an explosion, level one being the direct components of the exploded item.
+// QuantityPer is the quantity per unit of the parent, Quantity the quantity required for the explosion.
+type ExplosionLine struct {
+	Level       int
+	Component   Item
+	QuantityPer float64
+	Quantity    float64
+	UnitCode    string
+	Cost        int64
+	Assembly    bool
+}
+
+type ComponentRequirement struct {
+	Component Item
+	Quantity  float64
+	UnitCode  string
+	Cost      int64
+}
+
+// UnitConversion converts quantities of an item entered in Unit into the base unit of the item.
+type UnitConversion struct {
+	Unit     Unit
+	BaseUnit Unit
+	// Factor is the number of base units per unit.
+	Factor float64
 }
 
 type ItemCategory struct {
-	ID   int64  `db:"id"`
-	Name string `db:"name"`
+	ID   int64  `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+	// ParentID is empty for top-level categories.
+	ParentID sql.NullInt64 `db:"parent_id" json:"parent_id"`
+	// DefaultBaseUnitID and DefaultTracking are used for new items that do not set them, empty defaults are inherited
+	// from the parent category.
+	DefaultBaseUnitID sql.NullInt64 `db:"default_base_unit_id" json:"default_base_unit_id"`
+	DefaultTracking   string        `db:"default_tracking" json:"default_tracking"`
+	// Path are the names of the category and its ancestors separated by slashes, Depth is the number of ancestors.
+	Path  string `db:"path" json:"path"`
+	Depth int    `db:"depth" json:"depth"`
+}
+
+// ItemCategoryParams uses a zero parent ID for top-level categories and zero values for defaults that are inherited.
+type ItemCategoryParams struct {
+	Name              string `form:"name" json:"name"`
+	ParentID          int64  `form:"parent_id" json:"parent_id"`
+	DefaultBaseUnitID int64  `form:"default_base_unit_id" json:"default_base_unit_id"`
+	DefaultTracking   string `form:"default_tracking" json:"default_tracking"`
+}
+
+type ItemCategoryFilter struct {
+	parentID sql.NullInt64
+}
+
+// ItemAttribute is a typed attribute of the items of a category and of its subcategories.
+type ItemAttribute struct {
+	ID         int64    `db:"id" json:"id"`
+	CategoryID int64    `db:"category_id" json:"category_id"`
+	Name       string   `db:"name" json:"name"`
+	Type       string   `db:"type" json:"type"`
+	Options    []string `db:"options" json:"options"`
+	Required   bool     `db:"required" json:"required"`
+}
+
+// ItemAttributeParams takes the options of enum attributes as a comma separated list.
+type ItemAttributeParams struct {
+	Name     string `form:"name" json:"name"`
+	Type     string `form:"type" json:"type"`
+	Options  string `form:"options" json:"options"`
+	Required bool   `form:"required" json:"required"`
+}
+
+type ItemAttributeValue struct {
+	ItemID      int64  `db:"item_id" json:"item_id"`
+	AttributeID int64  `db:"attribute_id" json:"attribute_id"`
+	Value       string `db:"value" json:"value"`
+}
+
+// VariantParams are the options per enum attribute ID, a variant is generated for every combination of them.
+type VariantParams struct {
+	Options map[int64][]string
 }
 
+// Country is a country of ISO 3166-1, see seed.sql. Postal codes of the country have to match PostalCodePattern unless
+// it is empty.
+type Country struct {
+	Code              string `db:"code" json:"code"`
+	Name              string `db:"name" json:"name"`
+	PostalCodePattern string `db:"postal_code_pattern" json:"postal_code_pattern"`
+}
+
+type CountryFilter struct{}
+
 type Address struct {
 	ID        int64   `db:"id" json:"id"`
 	ZIP       string  `db:"zip" json:"zip"`
@@ -45,38 +304,923 @@ type Address struct {
 	Longitude float64 `db:"longitude" json:"longitude"`
 }
 
+// AddressParams accept the country as ISO 3166 alpha-2 code or by its name. An address equal to an existing one is only
+// created with AllowDuplicate, see DuplicateAddressError.
 type AddressParams struct {
-	ZIP       string  `form:"zip" json:"zip"`
-	City      string  `form:"city" json:"city"`
-	Street    string  `form:"street" json:"street"`
-	Country   string  `form:"country" json:"country"`
-	Latitude  float64 `form:"latitude" json:"latitude"`
-	Longitude float64 `form:"longitude" json:"longitude"`
+	ZIP            string  `form:"zip" json:"zip"`
+	City           string  `form:"city" json:"city"`
+	Street         string  `form:"street" json:"street"`
+	Country        string  `form:"country" json:"country"`
+	Latitude       float64 `form:"latitude" json:"latitude"`
+	Longitude      float64 `form:"longitude" json:"longitude"`
+	AllowDuplicate bool    `form:"allow_duplicate" json:"allow_duplicate"`
+}
+
+// DuplicateAddressError is returned when an address to be created already exists, Existing can be reused instead.
+type DuplicateAddressError struct {
+	Existing Address
+}
+
+func (err DuplicateAddressError) Error() string {
+	return fmt.Sprintf("%v: the address already exists as address %v", xerrors.ErrBadRequest, err.Existing.ID)
 }
 
+func (err DuplicateAddressError) Unwrap() error {
+	return xerrors.ErrBadRequest
+}
+
+// AddressFilter matches the text fields case-insensitively anywhere in the address, see database.ContainsPattern.
 type AddressFilter struct {
-	zip       sql.NullString
-	city      sql.NullString
-	street    sql.NullString
-	country   sql.NullString
-	longitude sql.NullFloat64
-	latitude  sql.NullFloat64
+	zip     sql.NullString
+	city    sql.NullString
+	street  sql.NullString
+	country sql.NullString
+	box     boundingBoxFilter
+}
+
+// boundingBoxFilter limits filtered addresses to a geographic box, each bound is optional. A minimum longitude greater
+// than the maximum longitude selects a box crossing the antimeridian. Addresses without coordinates are excluded as
+// soon as a bound is set.
+type boundingBoxFilter struct {
+	minLatitude  sql.NullFloat64
+	maxLatitude  sql.NullFloat64
+	minLongitude sql.NullFloat64
+	maxLongitude sql.NullFloat64
+}
+
+// Coordinates are a position in decimal degrees.
+type Coordinates struct {
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+}
+
+// GeocodeQuery is the part of an address a Geocoder locates, Country is an ISO 3166 alpha-2 code.
+type GeocodeQuery struct {
+	Country string
+	ZIP     string
+	City    string
+}
+
+// PostalCode is a place of a postal code dump, see parsePostalCodes.
+type PostalCode struct {
+	Country   string  `db:"country" json:"country"`
+	ZIP       string  `db:"zip" json:"zip"`
+	Place     string  `db:"place" json:"place"`
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+}
+
+// PostalCodeCountry is the number of imported postal code places of a country.
+type PostalCodeCountry struct {
+	Country string `db:"country" json:"country"`
+	Places  int64  `db:"places" json:"places"`
+}
+
+// PlantLocation is a plant with the coordinates of its address.
+type PlantLocation struct {
+	Plant
+	Street    string  `db:"street" json:"street"`
+	ZIP       string  `db:"zip" json:"zip"`
+	City      string  `db:"city" json:"city"`
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+}
+
+// PlantDistance is a plant with its great-circle distance in kilometers to a position.
+type PlantDistance struct {
+	PlantLocation
+	Distance float64 `json:"distance"`
+}
+
+// BoundingBox covers the positions between its corners in decimal degrees. Boxes crossing the antimeridian have a
+// MinLongitude greater than their MaxLongitude.
+type BoundingBox struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// geoJSONFeatureCollection is a GeoJSON FeatureCollection of points, see RFC 7946.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoJSONPoint   `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// geoJSONPoint lists its coordinates as longitude, latitude.
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
 }
 
 type Plant struct {
-	ID        int64  `db:"id" json:"id"`
-	Name      string `db:"name" json:"name"`
-	AddressID int64  `db:"address_id" json:"address_id"`
+	ID                 int64  `db:"id" json:"id"`
+	Name               string `db:"name" json:"name"`
+	AddressID          int64  `db:"address_id" json:"address_id"`
+	AllowNegativeStock bool   `db:"allow_negative_stock" json:"allow_negative_stock"`
 }
 
 type PlantParams struct {
+	Name               string `form:"name" json:"name"`
+	AddressID          int64  `form:"address_id" json:"address_id"`
+	AllowNegativeStock bool   `form:"allow_negative_stock" json:"allow_negative_stock"`
+}
+
+// PlantFilter matches the name and address case-insensitively anywhere, address is searched in the street, postal code
+// and city of the plant address.
+type PlantFilter struct {
+	name      sql.NullString
+	addressID sql.NullInt64
+	address   sql.NullString
+	box       boundingBoxFilter
+}
+
+type Customer struct {
+	ID        int64         `db:"id" json:"id"`
+	Name      string        `db:"name" json:"name"`
+	AddressID int64         `db:"address_id" json:"address_id"`
+	GroupID   sql.NullInt64 `db:"group_id" json:"group_id"`
+}
+
+// CustomerParams uses a zero group ID for customers without a group.
+type CustomerParams struct {
 	Name      string `form:"name" json:"name"`
 	AddressID int64  `form:"address_id" json:"address_id"`
+	GroupID   int64  `form:"group_id" json:"group_id"`
 }
 
-type PlantFilter struct {
+type CustomerFilter struct {
 	name      sql.NullString
 	addressID sql.NullInt64
+	groupID   sql.NullInt64
+}
+
+// TaxCategory carries the tax rate of its items in percent.
+type TaxCategory struct {
+	ID   int64   `db:"id" json:"id"`
+	Name string  `db:"name" json:"name"`
+	Rate float64 `db:"rate" json:"rate"`
+}
+
+type TaxCategoryParams struct {
+	Name string  `form:"name" json:"name"`
+	Rate float64 `form:"rate" json:"rate"`
+}
+
+type TaxCategoryFilter struct{}
+
+// CurrencyRounding rounds gross prices in a currency to a multiple of Increment minor units.
+type CurrencyRounding struct {
+	Currency  string `db:"currency" json:"currency"`
+	Increment int64  `db:"increment" json:"increment"`
+}
+
+type CurrencyRoundingParams struct {
+	Currency  string `form:"currency" json:"currency"`
+	Increment int64  `form:"increment" json:"increment"`
+}
+
+type CurrencyRoundingFilter struct{}
+
+// itemPriceBasis is what the prices of an item are derived from, see itemPrices.
+type itemPriceBasis struct {
+	ID         int64   `db:"id"`
+	PriceEntry string  `db:"price_entry"`
+	NetPrice   int64   `db:"net_price"`
+	GrossPrice int64   `db:"gross_price"`
+	Rate       float64 `db:"rate"`
+	Increment  int64   `db:"increment"`
+}
+
+type CustomerGroup struct {
+	ID   int64  `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+}
+
+type CustomerGroupParams struct {
+	Name string `form:"name" json:"name"`
+}
+
+type CustomerGroupFilter struct{}
+
+// PriceList holds net prices per base unit in a currency. A list applies to one customer, to the customers of one group
+// or, if it has neither, to all customers. Empty validity dates are open ended.
+type PriceList struct {
+	ID              int64         `db:"id" json:"id"`
+	Name            string        `db:"name" json:"name"`
+	Currency        string        `db:"currency" json:"currency"`
+	ValidFrom       string        `db:"valid_from" json:"valid_from"`
+	ValidTo         string        `db:"valid_to" json:"valid_to"`
+	CustomerGroupID sql.NullInt64 `db:"customer_group_id" json:"customer_group_id"`
+	CustomerID      sql.NullInt64 `db:"customer_id" json:"customer_id"`
+}
+
+// PriceListParams use zero IDs for lists that do not apply to a customer group or customer.
+type PriceListParams struct {
+	Name            string `form:"name" json:"name"`
+	Currency        string `form:"currency" json:"currency"`
+	ValidFrom       string `form:"valid_from" json:"valid_from"`
+	ValidTo         string `form:"valid_to" json:"valid_to"`
+	CustomerGroupID int64  `form:"customer_group_id" json:"customer_group_id"`
+	CustomerID      int64  `form:"customer_id" json:"customer_id"`
+}
+
+type PriceListFilter struct {
+	customerGroupID sql.NullInt64
+	customerID      sql.NullInt64
+}
+
+// Price is the price of an item per base unit in a price list from MinQuantity base units on.
+type Price struct {
+	ID          int64   `db:"id" json:"id"`
+	PriceListID int64   `db:"price_list_id" json:"price_list_id"`
+	ItemID      int64   `db:"item_id" json:"item_id"`
+	MinQuantity float64 `db:"min_quantity" json:"min_quantity"`
+	Price       int64   `db:"price" json:"price"`
+	ItemName    string  `db:"item_name" json:"item_name"`
+	ItemSKU     string  `db:"item_sku" json:"item_sku"`
+}
+
+type PriceParams struct {
+	ItemID      int64   `form:"item_id" json:"item_id"`
+	MinQuantity float64 `form:"min_quantity" json:"min_quantity"`
+	Price       int64   `form:"price" json:"price"`
+}
+
+// Price sources, from the most to the least specific.
+const (
+	PriceSourceCustomer      = "customer"
+	PriceSourceCustomerGroup = "customer_group"
+	PriceSourceGeneral       = "general"
+	PriceSourceItem          = "item"
+)
+
+// EffectivePrice is the result of a price determination. Prices from price lists carry the list, the item price is used
+// if no list has a price.
+type EffectivePrice struct {
+	ItemID      int64
+	Quantity    float64
+	Price       int64
+	Source      string
+	PriceListID sql.NullInt64
+	Currency    string
+	MinQuantity float64
+}
+
+// PriceQuery asks for the price of Quantity base units of an item for a customer on a date, a zero customer ID asks
+// for the general price. Only lists in Currency apply, an empty currency asks for the currency of the item.
+type PriceQuery struct {
+	ItemID     int64
+	Quantity   float64
+	CustomerID int64
+	Date       string
+	Currency   string
+}
+
+// priceCandidate is a scale price of a list that applies to a price query.
+type priceCandidate struct {
+	PriceListID     int64         `db:"price_list_id"`
+	Currency        string        `db:"currency"`
+	ValidFrom       string        `db:"valid_from"`
+	CustomerGroupID sql.NullInt64 `db:"customer_group_id"`
+	CustomerID      sql.NullInt64 `db:"customer_id"`
+	MinQuantity     float64       `db:"min_quantity"`
+	Price           int64         `db:"price"`
+}
+
+type GoodsMovement struct {
+	ID          int64         `db:"id" json:"id"`
+	Type        string        `db:"type" json:"type"`
+	Date        string        `db:"date" json:"date"`
+	ItemID      int64         `db:"item_id" json:"item_id"`
+	FromPlantID sql.NullInt64 `db:"from_plant_id" json:"from_plant_id"`
+	ToPlantID   sql.NullInt64 `db:"to_plant_id" json:"to_plant_id"`
+	FromBinID   sql.NullInt64 `db:"from_bin_id" json:"from_bin_id"`
+	ToBinID     sql.NullInt64 `db:"to_bin_id" json:"to_bin_id"`
+	// PurchaseOrderLineID is set for receipts against a purchase order.
+	PurchaseOrderLineID sql.NullInt64 `db:"purchase_order_line_id" json:"purchase_order_line_id"`
+	// DeliveryLineID is set for issues of shipped deliveries.
+	DeliveryLineID sql.NullInt64 `db:"delivery_line_id" json:"delivery_line_id"`
+	BatchID        sql.NullInt64 `db:"batch_id" json:"batch_id"`
+	Quantity       float64       `db:"quantity" json:"quantity"`
+	Reference      string        `db:"reference" json:"reference"`
+	Note           string        `db:"note" json:"note"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	// SerialNumbers are only read for single movements.
+	SerialNumbers []string `db:"serial_numbers" json:"serial_numbers,omitempty"`
+}
+
+// GoodsMovementParams uses zero plant and bin IDs for plants and bins that do not apply to the movement. Quantities of
+// goods movements are stored in the base unit of the item.
+type GoodsMovementParams struct {
+	Type        string  `form:"type" json:"type"`
+	Date        string  `form:"date" json:"date"`
+	ItemID      int64   `form:"item_id" json:"item_id"`
+	FromPlantID int64   `form:"from_plant_id" json:"from_plant_id"`
+	ToPlantID   int64   `form:"to_plant_id" json:"to_plant_id"`
+	FromBinID   int64   `form:"from_bin_id" json:"from_bin_id"`
+	ToBinID     int64   `form:"to_bin_id" json:"to_bin_id"`
+	Quantity    float64 `form:"quantity" json:"quantity"`
+	// UnitID is the unit Quantity is entered in, zero for the base unit of the item.
+	UnitID    int64  `form:"unit_id" json:"unit_id"`
+	Reference string `form:"reference" json:"reference"`
+	Note      string `form:"note" json:"note"`
+	// PurchaseOrderLineID is set by goods receipts against purchase orders and can not be entered.
+	PurchaseOrderLineID int64 `form:"-" json:"-"`
+	// DeliveryLineID is set when deliveries are shipped and can not be entered.
+	DeliveryLineID int64 `form:"-" json:"-"`
+	// BatchNumber is required for batch managed items. Batches are created by the first movement into a plant, the
+	// dates are only used then.
+	BatchNumber     string `form:"batch_number" json:"batch_number"`
+	ManufactureDate string `form:"manufacture_date" json:"manufacture_date"`
+	ExpiryDate      string `form:"expiry_date" json:"expiry_date"`
+	// BatchID can be set instead of BatchNumber by movements of existing batches.
+	BatchID int64 `form:"-" json:"-"`
+	// SerialNumbers are required for serialized items, one per unit of the quantity, separated by new lines or commas.
+	SerialNumbers string `form:"serial_numbers" json:"serial_numbers"`
+}
+
+type GoodsMovementFilter struct {
+	movementType sql.NullString
+	itemID       sql.NullInt64
+	plantID      sql.NullInt64
+}
+
+type Batch struct {
+	ID              int64  `db:"id" json:"id"`
+	ItemID          int64  `db:"item_id" json:"item_id"`
+	Number          string `db:"number" json:"number"`
+	ManufactureDate string `db:"manufacture_date" json:"manufacture_date"`
+	ExpiryDate      string `db:"expiry_date" json:"expiry_date"`
+}
+
+// BatchParams only change the dates, batches are created by goods movements.
+type BatchParams struct {
+	ManufactureDate string `form:"manufacture_date" json:"manufacture_date"`
+	ExpiryDate      string `form:"expiry_date" json:"expiry_date"`
+}
+
+type BatchFilter struct {
+	itemID sql.NullInt64
+	number sql.NullString
+}
+
+// BatchStock is the stock of a batch in a plant.
+type BatchStock struct {
+	Batch
+	PlantID  int64   `db:"plant_id" json:"plant_id"`
+	Quantity float64 `db:"quantity" json:"quantity"`
+}
+
+// SerialNumber is a serial number of a serialized item, PlantID and BinID are where it is in stock.
+type SerialNumber struct {
+	ID      int64         `db:"id" json:"id"`
+	ItemID  int64         `db:"item_id" json:"item_id"`
+	Number  string        `db:"number" json:"number"`
+	PlantID sql.NullInt64 `db:"plant_id" json:"plant_id"`
+	BinID   sql.NullInt64 `db:"bin_id" json:"bin_id"`
+}
+
+type SerialNumberFilter struct {
+	itemID  sql.NullInt64
+	number  sql.NullString
+	plantID sql.NullInt64
+}
+
+// TraceMovement is a goods movement of a batch or serial number together with the purchase order or delivery it was
+// posted for.
+type TraceMovement struct {
+	GoodsMovement
+	PurchaseOrderID sql.NullInt64  `db:"purchase_order_id" json:"purchase_order_id"`
+	SupplierName    sql.NullString `db:"supplier_name" json:"supplier_name"`
+	DeliveryID      sql.NullInt64  `db:"delivery_id" json:"delivery_id"`
+	CustomerName    sql.NullString `db:"customer_name" json:"customer_name"`
+}
+
+// Stock is the quantity of an item in a plant and bin, derived from all goods movements. Stock without a bin has not
+// been put away yet.
+type Stock struct {
+	ItemID              int64          `db:"item_id" json:"item_id"`
+	ItemName            string         `db:"item_name" json:"item_name"`
+	ItemSKU             string         `db:"item_sku" json:"item_sku"`
+	PlantID             int64          `db:"plant_id" json:"plant_id"`
+	PlantName           string         `db:"plant_name" json:"plant_name"`
+	BinID               sql.NullInt64  `db:"bin_id" json:"bin_id"`
+	BinName             sql.NullString `db:"bin_name" json:"bin_name"`
+	StorageLocationName sql.NullString `db:"storage_location_name" json:"storage_location_name"`
+	BatchID             sql.NullInt64  `db:"batch_id" json:"batch_id"`
+	BatchNumber         sql.NullString `db:"batch_number" json:"batch_number"`
+	ExpiryDate          sql.NullString `db:"expiry_date" json:"expiry_date"`
+	Quantity            float64        `db:"quantity" json:"quantity"`
+	// UnitCode is the code of the base unit of the item.
+	UnitCode string `db:"unit_code" json:"unit_code"`
+}
+
+type StockFilter struct {
+	itemID  sql.NullInt64
+	plantID sql.NullInt64
+	binID   sql.NullInt64
+}
+
+type StorageLocation struct {
+	ID      int64  `db:"id" json:"id"`
+	PlantID int64  `db:"plant_id" json:"plant_id"`
+	Name    string `db:"name" json:"name"`
+}
+
+type StorageLocationParams struct {
+	PlantID int64  `form:"plant_id" json:"plant_id"`
+	Name    string `form:"name" json:"name"`
+}
+
+type StorageLocationFilter struct {
+	plantID sql.NullInt64
+}
+
+// Bin is a storage bin inside of a storage location. PlantID, StorageLocationName and CapacityUnitCode are read from
+// the storage location and the capacity unit and only set when querying bins, not when creating or updating them.
+type Bin struct {
+	ID                  int64         `db:"id" json:"id"`
+	StorageLocationID   int64         `db:"storage_location_id" json:"storage_location_id"`
+	Name                string        `db:"name" json:"name"`
+	Capacity            float64       `db:"capacity" json:"capacity"`
+	CapacityUnitID      sql.NullInt64 `db:"capacity_unit_id" json:"capacity_unit_id"`
+	Blocked             bool          `db:"blocked" json:"blocked"`
+	PlantID             int64         `db:"plant_id" json:"plant_id"`
+	StorageLocationName string        `db:"storage_location_name" json:"storage_location_name"`
+	CapacityUnitCode    string        `db:"capacity_unit_code" json:"capacity_unit_code"`
+}
+
+// BinParams uses a capacity of zero for bins that are not limited, the capacity of limited bins is given in the
+// capacity unit.
+type BinParams struct {
+	StorageLocationID int64   `form:"storage_location_id" json:"storage_location_id"`
+	Name              string  `form:"name" json:"name"`
+	Capacity          float64 `form:"capacity" json:"capacity"`
+	CapacityUnitID    int64   `form:"capacity_unit_id" json:"capacity_unit_id"`
+	Blocked           bool    `form:"blocked" json:"blocked"`
+}
+
+// BinLoad is the stock of a bin in its capacity unit. Unconvertible names the items in the bin whose base unit can not
+// be converted to the capacity unit, their stock is not included in Quantity.
+type BinLoad struct {
+	Quantity      float64  `db:"quantity"`
+	Unconvertible []string `db:"unconvertible"`
+}
+
+type BinFilter struct {
+	plantID           sql.NullInt64
+	storageLocationID sql.NullInt64
+}
+
+type Supplier struct {
+	ID        int64  `db:"id" json:"id"`
+	Name      string `db:"name" json:"name"`
+	AddressID int64  `db:"address_id" json:"address_id"`
+}
+
+type SupplierParams struct {
+	Name      string `form:"name" json:"name"`
+	AddressID int64  `form:"address_id" json:"address_id"`
+}
+
+type SupplierFilter struct {
+	name sql.NullString
+}
+
+type PurchaseOrder struct {
+	PurchaseOrderHeader
+	Lines []PurchaseOrderLine `json:"lines"`
+}
+
+// Should only be embedded
+type PurchaseOrderHeader struct {
+	ID           int64  `db:"id" json:"id"`
+	SupplierID   int64  `db:"supplier_id" json:"supplier_id"`
+	PlantID      int64  `db:"plant_id" json:"plant_id"`
+	Date         string `db:"date" json:"date"`
+	DeliveryDate string `db:"delivery_date" json:"delivery_date"`
+	Currency     string `db:"currency" json:"currency"`
+	Status       string `db:"status" json:"status"`
+}
+
+// PurchaseOrderLine has its quantity and price in the unit of the line. BaseQuantity and ReceivedQuantity are in the
+// base unit of the item.
+type PurchaseOrderLine struct {
+	ID               int64   `db:"id" json:"id"`
+	PurchaseOrderID  int64   `db:"purchase_order_id" json:"purchase_order_id"`
+	ItemID           int64   `db:"item_id" json:"item_id"`
+	Quantity         float64 `db:"quantity" json:"quantity"`
+	UnitID           int64   `db:"unit_id" json:"unit_id"`
+	BaseQuantity     float64 `db:"base_quantity" json:"base_quantity"`
+	Price            int64   `db:"price" json:"price"`
+	ReceivedQuantity float64 `db:"received_quantity" json:"received_quantity"`
+}
+
+type PurchaseOrderParams struct {
+	PurchaseOrderHeaderParams
+	Lines []OrderLineParams
+}
+
+type PurchaseOrderHeaderParams struct {
+	SupplierID   int64
+	PlantID      int64
+	Date         string
+	DeliveryDate string
+	Currency     string
+}
+
+// OrderLineParams describe a line of a purchase or sales order, a unit ID of zero stands for the base unit of the item
+// and an empty price defaults to the net price of the item, on sales orders to the price determined for the customer.
+type OrderLineParams struct {
+	ItemID   int64
+	Quantity float64
+	UnitID   int64
+	Price    sql.NullInt64
+}
+
+// orderLineValues are the resolved values of an order line, see OrderLineParams.
+type orderLineValues struct {
+	ItemID       int64
+	Quantity     float64
+	UnitID       int64
+	BaseQuantity float64
+	Price        int64
+}
+
+type PurchaseOrderFilter struct {
+	supplierID sql.NullInt64
+	status     sql.NullString
+}
+
+// GoodsReceiptParams receive goods against the lines of a purchase order, quantities are in the base unit of the items.
+type GoodsReceiptParams struct {
+	Date      string
+	Reference string
+	Lines     []GoodsReceiptLineParams
+}
+
+// GoodsReceiptLineParams capture the batch of batch managed and the serial numbers of serialized items, see
+// GoodsMovementParams.
+type GoodsReceiptLineParams struct {
+	LineID        int64
+	Quantity      float64
+	BinID         int64
+	BatchNumber   string
+	ExpiryDate    string
+	SerialNumbers string
+}
+
+// PlanningParameters control the planning runs of an item in a plant, quantities are in the base unit of the item.
+type PlanningParameters struct {
+	ItemID       int64         `db:"item_id" json:"item_id"`
+	PlantID      int64         `db:"plant_id" json:"plant_id"`
+	ReorderPoint float64       `db:"reorder_point" json:"reorder_point"`
+	SafetyStock  float64       `db:"safety_stock" json:"safety_stock"`
+	LotSize      float64       `db:"lot_size" json:"lot_size"`
+	LeadTimeDays int           `db:"lead_time_days" json:"lead_time_days"`
+	SupplierID   sql.NullInt64 `db:"supplier_id" json:"supplier_id"`
+}
+
+// PlanningParametersParams use a supplier ID of zero if no supplier is proposed.
+type PlanningParametersParams struct {
+	PlantID      int64   `form:"plant_id" json:"plant_id"`
+	ReorderPoint float64 `form:"reorder_point" json:"reorder_point"`
+	SafetyStock  float64 `form:"safety_stock" json:"safety_stock"`
+	LotSize      float64 `form:"lot_size" json:"lot_size"`
+	LeadTimeDays int     `form:"lead_time_days" json:"lead_time_days"`
+	SupplierID   int64   `form:"supplier_id" json:"supplier_id"`
+}
+
+type PlanningParametersFilter struct {
+	itemID  sql.NullInt64
+	plantID sql.NullInt64
+}
+
+// Projection is the projected stock of an item in a plant: the stock on hand plus the quantity still to be received
+// from purchase orders minus the quantity still to be shipped for sales orders.
+type Projection struct {
+	PlanningParameters
+	ItemName   string  `db:"item_name" json:"item_name"`
+	PlantName  string  `db:"plant_name" json:"plant_name"`
+	UnitCode   string  `db:"unit_code" json:"unit_code"`
+	Decimals   int     `db:"decimals" json:"decimals"`
+	OnHand     float64 `db:"on_hand" json:"on_hand"`
+	OpenSupply float64 `db:"open_supply" json:"open_supply"`
+	OpenDemand float64 `db:"open_demand" json:"open_demand"`
+}
+
+type PurchaseProposal struct {
+	ID                int64         `db:"id" json:"id"`
+	ItemID            int64         `db:"item_id" json:"item_id"`
+	PlantID           int64         `db:"plant_id" json:"plant_id"`
+	SupplierID        sql.NullInt64 `db:"supplier_id" json:"supplier_id"`
+	Quantity          float64       `db:"quantity" json:"quantity"`
+	DeliveryDate      string        `db:"delivery_date" json:"delivery_date"`
+	ProjectedQuantity float64       `db:"projected_quantity" json:"projected_quantity"`
+	BelowSafetyStock  bool          `db:"below_safety_stock" json:"below_safety_stock"`
+	Status            string        `db:"status" json:"status"`
+	PurchaseOrderID   sql.NullInt64 `db:"purchase_order_id" json:"purchase_order_id"`
+	CreatedAt         time.Time     `db:"created_at" json:"created_at"`
+}
+
+// PurchaseProposalParams change an open proposal, the quantity is in the base unit of the item.
+type PurchaseProposalParams struct {
+	SupplierID   int64   `form:"supplier_id" json:"supplier_id"`
+	Quantity     float64 `form:"quantity" json:"quantity"`
+	DeliveryDate string  `form:"delivery_date" json:"delivery_date"`
+}
+
+type PurchaseProposalFilter struct {
+	itemID  sql.NullInt64
+	plantID sql.NullInt64
+	status  sql.NullString
+}
+
+type InventoryCount struct {
+	InventoryCountHeader
+	Lines []InventoryCountLine `json:"lines"`
+}
+
+// Should only be embedded
+type InventoryCountHeader struct {
+	ID         int64        `db:"id" json:"id"`
+	PlantID    int64        `db:"plant_id" json:"plant_id"`
+	Date       string       `db:"date" json:"date"`
+	Note       string       `db:"note" json:"note"`
+	Status     string       `db:"status" json:"status"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	ApprovedAt sql.NullTime `db:"approved_at" json:"approved_at"`
+}
+
+// InventoryCountLine is a stock position of a counted item, quantities are in the base unit of the item. The counted
+// quantity is empty until it has been entered.
+type InventoryCountLine struct {
+	ID               int64           `db:"id" json:"id"`
+	InventoryCountID int64           `db:"inventory_count_id" json:"inventory_count_id"`
+	ItemID           int64           `db:"item_id" json:"item_id"`
+	BinID            sql.NullInt64   `db:"bin_id" json:"bin_id"`
+	BatchID          sql.NullInt64   `db:"batch_id" json:"batch_id"`
+	BookQuantity     float64         `db:"book_quantity" json:"book_quantity"`
+	CountedQuantity  sql.NullFloat64 `db:"counted_quantity" json:"counted_quantity"`
+	MovementID       sql.NullInt64   `db:"movement_id" json:"movement_id"`
+	ItemName         string          `db:"item_name" json:"item_name"`
+	ItemSKU          string          `db:"item_sku" json:"item_sku"`
+	Tracking         string          `db:"tracking" json:"tracking"`
+	UnitCode         string          `db:"unit_code" json:"unit_code"`
+	Decimals         int             `db:"decimals" json:"decimals"`
+	BinName          sql.NullString  `db:"bin_name" json:"bin_name"`
+	BatchNumber      sql.NullString  `db:"batch_number" json:"batch_number"`
+}
+
+type InventoryCountParams struct {
+	PlantID int64
+	Date    string
+	Note    string
+	ItemIDs []int64
+}
+
+// CountedQuantities are counted quantities in the base unit of the items by line ID.
+type CountedQuantities map[int64]float64
+
+type InventoryCountFilter struct {
+	plantID sql.NullInt64
+	status  sql.NullString
+}
+
+type SalesOrder struct {
+	SalesOrderHeader
+	Lines []SalesOrderLine `json:"lines"`
+}
+
+// Should only be embedded
+type SalesOrderHeader struct {
+	ID                    int64  `db:"id" json:"id"`
+	CustomerID            int64  `db:"customer_id" json:"customer_id"`
+	PlantID               int64  `db:"plant_id" json:"plant_id"`
+	ShippingAddressID     int64  `db:"shipping_address_id" json:"shipping_address_id"`
+	Date                  string `db:"date" json:"date"`
+	RequestedDeliveryDate string `db:"requested_delivery_date" json:"requested_delivery_date"`
+	Currency              string `db:"currency" json:"currency"`
+	Status                string `db:"status" json:"status"`
+}
+
+// SalesOrderLine has its quantity and price in the unit of the line. BaseQuantity, DeliveredQuantity and
+// ShippedQuantity are in the base unit of the item, cancelled deliveries do not count as delivered.
+type SalesOrderLine struct {
+	ID                int64   `db:"id" json:"id"`
+	SalesOrderID      int64   `db:"sales_order_id" json:"sales_order_id"`
+	ItemID            int64   `db:"item_id" json:"item_id"`
+	Quantity          float64 `db:"quantity" json:"quantity"`
+	UnitID            int64   `db:"unit_id" json:"unit_id"`
+	BaseQuantity      float64 `db:"base_quantity" json:"base_quantity"`
+	Price             int64   `db:"price" json:"price"`
+	DeliveredQuantity float64 `db:"delivered_quantity" json:"delivered_quantity"`
+	ShippedQuantity   float64 `db:"shipped_quantity" json:"shipped_quantity"`
+}
+
+type SalesOrderParams struct {
+	SalesOrderHeaderParams
+	Lines []OrderLineParams
+}
+
+// SalesOrderHeaderParams uses a shipping address ID of zero for the address of the customer.
+type SalesOrderHeaderParams struct {
+	CustomerID            int64
+	PlantID               int64
+	ShippingAddressID     int64
+	Date                  string
+	RequestedDeliveryDate string
+	Currency              string
+}
+
+type SalesOrderFilter struct {
+	customerID sql.NullInt64
+	status     sql.NullString
+}
+
+type Delivery struct {
+	DeliveryHeader
+	Lines []DeliveryLine `json:"lines"`
+}
+
+// Should only be embedded
+type DeliveryHeader struct {
+	ID           int64  `db:"id" json:"id"`
+	SalesOrderID int64  `db:"sales_order_id" json:"sales_order_id"`
+	Date         string `db:"date" json:"date"`
+	Status       string `db:"status" json:"status"`
+	// PlantID is read from the sales order.
+	PlantID int64 `db:"plant_id" json:"plant_id"`
+}
+
+// DeliveryLine has its quantity in the base unit of the item. ItemID is read from the sales order line, the batch
+// number and the serial numbers from their tables.
+type DeliveryLine struct {
+	ID               int64          `db:"id" json:"id"`
+	DeliveryID       int64          `db:"delivery_id" json:"delivery_id"`
+	SalesOrderLineID int64          `db:"sales_order_line_id" json:"sales_order_line_id"`
+	Quantity         float64        `db:"quantity" json:"quantity"`
+	BinID            sql.NullInt64  `db:"bin_id" json:"bin_id"`
+	BatchID          sql.NullInt64  `db:"batch_id" json:"batch_id"`
+	ItemID           int64          `db:"item_id" json:"item_id"`
+	BatchNumber      sql.NullString `db:"batch_number" json:"batch_number"`
+	SerialNumbers    []string       `db:"serial_numbers" json:"serial_numbers"`
+}
+
+// DeliveryParams create a delivery for the lines of a sales order, quantities are in the base unit of the items.
+type DeliveryParams struct {
+	Date  string
+	Lines []DeliveryLineParams
+}
+
+// DeliveryLineParams use a bin ID of zero if the goods are not picked from a bin. The batch is required for batch
+// managed and the serial numbers for serialized items, see GoodsMovementParams.
+type DeliveryLineParams struct {
+	SalesOrderLineID int64
+	Quantity         float64
+	BinID            int64
+	BatchID          int64
+	SerialNumbers    string
+}
+
+// deliveryLineValues are the validated values of a delivery line.
+type deliveryLineValues struct {
+	SalesOrderLineID int64
+	Quantity         float64
+	BinID            sql.NullInt64
+	BatchID          sql.NullInt64
+	SerialNumberIDs  []int64
+}
+
+type DeliveryFilter struct {
+	salesOrderID sql.NullInt64
+	plantID      sql.NullInt64
+	status       sql.NullString
+}
+
+// PickingListLine is a line of an open delivery together with what is needed to pick it.
+type PickingListLine struct {
+	DeliveryID          int64          `db:"delivery_id"`
+	Date                string         `db:"date"`
+	ItemName            string         `db:"item_name"`
+	SKU                 string         `db:"sku"`
+	Quantity            float64        `db:"quantity"`
+	UnitCode            string         `db:"unit_code"`
+	StorageLocationName sql.NullString `db:"storage_location_name"`
+	BinName             sql.NullString `db:"bin_name"`
+	BatchNumber         sql.NullString `db:"batch_number"`
+	SerialNumbers       []string       `db:"serial_numbers"`
+}
+
+// ItemLabelParams select the items of a label sheet and how their barcodes are printed, every item is printed Copies
+// times.
+type ItemLabelParams struct {
+	ItemIDs []int64 `form:"item_id"`
+	Barcode string  `form:"barcode"`
+	Content string  `form:"content"`
+	Copies  int     `form:"copies"`
+}
+
+// ItemLabel is an item with its encoded barcode. Linear barcodes are in Bars, QR codes in Matrix, Text is the human
+// readable content printed with the barcode.
+type ItemLabel struct {
+	Item
+	Bars   []bool
+	Matrix [][]bool
+	Text   string
+}
+
+type Shipment struct {
+	ShipmentHeader
+	Packages []ShipmentPackage `json:"packages"`
+}
+
+// Should only be embedded
+type ShipmentHeader struct {
+	ID         int64  `db:"id" json:"id"`
+	DeliveryID int64  `db:"delivery_id" json:"delivery_id"`
+	Carrier    string `db:"carrier" json:"carrier"`
+	Service    string `db:"service" json:"service"`
+	// TrackingNumber is empty until the label has been created.
+	TrackingNumber string    `db:"tracking_number" json:"tracking_number"`
+	Status         string    `db:"status" json:"status"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// ShipmentPackage has its dimensions in centimeters and its weight in kilograms.
+type ShipmentPackage struct {
+	ID             int64   `db:"id" json:"id"`
+	ShipmentID     int64   `db:"shipment_id" json:"shipment_id"`
+	Length         float64 `db:"length" json:"length"`
+	Width          float64 `db:"width" json:"width"`
+	Height         float64 `db:"height" json:"height"`
+	Weight         float64 `db:"weight" json:"weight"`
+	TrackingNumber string  `db:"tracking_number" json:"tracking_number"`
+}
+
+// ShipmentParams select the carrier and one of its services by their codes.
+type ShipmentParams struct {
+	Carrier string `form:"carrier" json:"carrier"`
+	Service string `form:"service" json:"service"`
+}
+
+type ShipmentPackageParams struct {
+	Length float64 `form:"length" json:"length"`
+	Width  float64 `form:"width" json:"width"`
+	Height float64 `form:"height" json:"height"`
+	Weight float64 `form:"weight" json:"weight"`
+}
+
+type ShipmentFilter struct {
+	deliveryID sql.NullInt64
+	carrier    sql.NullString
+	status     sql.NullString
+}
+
+// CarrierService is a service level offered by a carrier, e.g. standard or express delivery.
+type CarrierService struct {
+	Code string
+	Name string
+}
+
+// LabelAddress is an address together with the name of the sender or recipient.
+type LabelAddress struct {
+	Name string
+	Address
+	CountryName string
+}
+
+// LabelRequest is sent to a carrier to register a shipment, see Carrier.
+type LabelRequest struct {
+	Reference string
+	Service   string
+	From      LabelAddress
+	To        LabelAddress
+	Packages  []ShipmentPackage
+}
+
+// CarrierLabel holds the tracking numbers a carrier assigned to a shipment and to every package of the LabelRequest, in
+// the order of the packages.
+type CarrierLabel struct {
+	TrackingNumber         string
+	PackageTrackingNumbers []string
+}
+
+// ShippingLabel is everything printed on the labels of a shipment, one label per package.
+type ShippingLabel struct {
+	Shipment
+	CarrierName       string
+	ServiceName       string
+	DeliveryReference string
+	From              LabelAddress
+	To                LabelAddress
 }
 
 func (item Item) GetID() string {
@@ -87,6 +1231,22 @@ func (item Item) Redirect() string {
 	return "/logistics/items/" + item.GetID()
 }
 
+func (item Item) Variant() bool {
+	return item.ParentID.Valid
+}
+
+// optionList returns the trimmed options without empty and duplicate entries.
+func (params ItemAttributeParams) optionList() []string {
+	var options []string
+	for _, option := range strings.Split(params.Options, ",") {
+		option = strings.TrimSpace(option)
+		if option != "" && !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
 func (itemCategory ItemCategory) GetID() string {
 	return strconv.FormatInt(itemCategory.ID, 10)
 }
@@ -95,6 +1255,11 @@ func (itemCategory ItemCategory) Redirect() string {
 	return "/logistics/itemcategories/" + itemCategory.GetID()
 }
 
+// Indent returns the category name indented by its depth, used to show the tree in select boxes.
+func (itemCategory ItemCategory) Indent() string {
+	return strings.Repeat("\u00a0\u00a0", itemCategory.Depth) + itemCategory.Name
+}
+
 func (address Address) GetID() string {
 	return strconv.FormatInt(address.ID, 10)
 }
@@ -110,3 +1275,413 @@ func (plant Plant) GetID() string {
 func (plant Plant) Redirect() string {
 	return "/logistics/plants/" + plant.GetID()
 }
+
+func (customer Customer) GetID() string {
+	return strconv.FormatInt(customer.ID, 10)
+}
+
+func (customer Customer) Redirect() string {
+	return "/logistics/customers/" + customer.GetID()
+}
+
+func (category TaxCategory) GetID() string {
+	return strconv.FormatInt(category.ID, 10)
+}
+
+func (category TaxCategory) Redirect() string {
+	return "/logistics/tax-categories/" + category.GetID()
+}
+
+func (rounding CurrencyRounding) GetID() string {
+	return rounding.Currency
+}
+
+func (rounding CurrencyRounding) Redirect() string {
+	return "/logistics/currency-roundings/"
+}
+
+func (group CustomerGroup) GetID() string {
+	return strconv.FormatInt(group.ID, 10)
+}
+
+func (group CustomerGroup) Redirect() string {
+	return "/logistics/customer-groups/" + group.GetID()
+}
+
+func (list PriceList) GetID() string {
+	return strconv.FormatInt(list.ID, 10)
+}
+
+func (list PriceList) Redirect() string {
+	return "/logistics/price-lists/" + list.GetID()
+}
+
+// Source returns the price source of the prices of the list.
+func (list PriceList) Source() string {
+	switch {
+	case list.CustomerID.Valid:
+		return PriceSourceCustomer
+	case list.CustomerGroupID.Valid:
+		return PriceSourceCustomerGroup
+	default:
+		return PriceSourceGeneral
+	}
+}
+
+func (candidate priceCandidate) source() string {
+	return PriceList{CustomerGroupID: candidate.CustomerGroupID, CustomerID: candidate.CustomerID}.Source()
+}
+
+func (movement GoodsMovement) GetID() string {
+	return strconv.FormatInt(movement.ID, 10)
+}
+
+func (movement GoodsMovement) Redirect() string {
+	return "/logistics/movements/" + movement.GetID()
+}
+
+func (params GoodsMovementParams) serialNumbers() []string {
+	return splitSerialNumbers(params.SerialNumbers)
+}
+
+// splitSerialNumbers splits a list of serial numbers separated by new lines or commas and drops empty entries.
+func splitSerialNumbers(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ','
+	})
+
+	var numbers []string
+	for _, field := range fields {
+		if number := strings.TrimSpace(field); number != "" {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+func (stock Stock) GetID() string {
+	return strconv.FormatInt(stock.ItemID, 10) + "-" + strconv.FormatInt(stock.PlantID, 10)
+}
+
+func (stock Stock) Redirect() string {
+	return "/logistics/stock?item_id=" + strconv.FormatInt(stock.ItemID, 10)
+}
+
+func (location StorageLocation) GetID() string {
+	return strconv.FormatInt(location.ID, 10)
+}
+
+func (location StorageLocation) Redirect() string {
+	return "/logistics/storage-locations/" + location.GetID()
+}
+
+func (bin Bin) GetID() string {
+	return strconv.FormatInt(bin.ID, 10)
+}
+
+func (bin Bin) Redirect() string {
+	return "/logistics/bins/" + bin.GetID()
+}
+
+func (unit Unit) GetID() string {
+	return strconv.FormatInt(unit.ID, 10)
+}
+
+func (unit Unit) Redirect() string {
+	return "/logistics/units/" + unit.GetID()
+}
+
+// Round rounds quantity half away from zero to the decimals of the unit.
+func (unit Unit) Round(quantity float64) float64 {
+	scale := math.Pow10(unit.Decimals)
+	return math.Round(quantity*scale) / scale
+}
+
+// RoundUp rounds quantity up to the decimals of the unit, ignoring the error of its binary representation.
+func (unit Unit) RoundUp(quantity float64) float64 {
+	scale := math.Pow10(unit.Decimals)
+	return math.Ceil(quantity*scale-1e-9) / scale
+}
+
+// Validate returns ErrBadRequest if quantity is not positive or has more decimals than the unit allows.
+func (unit Unit) Validate(quantity float64) error {
+	if quantity <= 0 {
+		return fmt.Errorf("%w: quantity has to be positive", xerrors.ErrBadRequest)
+	}
+
+	// Quantities are entered as decimals, allow for the error of their binary representation.
+	if math.Abs(unit.Round(quantity)-quantity) > 1e-9 {
+		if unit.Decimals == 0 {
+			return fmt.Errorf("%w: quantities in %v have to be whole numbers", xerrors.ErrBadRequest, unit.Code)
+		}
+		return fmt.Errorf("%w: quantities in %v can have at most %v decimals", xerrors.ErrBadRequest, unit.Code, unit.Decimals)
+	}
+
+	return nil
+}
+
+// ToBase validates quantity in the unit of the conversion and returns it in the base unit, rounded to the decimals of
+// the base unit.
+func (conversion UnitConversion) ToBase(quantity float64) (float64, error) {
+	if err := conversion.Unit.Validate(quantity); err != nil {
+		return 0, err
+	}
+
+	base := conversion.BaseUnit.Round(quantity * conversion.Factor)
+	if base <= 0 {
+		return 0, fmt.Errorf("%w: %v %v is less than the smallest quantity of %v", xerrors.ErrBadRequest, quantity, conversion.Unit.Code, conversion.BaseUnit.Code)
+	}
+
+	return base, nil
+}
+
+func (supplier Supplier) GetID() string {
+	return strconv.FormatInt(supplier.ID, 10)
+}
+
+func (supplier Supplier) Redirect() string {
+	return "/logistics/suppliers/" + supplier.GetID()
+}
+
+// OpenQuantity is the quantity of the line in the base unit of the item that has not been received yet.
+func (line PurchaseOrderLine) OpenQuantity() float64 {
+	open := math.Round((line.BaseQuantity-line.ReceivedQuantity)*1000) / 1000
+	return max(open, 0)
+}
+
+func (line PurchaseOrderLine) Amount() int64 {
+	return int64(math.Round(line.Quantity * float64(line.Price)))
+}
+
+func (order PurchaseOrder) Total() int64 {
+	var total int64
+	for _, line := range order.Lines {
+		total += line.Amount()
+	}
+	return total
+}
+
+// Editable reports whether the order can still be changed, which is only the case before anything has been received.
+func (order PurchaseOrderHeader) Editable() bool {
+	return order.Status == PurchaseOrderStatusOpen
+}
+
+// Receivable reports whether goods can be received against the order.
+func (order PurchaseOrderHeader) Receivable() bool {
+	return order.Status == PurchaseOrderStatusOpen || order.Status == PurchaseOrderStatusPartiallyReceived
+}
+
+func (order PurchaseOrderHeader) Reference() string {
+	return "PO-" + order.GetID()
+}
+
+func (order PurchaseOrderHeader) GetID() string {
+	return strconv.FormatInt(order.ID, 10)
+}
+
+func (order PurchaseOrderHeader) Redirect() string {
+	return "/logistics/purchase-orders/" + order.GetID()
+}
+
+// OpenQuantity is the quantity of the line in the base unit of the item that is not on a delivery yet.
+func (line SalesOrderLine) OpenQuantity() float64 {
+	open := math.Round((line.BaseQuantity-line.DeliveredQuantity)*1000) / 1000
+	return max(open, 0)
+}
+
+func (line SalesOrderLine) Amount() int64 {
+	return int64(math.Round(line.Quantity * float64(line.Price)))
+}
+
+func (order SalesOrder) Total() int64 {
+	var total int64
+	for _, line := range order.Lines {
+		total += line.Amount()
+	}
+	return total
+}
+
+// deliveryStatus derives the status of the order from the delivered and shipped quantities of its lines.
+func (order SalesOrder) deliveryStatus() string {
+	delivered, allDelivered, allShipped := false, true, true
+	for _, line := range order.Lines {
+		if line.DeliveredQuantity > 0 {
+			delivered = true
+		}
+		if line.OpenQuantity() > 0 {
+			allDelivered = false
+		}
+		if math.Round(line.ShippedQuantity*1000) < math.Round(line.BaseQuantity*1000) {
+			allShipped = false
+		}
+	}
+
+	switch {
+	case allShipped:
+		return SalesOrderStatusShipped
+	case allDelivered:
+		return SalesOrderStatusDelivered
+	case delivered:
+		return SalesOrderStatusPartiallyDelivered
+	default:
+		return SalesOrderStatusOrdered
+	}
+}
+
+// Editable reports whether the order can still be changed, which is only the case before deliveries are created.
+func (order SalesOrderHeader) Editable() bool {
+	return order.Status == SalesOrderStatusOrdered
+}
+
+// Deliverable reports whether deliveries can be created for the order.
+func (order SalesOrderHeader) Deliverable() bool {
+	return order.Status == SalesOrderStatusOrdered || order.Status == SalesOrderStatusPartiallyDelivered
+}
+
+func (order SalesOrderHeader) Reference() string {
+	return "SO-" + order.GetID()
+}
+
+func (order SalesOrderHeader) GetID() string {
+	return strconv.FormatInt(order.ID, 10)
+}
+
+func (order SalesOrderHeader) Redirect() string {
+	return "/logistics/sales-orders/" + order.GetID()
+}
+
+func (delivery DeliveryHeader) Open() bool {
+	return delivery.Status == DeliveryStatusOpen
+}
+
+func (delivery DeliveryHeader) Reference() string {
+	return "DL-" + delivery.GetID()
+}
+
+func (delivery DeliveryHeader) GetID() string {
+	return strconv.FormatInt(delivery.ID, 10)
+}
+
+func (delivery DeliveryHeader) Redirect() string {
+	return "/logistics/deliveries/" + delivery.GetID()
+}
+
+func (shipment ShipmentHeader) GetID() string {
+	return strconv.FormatInt(shipment.ID, 10)
+}
+
+func (shipment ShipmentHeader) Redirect() string {
+	return "/logistics/shipments/" + shipment.GetID()
+}
+
+func (shipment ShipmentHeader) Reference() string {
+	return "SH-" + shipment.GetID()
+}
+
+func (shipment ShipmentHeader) Open() bool {
+	return shipment.Status == ShipmentStatusOpen
+}
+
+func (shipment ShipmentHeader) Labeled() bool {
+	return shipment.Status == ShipmentStatusLabeled
+}
+
+// Weight returns the total weight of the packages in kilograms.
+func (shipment Shipment) Weight() float64 {
+	var weight float64
+	for _, pkg := range shipment.Packages {
+		weight += pkg.Weight
+	}
+	return math.Round(weight*1000) / 1000
+}
+
+func (batch Batch) GetID() string {
+	return strconv.FormatInt(batch.ID, 10)
+}
+
+func (batch Batch) Redirect() string {
+	return "/logistics/batches/" + batch.GetID()
+}
+
+// Expired reports whether the batch expired before date, batches without an expiry date do not expire.
+func (batch Batch) Expired(date string) bool {
+	return batch.ExpiryDate != "" && batch.ExpiryDate < date
+}
+
+func (serialNumber SerialNumber) GetID() string {
+	return strconv.FormatInt(serialNumber.ID, 10)
+}
+
+func (serialNumber SerialNumber) Redirect() string {
+	return "/logistics/serial-numbers/" + serialNumber.GetID()
+}
+
+// Projected returns the projected stock.
+func (projection Projection) Projected() float64 {
+	return projection.OnHand + projection.OpenSupply - projection.OpenDemand
+}
+
+// Proposal returns the quantity to purchase if the projected stock is below the reorder point: the missing quantity
+// rounded up to a multiple of the lot size and to the decimals of the base unit.
+func (projection Projection) Proposal() (float64, bool) {
+	missing := projection.ReorderPoint - projection.Projected()
+	if missing <= 0 {
+		return 0, false
+	}
+
+	if projection.LotSize > 0 {
+		missing = math.Ceil(missing/projection.LotSize-1e-9) * projection.LotSize
+	}
+
+	unit := Unit{Decimals: projection.Decimals}
+	return unit.RoundUp(missing), true
+}
+
+func (count InventoryCountHeader) GetID() string {
+	return strconv.FormatInt(count.ID, 10)
+}
+
+func (count InventoryCountHeader) Redirect() string {
+	return "/logistics/inventory-counts/" + count.GetID()
+}
+
+func (count InventoryCountHeader) Open() bool {
+	return count.Status == CountStatusOpen
+}
+
+func (count InventoryCountHeader) Reference() string {
+	return "IC-" + count.GetID()
+}
+
+// Counted reports whether every line has a counted quantity.
+func (count InventoryCount) Counted() bool {
+	for _, line := range count.Lines {
+		if !line.CountedQuantity.Valid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Difference returns the counted minus the book quantity, it is zero while the line has not been counted.
+func (line InventoryCountLine) Difference() float64 {
+	if !line.CountedQuantity.Valid {
+		return 0
+	}
+
+	unit := Unit{Decimals: line.Decimals}
+	return unit.Round(line.CountedQuantity.Float64 - line.BookQuantity)
+}
+
+func (proposal PurchaseProposal) GetID() string {
+	return strconv.FormatInt(proposal.ID, 10)
+}
+
+func (proposal PurchaseProposal) Redirect() string {
+	return "/logistics/purchase-proposals/" + proposal.GetID()
+}
+
+func (proposal PurchaseProposal) Open() bool {
+	return proposal.Status == ProposalStatusOpen
+}
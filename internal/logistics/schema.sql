@@ -1,9 +1,16 @@
 CREATE SCHEMA IF NOT EXISTS logistics;
 GRANT ALL ON SCHEMA logistics TO postgres;
 
-CREATE TABLE IF NOT EXISTS logistics.item_categories (
-    id   SERIAL       PRIMARY KEY,
-    name VARCHAR(255) UNIQUE NOT NULL
+-- Tables are created with all of their columns. Columns and constraints added to an existing table are also added by
+-- the ALTER TABLE statements following it, so applying the schema again brings databases of earlier versions up to
+-- date. Constraints that can not be added conditionally are added in a block that ignores them if they exist.
+
+-- Countries are seeded from ISO 3166-1 in seed.sql, postal codes have to match the pattern of their country unless it is
+-- empty.
+CREATE TABLE IF NOT EXISTS logistics.countries (
+    code                VARCHAR(2)   PRIMARY KEY,
+    name                VARCHAR(255) NOT NULL,
+    postal_code_pattern VARCHAR(255) NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS logistics.addresses (
@@ -11,22 +18,590 @@ CREATE TABLE IF NOT EXISTS logistics.addresses (
     zip       VARCHAR(255) NOT NULL,
     city      VARCHAR(255) NOT NULL,
     street    VARCHAR(255) NOT NULL,
-    country   VARCHAR(255) NOT NULL,
+    country   VARCHAR(2)   NOT NULL REFERENCES logistics.countries(code),
     latitude  NUMERIC(8,6) NOT NULL DEFAULT 0,
     longitude NUMERIC(9,6) NOT NULL DEFAULT 0
 );
 
-CREATE TABLE IF NOT EXISTS logistics.items (
+-- Earlier versions stored countries as free text. Apply seed.sql and then the schema again, countries matching the name
+-- of a seeded country are replaced by its code. Other countries have to be replaced by hand, for example
+-- UPDATE logistics.addresses SET country = 'DE' WHERE country = 'Deutschland'.
+UPDATE logistics.addresses
+SET country = countries.code
+FROM logistics.countries
+WHERE LENGTH(addresses.country) > 2 AND LOWER(addresses.country) = LOWER(countries.name);
+
+ALTER TABLE logistics.addresses ALTER COLUMN country TYPE VARCHAR(2);
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.addresses ADD CONSTRAINT addresses_country_fkey FOREIGN KEY (country) REFERENCES logistics.countries(code);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+-- New addresses are checked for duplicates within their postal code.
+CREATE INDEX IF NOT EXISTS addresses_country_zip_idx ON logistics.addresses (country, zip);
+
+-- Distance queries prefilter addresses by a bounding box of their coordinates.
+CREATE INDEX IF NOT EXISTS addresses_coordinates_idx ON logistics.addresses (latitude, longitude);
+
+-- Postal codes imported from a GeoNames postal code dump, addresses without coordinates are geocoded with them.
+CREATE TABLE IF NOT EXISTS logistics.postal_codes (
+    country   VARCHAR(2)   NOT NULL,
+    zip       VARCHAR(20)  NOT NULL,
+    place     VARCHAR(180) NOT NULL,
+    latitude  NUMERIC(8,6) NOT NULL,
+    longitude NUMERIC(9,6) NOT NULL,
+    PRIMARY KEY (country, zip, place)
+);
+
+-- Quantities in a unit are rounded to its number of decimals.
+CREATE TABLE IF NOT EXISTS logistics.units (
+    id       SERIAL       PRIMARY KEY,
+    code     VARCHAR(16)  NOT NULL UNIQUE,
+    name     VARCHAR(255) NOT NULL,
+    decimals SMALLINT     NOT NULL DEFAULT 0 CHECK (decimals BETWEEN 0 AND 3)
+);
+
+-- Categories form a tree, top-level categories have no parent. The defaults are applied to new items of the category
+-- and of its subcategories that do not set them, empty defaults are inherited from the parent.
+CREATE TABLE IF NOT EXISTS logistics.item_categories (
+    id                   SERIAL       PRIMARY KEY,
+    name                 VARCHAR(255) UNIQUE NOT NULL,
+    parent_id            INTEGER      REFERENCES logistics.item_categories(id),
+    default_base_unit_id INTEGER      REFERENCES logistics.units(id),
+    default_tracking     VARCHAR(16)  NOT NULL DEFAULT '' CHECK (default_tracking IN ('', 'none', 'batch', 'serial'))
+);
+
+ALTER TABLE logistics.item_categories
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES logistics.item_categories(id),
+    ADD COLUMN IF NOT EXISTS default_base_unit_id INTEGER REFERENCES logistics.units(id),
+    ADD COLUMN IF NOT EXISTS default_tracking VARCHAR(16) NOT NULL DEFAULT '' CHECK (default_tracking IN ('', 'none', 'batch', 'serial'));
+
+CREATE INDEX IF NOT EXISTS item_categories_parent_id_idx ON logistics.item_categories (parent_id);
+
+-- Rates are percentages. Changing a rate recalculates the derived prices of all items of the category.
+CREATE TABLE IF NOT EXISTS logistics.tax_categories (
+    id   SERIAL       PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    rate NUMERIC(6,3) NOT NULL CHECK (rate >= 0)
+);
+
+-- Gross prices in a currency are rounded to a multiple of increment minor units, for example 5 for Swiss francs.
+-- Currencies without a rounding are rounded to whole minor units.
+CREATE TABLE IF NOT EXISTS logistics.currency_roundings (
+    currency  VARCHAR(3) PRIMARY KEY,
+    increment INTEGER    NOT NULL CHECK (increment > 0)
+);
+
+-- Attributes of a category apply to the items of the category and of all of its subcategories. Options are the values
+-- of enum attributes.
+CREATE TABLE IF NOT EXISTS logistics.item_attributes (
     id          SERIAL       PRIMARY KEY,
-    name        VARCHAR(255) NOT NULL UNIQUE,
-    sku         VARCHAR(255) NOT NULL UNIQUE,
     category_id INTEGER      NOT NULL REFERENCES logistics.item_categories(id),
-    gross_price INTEGER      NOT NULL DEFAULT 0,
-    net_price   INTEGER      NOT NULL DEFAULT 0
+    name        VARCHAR(255) NOT NULL,
+    type        VARCHAR(16)  NOT NULL CHECK (type IN ('text', 'number', 'enum', 'boolean')),
+    options     TEXT[]       NOT NULL DEFAULT '{}',
+    required    BOOLEAN      NOT NULL DEFAULT FALSE,
+    UNIQUE (category_id, name)
+);
+
+-- Variants are generated from a parent item, they reference it with parent_id. Prices are in minor units of the
+-- currency, the price named by price_entry is entered and the other one derived from it with the rate of the tax
+-- category.
+CREATE TABLE IF NOT EXISTS logistics.items (
+    id              SERIAL       PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL UNIQUE,
+    sku             VARCHAR(255) NOT NULL UNIQUE,
+    category_id     INTEGER      NOT NULL REFERENCES logistics.item_categories(id),
+    gross_price     INTEGER      NOT NULL DEFAULT 0,
+    net_price       INTEGER      NOT NULL DEFAULT 0,
+    base_unit_id    INTEGER      NOT NULL REFERENCES logistics.units(id),
+    tracking        VARCHAR(16)  NOT NULL DEFAULT 'none' CHECK (tracking IN ('none', 'batch', 'serial')),
+    parent_id       INTEGER      REFERENCES logistics.items(id),
+    tax_category_id INTEGER      NOT NULL DEFAULT 1 REFERENCES logistics.tax_categories(id),
+    currency        VARCHAR(3)   NOT NULL DEFAULT 'EUR',
+    price_entry     VARCHAR(5)   NOT NULL DEFAULT 'net' CHECK (price_entry IN ('net', 'gross')),
+    gtin            VARCHAR(14)  NOT NULL DEFAULT '' CHECK (gtin ~ '^([0-9]{8}|[0-9]{12,14})?$')
+);
+
+ALTER TABLE logistics.items ADD COLUMN IF NOT EXISTS base_unit_id INTEGER REFERENCES logistics.units(id);
+
+-- Items of earlier versions are counted in pieces.
+INSERT INTO logistics.units (code, name, decimals)
+SELECT 'PCE', 'Piece', 0
+WHERE EXISTS (SELECT FROM logistics.items WHERE base_unit_id IS NULL)
+ON CONFLICT (code) DO NOTHING;
+
+UPDATE logistics.items SET base_unit_id = (SELECT id FROM logistics.units WHERE code = 'PCE') WHERE base_unit_id IS NULL;
+ALTER TABLE logistics.items ALTER COLUMN base_unit_id SET NOT NULL;
+
+ALTER TABLE logistics.items
+    ADD COLUMN IF NOT EXISTS tracking VARCHAR(16) NOT NULL DEFAULT 'none' CHECK (tracking IN ('none', 'batch', 'serial'));
+
+ALTER TABLE logistics.items ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES logistics.items(id);
+
+ALTER TABLE logistics.items
+    ADD COLUMN IF NOT EXISTS tax_category_id INTEGER REFERENCES logistics.tax_categories(id),
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    ADD COLUMN IF NOT EXISTS price_entry VARCHAR(5) NOT NULL DEFAULT 'net' CHECK (price_entry IN ('net', 'gross'));
+
+-- Items of earlier versions are taxed at the standard rate, their prices are kept as entered.
+INSERT INTO logistics.tax_categories (name, rate)
+SELECT 'Standard', 19
+WHERE EXISTS (SELECT FROM logistics.items WHERE tax_category_id IS NULL)
+ON CONFLICT (name) DO NOTHING;
+
+UPDATE logistics.items SET tax_category_id = (SELECT id FROM logistics.tax_categories WHERE name = 'Standard') WHERE tax_category_id IS NULL;
+ALTER TABLE logistics.items ALTER COLUMN tax_category_id SET NOT NULL;
+
+ALTER TABLE logistics.items
+    ADD COLUMN IF NOT EXISTS gtin VARCHAR(14) NOT NULL DEFAULT '' CHECK (gtin ~ '^([0-9]{8}|[0-9]{12,14})?$');
+
+CREATE INDEX IF NOT EXISTS items_parent_id_idx ON logistics.items (parent_id);
+
+-- GTINs are stored as entered, padded to 14 digits they are equal if they identify the same trade item.
+CREATE UNIQUE INDEX IF NOT EXISTS items_gtin_idx ON logistics.items (LPAD(gtin, 14, '0')) WHERE gtin <> '';
+
+-- Values are stored as text in the canonical form of the attribute type, booleans as 'true' and 'false'.
+CREATE TABLE IF NOT EXISTS logistics.item_attribute_values (
+    item_id      INTEGER NOT NULL REFERENCES logistics.items(id),
+    attribute_id INTEGER NOT NULL REFERENCES logistics.item_attributes(id) ON DELETE CASCADE,
+    value        TEXT    NOT NULL,
+    PRIMARY KEY (item_id, attribute_id)
+);
+
+-- Batches of batch managed items. Dates are empty if they are not known.
+CREATE TABLE IF NOT EXISTS logistics.batches (
+    id               SERIAL       PRIMARY KEY,
+    item_id          INTEGER      NOT NULL REFERENCES logistics.items(id),
+    number           VARCHAR(255) NOT NULL,
+    manufacture_date VARCHAR(10)  NOT NULL DEFAULT '',
+    expiry_date      VARCHAR(10)  NOT NULL DEFAULT '',
+    UNIQUE (item_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS logistics.serial_numbers (
+    id      SERIAL       PRIMARY KEY,
+    item_id INTEGER      NOT NULL REFERENCES logistics.items(id),
+    number  VARCHAR(255) NOT NULL,
+    UNIQUE (item_id, number)
+);
+
+-- Alternative units of an item, one unit equals factor base units of the item.
+CREATE TABLE IF NOT EXISTS logistics.item_units (
+    item_id INTEGER       NOT NULL REFERENCES logistics.items(id),
+    unit_id INTEGER       NOT NULL REFERENCES logistics.units(id),
+    factor  NUMERIC(18,6) NOT NULL CHECK (factor > 0),
+    PRIMARY KEY (item_id, unit_id)
+);
+
+-- Bills of materials, one base unit of the item is assembled from quantity base units of the component. Components can
+-- have bills of materials themselves, cycles are rejected when lines are saved.
+CREATE TABLE IF NOT EXISTS logistics.bom_lines (
+    item_id      INTEGER       NOT NULL REFERENCES logistics.items(id),
+    component_id INTEGER       NOT NULL REFERENCES logistics.items(id),
+    quantity     NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (item_id, component_id),
+    CHECK (item_id <> component_id)
 );
 
+CREATE INDEX IF NOT EXISTS bom_lines_component_id_idx ON logistics.bom_lines (component_id);
+
 CREATE TABLE IF NOT EXISTS logistics.plants (
+    id                   SERIAL       PRIMARY KEY,
+    name                 VARCHAR(255) NOT NULL UNIQUE,
+    address_id           INTEGER      NOT NULL REFERENCES logistics.addresses(id),
+    allow_negative_stock BOOLEAN      NOT NULL DEFAULT FALSE
+);
+
+ALTER TABLE logistics.plants ADD COLUMN IF NOT EXISTS allow_negative_stock BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS logistics.customer_groups (
+    id   SERIAL       PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS logistics.customers (
+    id         SERIAL       PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL UNIQUE,
+    address_id INTEGER      NOT NULL REFERENCES logistics.addresses(id),
+    group_id   INTEGER      REFERENCES logistics.customer_groups(id)
+);
+
+ALTER TABLE logistics.customers ADD COLUMN IF NOT EXISTS group_id INTEGER REFERENCES logistics.customer_groups(id);
+
+-- Price lists hold net prices per base unit. A list applies to one customer, to the customers of one group or, if it
+-- has neither, to all customers. Empty validity dates are open ended.
+CREATE TABLE IF NOT EXISTS logistics.price_lists (
+    id                SERIAL       PRIMARY KEY,
+    name              VARCHAR(255) NOT NULL UNIQUE,
+    currency          VARCHAR(3)   NOT NULL,
+    valid_from        VARCHAR(10)  NOT NULL DEFAULT '',
+    valid_to          VARCHAR(10)  NOT NULL DEFAULT '',
+    customer_group_id INTEGER      REFERENCES logistics.customer_groups(id),
+    customer_id       INTEGER      REFERENCES logistics.customers(id),
+    CHECK (customer_group_id IS NULL OR customer_id IS NULL)
+);
+
+-- Quantity scales: a price applies from min_quantity base units on.
+CREATE TABLE IF NOT EXISTS logistics.prices (
+    id            SERIAL        PRIMARY KEY,
+    price_list_id INTEGER       NOT NULL REFERENCES logistics.price_lists(id) ON DELETE CASCADE,
+    item_id       INTEGER       NOT NULL REFERENCES logistics.items(id),
+    min_quantity  NUMERIC(18,3) NOT NULL DEFAULT 0 CHECK (min_quantity >= 0),
+    price         INTEGER       NOT NULL CHECK (price >= 0),
+    UNIQUE (price_list_id, item_id, min_quantity)
+);
+
+CREATE TABLE IF NOT EXISTS logistics.storage_locations (
+    id       SERIAL       PRIMARY KEY,
+    plant_id INTEGER      NOT NULL REFERENCES logistics.plants(id),
+    name     VARCHAR(255) NOT NULL,
+    UNIQUE (plant_id, name)
+);
+
+-- A capacity of zero means the bin is not limited, otherwise it is given in the capacity unit and stock of items with
+-- another base unit is converted with their alternative units. Blocked bins can not receive stock.
+CREATE TABLE IF NOT EXISTS logistics.bins (
+    id                  SERIAL        PRIMARY KEY,
+    storage_location_id INTEGER       NOT NULL REFERENCES logistics.storage_locations(id),
+    name                VARCHAR(255)  NOT NULL,
+    capacity            NUMERIC(18,3) NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+    capacity_unit_id    INTEGER       REFERENCES logistics.units(id),
+    blocked             BOOLEAN       NOT NULL DEFAULT FALSE,
+    UNIQUE (storage_location_id, name),
+    CONSTRAINT bins_capacity_unit_check CHECK (capacity = 0 OR capacity_unit_id IS NOT NULL)
+);
+
+ALTER TABLE logistics.bins ADD COLUMN IF NOT EXISTS capacity_unit_id INTEGER REFERENCES logistics.units(id);
+
+-- Limited bins of earlier versions summed their stock regardless of its unit, they are limited in pieces.
+UPDATE logistics.bins SET capacity_unit_id = (SELECT id FROM logistics.units WHERE code = 'PCE')
+WHERE capacity <> 0 AND capacity_unit_id IS NULL;
+
+ALTER TABLE logistics.bins DROP CONSTRAINT IF EXISTS bins_check;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.bins ADD CONSTRAINT bins_capacity_unit_check CHECK (capacity = 0 OR capacity_unit_id IS NOT NULL);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS logistics.suppliers (
     id         SERIAL       PRIMARY KEY,
     name       VARCHAR(255) NOT NULL UNIQUE,
     address_id INTEGER      NOT NULL REFERENCES logistics.addresses(id)
 );
+
+-- Line prices are in the currency of the order.
+CREATE TABLE IF NOT EXISTS logistics.purchase_orders (
+    id            SERIAL      PRIMARY KEY,
+    supplier_id   INTEGER     NOT NULL REFERENCES logistics.suppliers(id),
+    plant_id      INTEGER     NOT NULL REFERENCES logistics.plants(id),
+    date          VARCHAR(10) NOT NULL,
+    delivery_date VARCHAR(10) NOT NULL,
+    currency      VARCHAR(3)  NOT NULL DEFAULT 'EUR',
+    status        VARCHAR(32) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'partially_received', 'received', 'cancelled'))
+);
+
+ALTER TABLE logistics.purchase_orders ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+
+-- Quantity and price are in the unit of the line, base_quantity is the quantity in the base unit of the item which
+-- receipts are counted against.
+CREATE TABLE IF NOT EXISTS logistics.purchase_order_lines (
+    id                SERIAL        PRIMARY KEY,
+    purchase_order_id INTEGER       NOT NULL REFERENCES logistics.purchase_orders(id) ON DELETE CASCADE,
+    item_id           INTEGER       NOT NULL REFERENCES logistics.items(id),
+    quantity          NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    unit_id           INTEGER       NOT NULL REFERENCES logistics.units(id),
+    base_quantity     NUMERIC(18,3) NOT NULL CHECK (base_quantity > 0),
+    price             INTEGER       NOT NULL DEFAULT 0
+);
+
+-- Planning parameters of an item in a plant, quantities are in the base unit of the item. A lot size of zero orders
+-- exactly the quantity missing to the reorder point, the supplier is proposed for purchases.
+CREATE TABLE IF NOT EXISTS logistics.planning_parameters (
+    item_id        INTEGER       NOT NULL REFERENCES logistics.items(id),
+    plant_id       INTEGER       NOT NULL REFERENCES logistics.plants(id),
+    reorder_point  NUMERIC(18,3) NOT NULL CHECK (reorder_point >= 0),
+    safety_stock   NUMERIC(18,3) NOT NULL DEFAULT 0 CHECK (safety_stock >= 0 AND safety_stock <= reorder_point),
+    lot_size       NUMERIC(18,3) NOT NULL DEFAULT 0 CHECK (lot_size >= 0),
+    lead_time_days INTEGER       NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0),
+    supplier_id    INTEGER       REFERENCES logistics.suppliers(id),
+    PRIMARY KEY (item_id, plant_id)
+);
+
+-- Purchase proposals of planning runs, quantities are in the base unit of the item. Every run replaces the open
+-- proposals, accepted proposals reference the purchase order created from them.
+CREATE TABLE IF NOT EXISTS logistics.purchase_proposals (
+    id                 SERIAL        PRIMARY KEY,
+    item_id            INTEGER       NOT NULL REFERENCES logistics.items(id),
+    plant_id           INTEGER       NOT NULL REFERENCES logistics.plants(id),
+    supplier_id        INTEGER       REFERENCES logistics.suppliers(id),
+    quantity           NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    delivery_date      VARCHAR(10)   NOT NULL,
+    projected_quantity NUMERIC(18,3) NOT NULL,
+    below_safety_stock BOOLEAN       NOT NULL DEFAULT FALSE,
+    status             VARCHAR(32)   NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'accepted', 'discarded')),
+    purchase_order_id  INTEGER       REFERENCES logistics.purchase_orders(id),
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT now()
+);
+
+-- The shipping address defaults to the address of the customer, the plant is the one the goods are delivered from.
+-- Line prices are in the currency of the order, only price lists in that currency apply.
+CREATE TABLE IF NOT EXISTS logistics.sales_orders (
+    id                      SERIAL      PRIMARY KEY,
+    customer_id             INTEGER     NOT NULL REFERENCES logistics.customers(id),
+    plant_id                INTEGER     NOT NULL REFERENCES logistics.plants(id),
+    shipping_address_id     INTEGER     NOT NULL REFERENCES logistics.addresses(id),
+    date                    VARCHAR(10) NOT NULL,
+    requested_delivery_date VARCHAR(10) NOT NULL,
+    currency                VARCHAR(3)  NOT NULL DEFAULT 'EUR',
+    status                  VARCHAR(32) NOT NULL DEFAULT 'ordered'
+        CHECK (status IN ('ordered', 'partially_delivered', 'delivered', 'shipped', 'cancelled'))
+);
+
+ALTER TABLE logistics.sales_orders ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+
+-- See purchase_order_lines, deliveries are counted against base_quantity.
+CREATE TABLE IF NOT EXISTS logistics.sales_order_lines (
+    id             SERIAL        PRIMARY KEY,
+    sales_order_id INTEGER       NOT NULL REFERENCES logistics.sales_orders(id) ON DELETE CASCADE,
+    item_id        INTEGER       NOT NULL REFERENCES logistics.items(id),
+    quantity       NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    unit_id        INTEGER       NOT NULL REFERENCES logistics.units(id),
+    base_quantity  NUMERIC(18,3) NOT NULL CHECK (base_quantity > 0),
+    price          INTEGER       NOT NULL DEFAULT 0
+);
+
+-- Deliveries are picked in the plant of their sales order and issue the goods when they are shipped. Cancelled
+-- deliveries are kept, their quantities are open on the sales order again.
+CREATE TABLE IF NOT EXISTS logistics.deliveries (
+    id             SERIAL      PRIMARY KEY,
+    sales_order_id INTEGER     NOT NULL REFERENCES logistics.sales_orders(id),
+    date           VARCHAR(10) NOT NULL,
+    status         VARCHAR(32) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'shipped', 'cancelled'))
+);
+
+-- Quantities are in the base unit of the item, the bin is the one the goods are picked from.
+CREATE TABLE IF NOT EXISTS logistics.delivery_lines (
+    id                  SERIAL        PRIMARY KEY,
+    delivery_id         INTEGER       NOT NULL REFERENCES logistics.deliveries(id),
+    sales_order_line_id INTEGER       NOT NULL REFERENCES logistics.sales_order_lines(id),
+    quantity            NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    bin_id              INTEGER       REFERENCES logistics.bins(id),
+    batch_id            INTEGER       REFERENCES logistics.batches(id)
+);
+
+ALTER TABLE logistics.delivery_lines ADD COLUMN IF NOT EXISTS batch_id INTEGER REFERENCES logistics.batches(id);
+
+CREATE TABLE IF NOT EXISTS logistics.delivery_line_serial_numbers (
+    delivery_line_id INTEGER NOT NULL REFERENCES logistics.delivery_lines(id),
+    serial_number_id INTEGER NOT NULL REFERENCES logistics.serial_numbers(id),
+    PRIMARY KEY (delivery_line_id, serial_number_id)
+);
+
+-- Shipments hand the goods of a delivery to a carrier. Packages can be changed while the shipment is open, creating the
+-- label registers the shipment with the carrier and stores its tracking numbers.
+CREATE TABLE IF NOT EXISTS logistics.shipments (
+    id              SERIAL      PRIMARY KEY,
+    delivery_id     INTEGER     NOT NULL REFERENCES logistics.deliveries(id),
+    carrier         VARCHAR(32) NOT NULL,
+    service         VARCHAR(32) NOT NULL,
+    tracking_number VARCHAR(64) NOT NULL DEFAULT '',
+    status          VARCHAR(32) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'labeled', 'cancelled')),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Dimensions are in centimeters, the weight in kilograms.
+CREATE TABLE IF NOT EXISTS logistics.shipment_packages (
+    id              SERIAL        PRIMARY KEY,
+    shipment_id     INTEGER       NOT NULL REFERENCES logistics.shipments(id) ON DELETE CASCADE,
+    length          NUMERIC(8,1)  NOT NULL CHECK (length > 0),
+    width           NUMERIC(8,1)  NOT NULL CHECK (width > 0),
+    height          NUMERIC(8,1)  NOT NULL CHECK (height > 0),
+    weight          NUMERIC(10,3) NOT NULL CHECK (weight > 0),
+    tracking_number VARCHAR(64)   NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS shipments_delivery_id_idx ON logistics.shipments (delivery_id);
+
+-- Goods movements are immutable, stock is always derived from them. Receipts only have a destination plant, issues only a
+-- source plant, transfers have both and adjustments have one of them depending on the direction of the correction.
+-- Transfers within a plant move stock between bins.
+CREATE TABLE IF NOT EXISTS logistics.goods_movements (
+    id                     SERIAL        PRIMARY KEY,
+    type                   VARCHAR(16)   NOT NULL,
+    date                   VARCHAR(10)   NOT NULL,
+    item_id                INTEGER       NOT NULL REFERENCES logistics.items(id),
+    from_plant_id          INTEGER       REFERENCES logistics.plants(id),
+    to_plant_id            INTEGER       REFERENCES logistics.plants(id),
+    from_bin_id            INTEGER       REFERENCES logistics.bins(id),
+    to_bin_id              INTEGER       REFERENCES logistics.bins(id),
+    purchase_order_line_id INTEGER       REFERENCES logistics.purchase_order_lines(id),
+    delivery_line_id       INTEGER       REFERENCES logistics.delivery_lines(id),
+    batch_id               INTEGER       REFERENCES logistics.batches(id),
+    quantity               NUMERIC(18,3) NOT NULL CHECK (quantity > 0),
+    reference              VARCHAR(255)  NOT NULL DEFAULT '',
+    note                   TEXT          NOT NULL DEFAULT '',
+    created_at             TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    CONSTRAINT goods_movements_type_check CHECK (
+        (type = 'receipt'    AND from_plant_id IS NULL     AND to_plant_id IS NOT NULL) OR
+        (type = 'issue'      AND from_plant_id IS NOT NULL AND to_plant_id IS NULL) OR
+        (type = 'transfer'   AND from_plant_id IS NOT NULL AND to_plant_id IS NOT NULL AND
+            (from_plant_id <> to_plant_id OR from_bin_id IS DISTINCT FROM to_bin_id)) OR
+        (type = 'adjustment' AND (from_plant_id IS NULL) <> (to_plant_id IS NULL))
+    ),
+    CONSTRAINT goods_movements_from_bin_check CHECK (from_bin_id IS NULL OR from_plant_id IS NOT NULL),
+    CONSTRAINT goods_movements_to_bin_check CHECK (to_bin_id IS NULL OR to_plant_id IS NOT NULL),
+    CONSTRAINT goods_movements_purchase_order_line_check CHECK (purchase_order_line_id IS NULL OR type = 'receipt'),
+    CONSTRAINT goods_movements_delivery_line_check CHECK (delivery_line_id IS NULL OR type = 'issue')
+);
+
+ALTER TABLE logistics.goods_movements ADD COLUMN IF NOT EXISTS from_bin_id INTEGER REFERENCES logistics.bins(id);
+ALTER TABLE logistics.goods_movements ADD COLUMN IF NOT EXISTS to_bin_id INTEGER REFERENCES logistics.bins(id);
+
+-- Earlier versions created the checks without names, the type check did not allow transfers between bins of a plant.
+ALTER TABLE logistics.goods_movements
+    DROP CONSTRAINT IF EXISTS goods_movements_check,
+    DROP CONSTRAINT IF EXISTS goods_movements_check1,
+    DROP CONSTRAINT IF EXISTS goods_movements_check2;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.goods_movements ADD CONSTRAINT goods_movements_type_check CHECK (
+        (type = 'receipt'    AND from_plant_id IS NULL     AND to_plant_id IS NOT NULL) OR
+        (type = 'issue'      AND from_plant_id IS NOT NULL AND to_plant_id IS NULL) OR
+        (type = 'transfer'   AND from_plant_id IS NOT NULL AND to_plant_id IS NOT NULL AND
+            (from_plant_id <> to_plant_id OR from_bin_id IS DISTINCT FROM to_bin_id)) OR
+        (type = 'adjustment' AND (from_plant_id IS NULL) <> (to_plant_id IS NULL))
+    );
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.goods_movements ADD CONSTRAINT goods_movements_from_bin_check CHECK (from_bin_id IS NULL OR from_plant_id IS NOT NULL);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.goods_movements ADD CONSTRAINT goods_movements_to_bin_check CHECK (to_bin_id IS NULL OR to_plant_id IS NOT NULL);
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE logistics.goods_movements
+    ADD COLUMN IF NOT EXISTS purchase_order_line_id INTEGER REFERENCES logistics.purchase_order_lines(id),
+    DROP CONSTRAINT IF EXISTS goods_movements_check3;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.goods_movements ADD CONSTRAINT goods_movements_purchase_order_line_check
+        CHECK (purchase_order_line_id IS NULL OR type = 'receipt');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE logistics.goods_movements
+    ADD COLUMN IF NOT EXISTS delivery_line_id INTEGER REFERENCES logistics.delivery_lines(id),
+    DROP CONSTRAINT IF EXISTS goods_movements_check4;
+
+DO $$
+BEGIN
+    ALTER TABLE logistics.goods_movements ADD CONSTRAINT goods_movements_delivery_line_check CHECK (delivery_line_id IS NULL OR type = 'issue');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE logistics.goods_movements ADD COLUMN IF NOT EXISTS batch_id INTEGER REFERENCES logistics.batches(id);
+
+CREATE INDEX IF NOT EXISTS goods_movements_item_id_idx ON logistics.goods_movements (item_id);
+CREATE INDEX IF NOT EXISTS goods_movements_purchase_order_line_id_idx ON logistics.goods_movements (purchase_order_line_id);
+CREATE INDEX IF NOT EXISTS goods_movements_batch_id_idx ON logistics.goods_movements (batch_id);
+CREATE INDEX IF NOT EXISTS delivery_lines_sales_order_line_id_idx ON logistics.delivery_lines (sales_order_line_id);
+
+CREATE OR REPLACE FUNCTION logistics.reject_goods_movement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'goods movements can not be changed, post a correcting movement instead';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS goods_movements_immutable ON logistics.goods_movements;
+CREATE TRIGGER goods_movements_immutable
+    BEFORE UPDATE OR DELETE ON logistics.goods_movements
+    FOR EACH ROW EXECUTE FUNCTION logistics.reject_goods_movement_change();
+
+-- The serial numbers moved by a goods movement of a serialized item, one per unit of its quantity.
+CREATE TABLE IF NOT EXISTS logistics.goods_movement_serial_numbers (
+    goods_movement_id INTEGER NOT NULL REFERENCES logistics.goods_movements(id),
+    serial_number_id  INTEGER NOT NULL REFERENCES logistics.serial_numbers(id),
+    PRIMARY KEY (goods_movement_id, serial_number_id)
+);
+
+CREATE INDEX IF NOT EXISTS goods_movement_serial_numbers_serial_number_id_idx
+    ON logistics.goods_movement_serial_numbers (serial_number_id);
+
+DROP TRIGGER IF EXISTS goods_movement_serial_numbers_immutable ON logistics.goods_movement_serial_numbers;
+CREATE TRIGGER goods_movement_serial_numbers_immutable
+    BEFORE UPDATE OR DELETE ON logistics.goods_movement_serial_numbers
+    FOR EACH ROW EXECUTE FUNCTION logistics.reject_goods_movement_change();
+
+-- Stock without a bin is stock of the plant that has not been put away yet.
+CREATE OR REPLACE VIEW logistics.stock AS
+SELECT item_id, plant_id, SUM(quantity) AS quantity, bin_id, batch_id
+FROM (
+    SELECT item_id, to_plant_id AS plant_id, to_bin_id AS bin_id, batch_id, quantity
+    FROM logistics.goods_movements
+    WHERE to_plant_id IS NOT NULL
+    UNION ALL
+    SELECT item_id, from_plant_id AS plant_id, from_bin_id AS bin_id, batch_id, -quantity
+    FROM logistics.goods_movements
+    WHERE from_plant_id IS NOT NULL
+) AS movements
+GROUP BY item_id, plant_id, bin_id, batch_id;
+
+-- Where serial numbers are in stock, a serial number is in at most one plant and bin at a time.
+CREATE OR REPLACE VIEW logistics.serial_number_stock AS
+SELECT serial_number_id, plant_id, bin_id
+FROM (
+    SELECT serials.serial_number_id, movements.to_plant_id AS plant_id, movements.to_bin_id AS bin_id, 1 AS quantity
+    FROM logistics.goods_movement_serial_numbers serials
+    JOIN logistics.goods_movements movements ON movements.id = serials.goods_movement_id
+    WHERE movements.to_plant_id IS NOT NULL
+    UNION ALL
+    SELECT serials.serial_number_id, movements.from_plant_id AS plant_id, movements.from_bin_id AS bin_id, -1
+    FROM logistics.goods_movement_serial_numbers serials
+    JOIN logistics.goods_movements movements ON movements.id = serials.goods_movement_id
+    WHERE movements.from_plant_id IS NOT NULL
+) AS movements
+GROUP BY serial_number_id, plant_id, bin_id
+HAVING SUM(quantity) > 0;
+
+-- Physical inventory counts of a plant. While a count is open, goods movements of its items in the plant are rejected,
+-- approving it posts the differences between counted and book quantities as adjustments.
+CREATE TABLE IF NOT EXISTS logistics.inventory_counts (
+    id          SERIAL      PRIMARY KEY,
+    plant_id    INTEGER     NOT NULL REFERENCES logistics.plants(id),
+    date        VARCHAR(10) NOT NULL,
+    note        TEXT        NOT NULL DEFAULT '',
+    status      VARCHAR(32) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'approved', 'cancelled')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    approved_at TIMESTAMPTZ
+);
+
+-- A line per stock position of a counted item, the book quantity is recorded when the count is created. Items without
+-- stock get a line without bin and batch. movement_id is the adjustment that posted the difference.
+CREATE TABLE IF NOT EXISTS logistics.inventory_count_lines (
+    id                 SERIAL        PRIMARY KEY,
+    inventory_count_id INTEGER       NOT NULL REFERENCES logistics.inventory_counts(id),
+    item_id            INTEGER       NOT NULL REFERENCES logistics.items(id),
+    bin_id             INTEGER       REFERENCES logistics.bins(id),
+    batch_id           INTEGER       REFERENCES logistics.batches(id),
+    book_quantity      NUMERIC(18,3) NOT NULL,
+    counted_quantity   NUMERIC(18,3) CHECK (counted_quantity >= 0),
+    movement_id        INTEGER       REFERENCES logistics.goods_movements(id)
+);
+
+CREATE INDEX IF NOT EXISTS inventory_count_lines_inventory_count_id_idx ON logistics.inventory_count_lines (inventory_count_id);
+CREATE INDEX IF NOT EXISTS inventory_count_lines_item_id_idx ON logistics.inventory_count_lines (item_id);
@@ -0,0 +1,253 @@
+-- Countries as listed in ISO 3166-1, postal codes of a country have to match its pattern if it has one.
+INSERT INTO logistics.countries (code, name, postal_code_pattern)
+VALUES
+    ('AD', 'Andorra', 'AD\d{3}'),
+    ('AE', 'United Arab Emirates', ''),
+    ('AF', 'Afghanistan', ''),
+    ('AG', 'Antigua and Barbuda', ''),
+    ('AI', 'Anguilla', ''),
+    ('AL', 'Albania', ''),
+    ('AM', 'Armenia', ''),
+    ('AO', 'Angola', ''),
+    ('AQ', 'Antarctica', ''),
+    ('AR', 'Argentina', '[A-Z]?\d{4}([A-Z]{3})?'),
+    ('AS', 'American Samoa', ''),
+    ('AT', 'Austria', '\d{4}'),
+    ('AU', 'Australia', '\d{4}'),
+    ('AW', 'Aruba', ''),
+    ('AX', 'Åland Islands', ''),
+    ('AZ', 'Azerbaijan', ''),
+    ('BA', 'Bosnia and Herzegovina', ''),
+    ('BB', 'Barbados', ''),
+    ('BD', 'Bangladesh', ''),
+    ('BE', 'Belgium', '\d{4}'),
+    ('BF', 'Burkina Faso', ''),
+    ('BG', 'Bulgaria', '\d{4}'),
+    ('BH', 'Bahrain', ''),
+    ('BI', 'Burundi', ''),
+    ('BJ', 'Benin', ''),
+    ('BL', 'Saint Barthelemy', ''),
+    ('BM', 'Bermuda', ''),
+    ('BN', 'Brunei', ''),
+    ('BO', 'Bolivia', ''),
+    ('BQ', 'Caribbean NL', ''),
+    ('BR', 'Brazil', '\d{5}-?\d{3}'),
+    ('BS', 'Bahamas', ''),
+    ('BT', 'Bhutan', ''),
+    ('BV', 'Bouvet Island', ''),
+    ('BW', 'Botswana', ''),
+    ('BY', 'Belarus', ''),
+    ('BZ', 'Belize', ''),
+    ('CA', 'Canada', '[A-Z]\d[A-Z] ?\d[A-Z]\d'),
+    ('CC', 'Cocos (Keeling) Islands', ''),
+    ('CD', 'DR Congo', ''),
+    ('CF', 'Central African Rep.', ''),
+    ('CG', 'Congo', ''),
+    ('CH', 'Switzerland', '\d{4}'),
+    ('CI', 'Côte d''Ivoire', ''),
+    ('CK', 'Cook Islands', ''),
+    ('CL', 'Chile', ''),
+    ('CM', 'Cameroon', ''),
+    ('CN', 'China', '\d{6}'),
+    ('CO', 'Colombia', ''),
+    ('CR', 'Costa Rica', ''),
+    ('CU', 'Cuba', ''),
+    ('CV', 'Cape Verde', ''),
+    ('CW', 'Curaçao', ''),
+    ('CX', 'Christmas Island', ''),
+    ('CY', 'Cyprus', '\d{4}'),
+    ('CZ', 'Czech Republic', '\d{3} ?\d{2}'),
+    ('DE', 'Germany', '\d{5}'),
+    ('DJ', 'Djibouti', ''),
+    ('DK', 'Denmark', '\d{4}'),
+    ('DM', 'Dominica', ''),
+    ('DO', 'Dominican Republic', ''),
+    ('DZ', 'Algeria', ''),
+    ('EC', 'Ecuador', ''),
+    ('EE', 'Estonia', '\d{5}'),
+    ('EG', 'Egypt', ''),
+    ('EH', 'Western Sahara', ''),
+    ('ER', 'Eritrea', ''),
+    ('ES', 'Spain', '\d{5}'),
+    ('ET', 'Ethiopia', ''),
+    ('FI', 'Finland', '\d{5}'),
+    ('FJ', 'Fiji', ''),
+    ('FK', 'Falkland Islands', ''),
+    ('FM', 'Micronesia', ''),
+    ('FO', 'Faroe Islands', ''),
+    ('FR', 'France', '\d{5}'),
+    ('GA', 'Gabon', ''),
+    ('GB', 'United Kingdom', 'GIR ?0AA|[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}'),
+    ('GD', 'Grenada', ''),
+    ('GE', 'Georgia', ''),
+    ('GF', 'French Guiana', ''),
+    ('GG', 'Guernsey', ''),
+    ('GH', 'Ghana', ''),
+    ('GI', 'Gibraltar', ''),
+    ('GL', 'Greenland', ''),
+    ('GM', 'Gambia', ''),
+    ('GN', 'Guinea', ''),
+    ('GP', 'Guadeloupe', ''),
+    ('GQ', 'Equatorial Guinea', ''),
+    ('GR', 'Greece', '\d{3} ?\d{2}'),
+    ('GS', 'South Georgia and the South Sandwich Islands', ''),
+    ('GT', 'Guatemala', ''),
+    ('GU', 'Guam', ''),
+    ('GW', 'Guinea-Bissau', ''),
+    ('GY', 'Guyana', ''),
+    ('HK', 'Hong Kong', ''),
+    ('HM', 'Heard Island and McDonald Islands', ''),
+    ('HN', 'Honduras', ''),
+    ('HR', 'Croatia', '\d{5}'),
+    ('HT', 'Haiti', ''),
+    ('HU', 'Hungary', '\d{4}'),
+    ('ID', 'Indonesia', ''),
+    ('IE', 'Ireland', '[A-Z]\d[\dW] ?[A-Z\d]{4}'),
+    ('IL', 'Israel', ''),
+    ('IM', 'Isle of Man', ''),
+    ('IN', 'India', '\d{6}'),
+    ('IO', 'British Indian Ocean Territory', ''),
+    ('IQ', 'Iraq', ''),
+    ('IR', 'Iran', ''),
+    ('IS', 'Iceland', '\d{3}'),
+    ('IT', 'Italy', '\d{5}'),
+    ('JE', 'Jersey', ''),
+    ('JM', 'Jamaica', ''),
+    ('JO', 'Jordan', ''),
+    ('JP', 'Japan', '\d{3}-?\d{4}'),
+    ('KE', 'Kenya', ''),
+    ('KG', 'Kyrgyzstan', ''),
+    ('KH', 'Cambodia', ''),
+    ('KI', 'Kiribati', ''),
+    ('KM', 'Comoros', ''),
+    ('KN', 'Saint Kitts and Nevis', ''),
+    ('KP', 'North Korea', ''),
+    ('KR', 'South Korea', '\d{5}'),
+    ('KW', 'Kuwait', ''),
+    ('KY', 'Cayman Islands', ''),
+    ('KZ', 'Kazakhstan', ''),
+    ('LA', 'Laos', ''),
+    ('LB', 'Lebanon', ''),
+    ('LC', 'Saint Lucia', ''),
+    ('LI', 'Liechtenstein', '\d{4}'),
+    ('LK', 'Sri Lanka', ''),
+    ('LR', 'Liberia', ''),
+    ('LS', 'Lesotho', ''),
+    ('LT', 'Lithuania', '(LT-)?\d{5}'),
+    ('LU', 'Luxembourg', '(L-)?\d{4}'),
+    ('LV', 'Latvia', '(LV-)?\d{4}'),
+    ('LY', 'Libya', ''),
+    ('MA', 'Morocco', ''),
+    ('MC', 'Monaco', '980\d{2}'),
+    ('MD', 'Moldova', ''),
+    ('ME', 'Montenegro', ''),
+    ('MF', 'Saint Martin', ''),
+    ('MG', 'Madagascar', ''),
+    ('MH', 'Marshall Islands', ''),
+    ('MK', 'North Macedonia', ''),
+    ('ML', 'Mali', ''),
+    ('MM', 'Myanmar', ''),
+    ('MN', 'Mongolia', ''),
+    ('MO', 'Macau', ''),
+    ('MP', 'Northern Mariana Islands', ''),
+    ('MQ', 'Martinique', ''),
+    ('MR', 'Mauritania', ''),
+    ('MS', 'Montserrat', ''),
+    ('MT', 'Malta', '[A-Z]{3} ?\d{4}'),
+    ('MU', 'Mauritius', ''),
+    ('MV', 'Maldives', ''),
+    ('MW', 'Malawi', ''),
+    ('MX', 'Mexico', '\d{5}'),
+    ('MY', 'Malaysia', ''),
+    ('MZ', 'Mozambique', ''),
+    ('NA', 'Namibia', ''),
+    ('NC', 'New Caledonia', ''),
+    ('NE', 'Niger', ''),
+    ('NF', 'Norfolk Island', ''),
+    ('NG', 'Nigeria', ''),
+    ('NI', 'Nicaragua', ''),
+    ('NL', 'Netherlands', '\d{4} ?[A-Z]{2}'),
+    ('NO', 'Norway', '\d{4}'),
+    ('NP', 'Nepal', ''),
+    ('NR', 'Nauru', ''),
+    ('NU', 'Niue', ''),
+    ('NZ', 'New Zealand', '\d{4}'),
+    ('OM', 'Oman', ''),
+    ('PA', 'Panama', ''),
+    ('PE', 'Peru', ''),
+    ('PF', 'French Polynesia', ''),
+    ('PG', 'Papua New Guinea', ''),
+    ('PH', 'Philippines', ''),
+    ('PK', 'Pakistan', ''),
+    ('PL', 'Poland', '\d{2}-\d{3}'),
+    ('PM', 'Saint Pierre and Miquelon', ''),
+    ('PN', 'Pitcairn', ''),
+    ('PR', 'Puerto Rico', ''),
+    ('PS', 'Palestine', ''),
+    ('PT', 'Portugal', '\d{4}-\d{3}'),
+    ('PW', 'Palau', ''),
+    ('PY', 'Paraguay', ''),
+    ('QA', 'Qatar', ''),
+    ('RE', 'Réunion', ''),
+    ('RO', 'Romania', '\d{6}'),
+    ('RS', 'Serbia', ''),
+    ('RU', 'Russia', '\d{6}'),
+    ('RW', 'Rwanda', ''),
+    ('SA', 'Saudi Arabia', ''),
+    ('SB', 'Solomon Islands', ''),
+    ('SC', 'Seychelles', ''),
+    ('SD', 'Sudan', ''),
+    ('SE', 'Sweden', '\d{3} ?\d{2}'),
+    ('SG', 'Singapore', '\d{6}'),
+    ('SH', 'Saint Helena', ''),
+    ('SI', 'Slovenia', '\d{4}'),
+    ('SJ', 'Svalbard and Jan Mayen', ''),
+    ('SK', 'Slovakia', '\d{3} ?\d{2}'),
+    ('SL', 'Sierra Leone', ''),
+    ('SM', 'San Marino', ''),
+    ('SN', 'Senegal', ''),
+    ('SO', 'Somalia', ''),
+    ('SR', 'Suriname', ''),
+    ('SS', 'South Sudan', ''),
+    ('ST', 'Sao Tome and Principe', ''),
+    ('SV', 'El Salvador', ''),
+    ('SX', 'Sint Maarten', ''),
+    ('SY', 'Syria', ''),
+    ('SZ', 'Eswatini', ''),
+    ('TC', 'Turks and Caicos Is', ''),
+    ('TD', 'Chad', ''),
+    ('TF', 'French S. Terr.', ''),
+    ('TG', 'Togo', ''),
+    ('TH', 'Thailand', ''),
+    ('TJ', 'Tajikistan', ''),
+    ('TK', 'Tokelau', ''),
+    ('TL', 'East Timor', ''),
+    ('TM', 'Turkmenistan', ''),
+    ('TN', 'Tunisia', ''),
+    ('TO', 'Tonga', ''),
+    ('TR', 'Turkey', '\d{5}'),
+    ('TT', 'Trinidad and Tobago', ''),
+    ('TV', 'Tuvalu', ''),
+    ('TW', 'Taiwan', ''),
+    ('TZ', 'Tanzania', ''),
+    ('UA', 'Ukraine', ''),
+    ('UG', 'Uganda', ''),
+    ('UM', 'US minor outlying islands', ''),
+    ('US', 'United States', '\d{5}(-\d{4})?'),
+    ('UY', 'Uruguay', ''),
+    ('UZ', 'Uzbekistan', ''),
+    ('VA', 'Vatican City', ''),
+    ('VC', 'Saint Vincent', ''),
+    ('VE', 'Venezuela', ''),
+    ('VG', 'British Virgin Islands', ''),
+    ('VI', 'U.S. Virgin Islands', ''),
+    ('VN', 'Vietnam', ''),
+    ('VU', 'Vanuatu', ''),
+    ('WF', 'Wallis and Futuna', ''),
+    ('WS', 'Samoa', ''),
+    ('YE', 'Yemen', ''),
+    ('YT', 'Mayotte', ''),
+    ('ZA', 'South Africa', '\d{4}'),
+    ('ZM', 'Zambia', ''),
+    ('ZW', 'Zimbabwe', '')
+ON CONFLICT DO NOTHING;
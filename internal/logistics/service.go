@@ -1,21 +1,255 @@
 package logistics
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tombuente/apex/internal/barcode"
+	"github.com/tombuente/apex/internal/xerrors"
 )
 
-type Service struct {
+// MovementHook is called after goods movements have been recorded, other modules use it to follow stock changes. Errors
+// are logged, the movements stay recorded.
+type MovementHook func(ctx context.Context) error
+
+// Geocoder locates addresses. It returns an error wrapping xerrors.ErrNotFound if it does not know an address.
+type Geocoder interface {
+	Geocode(ctx context.Context, query GeocodeQuery) (Coordinates, error)
+}
+
+// PostalCodeGeocoder is an offline Geocoder that locates addresses at the center of their postal code, see
+// Service.importPostalCodes.
+type PostalCodeGeocoder struct {
 	db Database
 }
 
+func MakePostalCodeGeocoder(db Database) PostalCodeGeocoder {
+	return PostalCodeGeocoder{
+		db: db,
+	}
+}
+
+func (geocoder PostalCodeGeocoder) Geocode(ctx context.Context, query GeocodeQuery) (Coordinates, error) {
+	return geocoder.db.geocodePostalCode(ctx, query)
+}
+
+// Carrier ships packages. CreateLabel registers a shipment with the carrier and returns its tracking numbers, CancelLabel
+// voids a registered shipment by its tracking number. Errors wrapping xerrors.ErrBadRequest are shown to the user, e.g.
+// if the carrier does not accept a package.
+type Carrier interface {
+	Code() string
+	Name() string
+	Services() []CarrierService
+	CreateLabel(ctx context.Context, request LabelRequest) (CarrierLabel, error)
+	CancelLabel(ctx context.Context, trackingNumber string) error
+}
+
+// Package limits of the FakeCarrier in centimeters and kilograms.
+const (
+	fakeCarrierMaxLength = 120
+	fakeCarrierMaxGirth  = 300
+	fakeCarrierMaxWeight = 31.5
+)
+
+// FakeCarrier is a Carrier that works offline, e.g. for testing and demos. Its tracking numbers are derived from the
+// shipment reference, so the same shipment always gets the same numbers.
+type FakeCarrier struct{}
+
+func (FakeCarrier) Code() string {
+	return "fake"
+}
+
+func (FakeCarrier) Name() string {
+	return "Fake Carrier"
+}
+
+func (FakeCarrier) Services() []CarrierService {
+	return []CarrierService{
+		{Code: "standard", Name: "Standard"},
+		{Code: "express", Name: "Express"},
+	}
+}
+
+// CreateLabel accepts packages of up to 31.5 kg, 120 cm length and 300 cm length plus girth.
+func (carrier FakeCarrier) CreateLabel(ctx context.Context, request LabelRequest) (CarrierLabel, error) {
+	if !slices.ContainsFunc(carrier.Services(), func(service CarrierService) bool { return service.Code == request.Service }) {
+		return CarrierLabel{}, fmt.Errorf("%w: unknown service %v", xerrors.ErrBadRequest, request.Service)
+	}
+
+	trackingNumber := fmt.Sprintf("FK%010d", crc32.ChecksumIEEE([]byte(request.Reference)))
+	label := CarrierLabel{TrackingNumber: trackingNumber}
+	for i, pkg := range request.Packages {
+		sides := []float64{pkg.Length, pkg.Width, pkg.Height}
+		slices.Sort(sides)
+		switch {
+		case pkg.Weight > fakeCarrierMaxWeight:
+			return CarrierLabel{}, fmt.Errorf("%w: package %v is heavier than %v kg", xerrors.ErrBadRequest, i+1, fakeCarrierMaxWeight)
+		case sides[2] > fakeCarrierMaxLength:
+			return CarrierLabel{}, fmt.Errorf("%w: package %v is longer than %v cm", xerrors.ErrBadRequest, i+1, fakeCarrierMaxLength)
+		case sides[2]+2*(sides[0]+sides[1]) > fakeCarrierMaxGirth:
+			return CarrierLabel{}, fmt.Errorf("%w: length and girth of package %v exceed %v cm", xerrors.ErrBadRequest, i+1, fakeCarrierMaxGirth)
+		}
+
+		label.PackageTrackingNumbers = append(label.PackageTrackingNumbers, fmt.Sprintf("%v%02d", trackingNumber, i+1))
+	}
+
+	return label, nil
+}
+
+func (FakeCarrier) CancelLabel(ctx context.Context, trackingNumber string) error {
+	return nil
+}
+
+type Service struct {
+	db           Database
+	movementHook MovementHook
+	geocoder     Geocoder
+	carriers     map[string]Carrier
+}
+
+// MakeService makes a service that geocodes addresses with a PostalCodeGeocoder, see WithGeocoder.
 func MakeService(db Database) Service {
 	return Service{
-		db: db,
+		db:       db,
+		geocoder: MakePostalCodeGeocoder(db),
+	}
+}
+
+// WithGeocoder returns a copy of the service that locates addresses without coordinates with geocoder.
+func (s Service) WithGeocoder(geocoder Geocoder) Service {
+	s.geocoder = geocoder
+	return s
+}
+
+// WithCarrier returns a copy of the service that can also ship with carrier. A carrier with the same code is replaced.
+func (s Service) WithCarrier(carrier Carrier) Service {
+	carriers := make(map[string]Carrier, len(s.carriers)+1)
+	maps.Copy(carriers, s.carriers)
+	carriers[carrier.Code()] = carrier
+	s.carriers = carriers
+	return s
+}
+
+// WithMovementHook returns a copy of the service that calls hook after goods movements have been recorded.
+func (s Service) WithMovementHook(hook MovementHook) Service {
+	s.movementHook = hook
+	return s
+}
+
+// movementsRecorded runs the movement hook, it has to be called after every committed change that records goods
+// movements.
+func (s Service) movementsRecorded(ctx context.Context) {
+	if s.movementHook == nil {
+		return
+	}
+
+	if err := s.movementHook(ctx); err != nil {
+		slog.Error("Unable to run goods movement hook", "error", err)
+	}
+}
+
+func (s Service) itemCategory(ctx context.Context, id int64) (ItemCategory, error) {
+	return s.db.itemCategory(ctx, id)
+}
+
+func (s Service) itemCategories(ctx context.Context, filter ItemCategoryFilter) ([]ItemCategory, error) {
+	return s.db.itemCategories(ctx, filter)
+}
+
+func (s Service) createItemCategory(ctx context.Context, params ItemCategoryParams) (ItemCategory, error) {
+	params, err := s.validateItemCategoryParams(ctx, params)
+	if err != nil {
+		return ItemCategory{}, err
+	}
+
+	return s.db.createItemCategory(ctx, params)
+}
+
+// updateItemCategory updates a category, moving it below itself or one of its subcategories is rejected.
+func (s Service) updateItemCategory(ctx context.Context, id int64, params ItemCategoryParams) (ItemCategory, error) {
+	params, err := s.validateItemCategoryParams(ctx, params)
+	if err != nil {
+		return ItemCategory{}, err
+	}
+
+	if params.ParentID == id {
+		return ItemCategory{}, fmt.Errorf("%w: a category can not be its own parent", xerrors.ErrBadRequest)
+	}
+
+	return s.db.updateItemCategory(ctx, id, params)
+}
+
+func (s Service) validateItemCategoryParams(ctx context.Context, params ItemCategoryParams) (ItemCategoryParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return ItemCategoryParams{}, fmt.Errorf("%w: category name is required", xerrors.ErrBadRequest)
 	}
+
+	if params.ParentID != 0 {
+		if _, err := s.db.itemCategory(ctx, params.ParentID); err != nil {
+			if errors.Is(err, xerrors.ErrNotFound) {
+				return ItemCategoryParams{}, fmt.Errorf("%w: unknown parent category", xerrors.ErrBadRequest)
+			}
+			return ItemCategoryParams{}, err
+		}
+	}
+
+	if params.DefaultBaseUnitID != 0 {
+		if err := s.validateBaseUnit(ctx, params.DefaultBaseUnitID); err != nil {
+			return ItemCategoryParams{}, err
+		}
+	}
+
+	if params.DefaultTracking != "" {
+		if _, err := resolveTracking(params.DefaultTracking); err != nil {
+			return ItemCategoryParams{}, err
+		}
+	}
+
+	return params, nil
 }
 
-func (s Service) itemCategories(ctx context.Context) ([]ItemCategory, error) {
-	return s.db.itemCategories(ctx)
+// itemDefaults fills in the base unit and tracking mode of new items that do not set them from the category of the item
+// or, if the category does not set them either, from the closest ancestor that does.
+func (s Service) itemDefaults(ctx context.Context, params ItemParams) (ItemParams, error) {
+	ancestors, err := s.db.categoryAncestors(ctx, params.CategoryID)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return ItemParams{}, fmt.Errorf("%w: unknown category", xerrors.ErrBadRequest)
+	}
+	if err != nil {
+		return ItemParams{}, err
+	}
+
+	for _, category := range ancestors {
+		if params.BaseUnitID == 0 && category.DefaultBaseUnitID.Valid {
+			params.BaseUnitID = category.DefaultBaseUnitID.Int64
+		}
+		if params.Tracking == "" {
+			params.Tracking = category.DefaultTracking
+		}
+	}
+
+	if params.BaseUnitID == 0 {
+		return ItemParams{}, fmt.Errorf("%w: base unit is required, the category does not define a default", xerrors.ErrBadRequest)
+	}
+
+	return params, nil
 }
 
 func (s Service) item(ctx context.Context, id int64) (Item, error) {
@@ -27,41 +261,2896 @@ func (s Service) items(ctx context.Context, filter ItemFilter) ([]Item, error) {
 }
 
 func (s Service) createItem(ctx context.Context, params ItemParams) (Item, error) {
+	params, err := s.itemDefaults(ctx, params)
+	if err != nil {
+		return Item{}, err
+	}
+
+	if err := s.validateBaseUnit(ctx, params.BaseUnitID); err != nil {
+		return Item{}, err
+	}
+
+	if params.Tracking, err = resolveTracking(params.Tracking); err != nil {
+		return Item{}, err
+	}
+
+	if params.GTIN, err = s.validateGTIN(ctx, 0, params.GTIN); err != nil {
+		return Item{}, err
+	}
+
+	if params.Attributes, err = s.attributeValues(ctx, params.CategoryID, params.Attributes); err != nil {
+		return Item{}, err
+	}
+
+	if params, err = s.resolveItemPrices(ctx, params); err != nil {
+		return Item{}, err
+	}
+
 	return s.db.createItem(ctx, params)
 }
 
+// updateItem updates an item. The base unit and the tracking mode can not be changed once goods have been moved,
+// because movement quantities are stored in the base unit and batches and serial numbers are only captured for tracked
+// items.
 func (s Service) updateItem(ctx context.Context, id int64, params ItemParams) (Item, error) {
+	item, err := s.db.item(ctx, id)
+	if err != nil {
+		return Item{}, err
+	}
+
+	if params.Tracking, err = resolveTracking(params.Tracking); err != nil {
+		return Item{}, err
+	}
+
+	if params.CategoryID != item.CategoryID {
+		_, err := s.db.itemCategory(ctx, params.CategoryID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return Item{}, fmt.Errorf("%w: unknown category", xerrors.ErrBadRequest)
+		}
+		if err != nil {
+			return Item{}, err
+		}
+	}
+
+	if params.Tracking != item.Tracking {
+		moved, err := s.itemMoved(ctx, id)
+		if err != nil {
+			return Item{}, err
+		}
+		if moved {
+			return Item{}, fmt.Errorf("%w: the tracking mode can not be changed after goods have been moved", xerrors.ErrBadRequest)
+		}
+	}
+
+	if params.BaseUnitID != item.BaseUnitID {
+		if err := s.validateBaseUnit(ctx, params.BaseUnitID); err != nil {
+			return Item{}, err
+		}
+
+		moved, err := s.itemMoved(ctx, id)
+		if err != nil {
+			return Item{}, err
+		}
+		if moved {
+			return Item{}, fmt.Errorf("%w: the base unit can not be changed after goods have been moved", xerrors.ErrBadRequest)
+		}
+
+		if _, err := s.db.itemUnit(ctx, id, params.BaseUnitID); err == nil {
+			return Item{}, fmt.Errorf("%w: the base unit is already an alternative unit of the item", xerrors.ErrBadRequest)
+		}
+	}
+
+	if params.GTIN, err = s.validateGTIN(ctx, id, params.GTIN); err != nil {
+		return Item{}, err
+	}
+
+	if params.Attributes, err = s.attributeValues(ctx, params.CategoryID, params.Attributes); err != nil {
+		return Item{}, err
+	}
+
+	if params, err = s.resolveItemPrices(ctx, params); err != nil {
+		return Item{}, err
+	}
+
 	return s.db.updateItem(ctx, id, params)
 }
 
-func (s Service) address(ctx context.Context, id int64) (Address, error) {
-	return s.db.address(ctx, id)
+// resolveItemPrices validates the tax category and currency of an item and derives the price that was not entered
+// from the one that was, see deriveItemPrices.
+func (s Service) resolveItemPrices(ctx context.Context, params ItemParams) (ItemParams, error) {
+	category, err := s.db.taxCategory(ctx, params.TaxCategoryID)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return ItemParams{}, fmt.Errorf("%w: unknown tax category", xerrors.ErrBadRequest)
+	}
+	if err != nil {
+		return ItemParams{}, err
+	}
+
+	if params.Currency, err = normalizeCurrency(params.Currency); err != nil {
+		return ItemParams{}, err
+	}
+
+	increment, err := s.db.roundingIncrement(ctx, params.Currency)
+	if err != nil {
+		return ItemParams{}, err
+	}
+
+	return deriveItemPrices(params, category.Rate, increment)
 }
 
-func (s Service) addresses(ctx context.Context, filter AddressFilter) ([]Address, error) {
-	return s.db.addresses(ctx, filter)
+// deriveItemPrices derives the price of an item that was not entered from the one that was, see itemPrices. Params
+// without a price entry have to carry a net and a gross price that agree with each other.
+func deriveItemPrices(params ItemParams, rate float64, increment int64) (ItemParams, error) {
+	if params.NetPrice < 0 || params.GrossPrice < 0 {
+		return ItemParams{}, fmt.Errorf("%w: prices can not be negative", xerrors.ErrBadRequest)
+	}
+
+	basis := itemPriceBasis{
+		PriceEntry: params.PriceEntry,
+		NetPrice:   params.NetPrice,
+		GrossPrice: params.GrossPrice,
+		Rate:       rate,
+		Increment:  increment,
+	}
+
+	switch params.PriceEntry {
+	case PriceEntryNet, PriceEntryGross:
+		params.NetPrice, params.GrossPrice = itemPrices(basis)
+	case "":
+		for _, entry := range []string{PriceEntryNet, PriceEntryGross} {
+			basis.PriceEntry = entry
+			if net, gross := itemPrices(basis); net == params.NetPrice && gross == params.GrossPrice {
+				params.PriceEntry = entry
+				return params, nil
+			}
+		}
+
+		basis.PriceEntry = PriceEntryNet
+		_, gross := itemPrices(basis)
+		return ItemParams{}, fmt.Errorf("%w: the gross price does not match the net price at a tax rate of %v%%, it has to be %v",
+			xerrors.ErrBadRequest, rate, gross)
+	default:
+		return ItemParams{}, fmt.Errorf("%w: unknown price entry %q", xerrors.ErrBadRequest, params.PriceEntry)
+	}
+
+	return params, nil
 }
 
-func (s Service) createAddress(ctx context.Context, params AddressParams) (Address, error) {
-	return s.db.createAddress(ctx, params)
+// itemPrices derives the price of an item that was not entered from the entered one. Derived gross prices are rounded
+// half up to a multiple of the rounding increment of the currency, derived net prices to whole minor units. Rates are
+// used with a precision of a thousandth of a percent to keep the calculation in integers.
+func itemPrices(basis itemPriceBasis) (int64, int64) {
+	const scale = 100_000
+	factor := scale + int64(math.Round(basis.Rate*1000))
+
+	if basis.PriceEntry == PriceEntryGross {
+		return (basis.GrossPrice*scale + factor/2) / factor, basis.GrossPrice
+	}
+
+	increment := max(basis.Increment, 1)
+	divisor := scale * increment
+	return basis.NetPrice, (basis.NetPrice*factor + divisor/2) / divisor * increment
 }
 
-func (s Service) updateAddress(ctx context.Context, id int64, params AddressParams) (Address, error) {
-	return s.db.updateAddress(ctx, id, params)
+func (s Service) taxCategory(ctx context.Context, id int64) (TaxCategory, error) {
+	return s.db.taxCategory(ctx, id)
 }
 
-func (s Service) plant(ctx context.Context, id int64) (Plant, error) {
-	return s.db.plant(ctx, id)
+func (s Service) taxCategories(ctx context.Context, filter TaxCategoryFilter) ([]TaxCategory, error) {
+	return s.db.taxCategories(ctx, filter)
 }
 
-func (s Service) plants(ctx context.Context, filter PlantFilter) ([]Plant, error) {
-	return s.db.plants(ctx, filter)
+func (s Service) createTaxCategory(ctx context.Context, params TaxCategoryParams) (TaxCategory, error) {
+	params, err := validateTaxCategoryParams(params)
+	if err != nil {
+		return TaxCategory{}, err
+	}
+
+	return s.db.createTaxCategory(ctx, params)
 }
 
-func (s Service) createPlant(ctx context.Context, params PlantParams) (Plant, error) {
-	return s.db.createPlant(ctx, params)
+// updateTaxCategory updates a tax category, a changed rate recalculates the derived prices of all of its items.
+func (s Service) updateTaxCategory(ctx context.Context, id int64, params TaxCategoryParams) (TaxCategory, error) {
+	params, err := validateTaxCategoryParams(params)
+	if err != nil {
+		return TaxCategory{}, err
+	}
+
+	return s.db.updateTaxCategory(ctx, id, params)
 }
 
-func (s Service) updatePlant(ctx context.Context, id int64, params PlantParams) (Plant, error) {
-	return s.db.updatePlant(ctx, id, params)
+func validateTaxCategoryParams(params TaxCategoryParams) (TaxCategoryParams, error) {
+	if params.Name = strings.TrimSpace(params.Name); params.Name == "" {
+		return TaxCategoryParams{}, fmt.Errorf("%w: tax category name is required", xerrors.ErrBadRequest)
+	}
+
+	if params.Rate < 0 || params.Rate >= 1000 || math.IsNaN(params.Rate) {
+		return TaxCategoryParams{}, fmt.Errorf("%w: tax rate has to be a percentage between 0 and 999.999", xerrors.ErrBadRequest)
+	}
+
+	return params, nil
+}
+
+func (s Service) currencyRoundings(ctx context.Context, filter CurrencyRoundingFilter) ([]CurrencyRounding, error) {
+	return s.db.currencyRoundings(ctx, filter)
+}
+
+// setCurrencyRounding sets the rounding increment of a currency, the derived prices of all items in the currency are
+// recalculated.
+func (s Service) setCurrencyRounding(ctx context.Context, params CurrencyRoundingParams) (CurrencyRounding, error) {
+	var err error
+	if params.Currency, err = normalizeCurrency(params.Currency); err != nil {
+		return CurrencyRounding{}, err
+	}
+
+	if params.Increment <= 0 {
+		return CurrencyRounding{}, fmt.Errorf("%w: the rounding increment has to be at least one minor unit", xerrors.ErrBadRequest)
+	}
+
+	return s.db.setCurrencyRounding(ctx, params)
+}
+
+func (s Service) deleteCurrencyRounding(ctx context.Context, currency string) error {
+	return s.db.deleteCurrencyRounding(ctx, currency)
+}
+
+func (s Service) itemAttributeValues(ctx context.Context, itemID int64) ([]ItemAttributeValue, error) {
+	return s.db.itemAttributeValues(ctx, itemID)
+}
+
+// itemAttributes returns the attributes of a category including the attributes inherited from its ancestors, or all
+// attributes if categoryID is zero.
+func (s Service) itemAttributes(ctx context.Context, categoryID int64) ([]ItemAttribute, error) {
+	return s.db.itemAttributes(ctx, nullID(categoryID))
+}
+
+func (s Service) createItemAttribute(ctx context.Context, categoryID int64, params ItemAttributeParams) (ItemAttribute, error) {
+	if _, err := s.db.itemCategory(ctx, categoryID); err != nil {
+		return ItemAttribute{}, err
+	}
+
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return ItemAttribute{}, fmt.Errorf("%w: attribute name is required", xerrors.ErrBadRequest)
+	}
+
+	switch params.Type {
+	case AttributeEnum:
+		if len(params.optionList()) == 0 {
+			return ItemAttribute{}, fmt.Errorf("%w: enum attributes need at least one option", xerrors.ErrBadRequest)
+		}
+	case AttributeText, AttributeNumber, AttributeBoolean:
+		params.Options = ""
+	default:
+		return ItemAttribute{}, fmt.Errorf("%w: unknown attribute type %q", xerrors.ErrBadRequest, params.Type)
+	}
+
+	attributes, err := s.db.itemAttributes(ctx, nullID(categoryID))
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return ItemAttribute{}, err
+	}
+	for _, attribute := range attributes {
+		if strings.EqualFold(attribute.Name, params.Name) {
+			return ItemAttribute{}, fmt.Errorf("%w: the category already has an attribute %q", xerrors.ErrBadRequest, attribute.Name)
+		}
+	}
+
+	return s.db.createItemAttribute(ctx, categoryID, params)
+}
+
+// deleteItemAttribute deletes an attribute of a category, the values of all items are deleted with it.
+func (s Service) deleteItemAttribute(ctx context.Context, categoryID int64, id int64) error {
+	return s.db.deleteItemAttribute(ctx, categoryID, id)
+}
+
+// attributeValues validates the attribute values of an item of a category and returns them in canonical form. Values of
+// attributes that do not apply to the category, for example after the category of an item changed, are dropped, empty
+// values are not stored.
+func (s Service) attributeValues(ctx context.Context, categoryID int64, values map[int64]string) (map[int64]string, error) {
+	attributes, err := s.db.itemAttributes(ctx, nullID(categoryID))
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return validateAttributeValues(attributes, values)
+}
+
+func validateAttributeValues(attributes []ItemAttribute, values map[int64]string) (map[int64]string, error) {
+	valid := make(map[int64]string, len(attributes))
+	for _, attribute := range attributes {
+		value := strings.TrimSpace(values[attribute.ID])
+		if value == "" {
+			if attribute.Required {
+				return nil, fmt.Errorf("%w: %v is required", xerrors.ErrBadRequest, attribute.Name)
+			}
+			continue
+		}
+
+		switch attribute.Type {
+		case AttributeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+				return nil, fmt.Errorf("%w: %v has to be a number", xerrors.ErrBadRequest, attribute.Name)
+			}
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		case AttributeBoolean:
+			boolean, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v has to be yes or no", xerrors.ErrBadRequest, attribute.Name)
+			}
+			value = strconv.FormatBool(boolean)
+		case AttributeEnum:
+			if !slices.Contains(attribute.Options, value) {
+				return nil, fmt.Errorf("%w: %q is not an option of %v", xerrors.ErrBadRequest, value, attribute.Name)
+			}
+		}
+
+		valid[attribute.ID] = value
+	}
+
+	return valid, nil
+}
+
+// maxVariants limits the number of variants generated at once.
+const maxVariants = 100
+
+// createVariants generates a variant of an item for every combination of the selected options of its enum attributes.
+// Variants copy the item and its attribute values, their names and SKUs are suffixed with the options. Combinations
+// that already exist as a variant of the item are skipped.
+func (s Service) createVariants(ctx context.Context, itemID int64, params VariantParams) ([]Item, error) {
+	parent, err := s.db.item(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Variant() {
+		return nil, fmt.Errorf("%w: variants can not be generated from a variant", xerrors.ErrBadRequest)
+	}
+
+	attributes, err := s.db.itemAttributes(ctx, nullID(parent.CategoryID))
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	combinations, err := variantCombinations(attributes, params.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.db.itemAttributeValues(ctx, parent.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	items, err := s.db.items(ctx, ItemFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []ItemParams
+	for _, combination := range combinations {
+		variant := ItemParams{
+			Name:          parent.Name,
+			SKU:           parent.SKU,
+			CategoryID:    parent.CategoryID,
+			GrossPrice:    parent.GrossPrice,
+			NetPrice:      parent.NetPrice,
+			BaseUnitID:    parent.BaseUnitID,
+			Tracking:      parent.Tracking,
+			Attributes:    make(map[int64]string, len(values)+len(combination)),
+			TaxCategoryID: parent.TaxCategoryID,
+			Currency:      parent.Currency,
+			PriceEntry:    parent.PriceEntry,
+		}
+		for _, value := range values {
+			variant.Attributes[value.AttributeID] = value.Value
+		}
+
+		var options []string
+		for _, option := range combination {
+			variant.Attributes[option.AttributeID] = option.Value
+			options = append(options, option.Value)
+			variant.SKU += "-" + strings.ToUpper(strings.Join(strings.Fields(option.Value), ""))
+		}
+		variant.Name = fmt.Sprintf("%v (%v)", parent.Name, strings.Join(options, ", "))
+
+		if variant.Attributes, err = validateAttributeValues(attributes, variant.Attributes); err != nil {
+			return nil, err
+		}
+
+		exists := false
+		for _, item := range items {
+			if item.SKU != variant.SKU && item.Name != variant.Name {
+				continue
+			}
+			if item.ParentID.Valid && item.ParentID.Int64 == parent.ID {
+				exists = true
+				break
+			}
+			return nil, fmt.Errorf("%w: the name or SKU of variant %v is already used by item %v", xerrors.ErrBadRequest, variant.SKU, item.Name)
+		}
+		if !exists {
+			variants = append(variants, variant)
+		}
+	}
+
+	if len(variants) == 0 {
+		return nil, nil
+	}
+
+	return s.db.createVariants(ctx, parent.ID, variants)
+}
+
+// variantCombinations returns every combination of the selected options, one option per enum attribute, in the order
+// of the attributes.
+func variantCombinations(attributes []ItemAttribute, selected map[int64][]string) ([][]ItemAttributeValue, error) {
+	combinations := [][]ItemAttributeValue{nil}
+	for _, attribute := range attributes {
+		options := selected[attribute.ID]
+		if len(options) == 0 {
+			continue
+		}
+		if attribute.Type != AttributeEnum {
+			return nil, fmt.Errorf("%w: variants can only be generated from enum attributes", xerrors.ErrBadRequest)
+		}
+
+		var values []string
+		for _, option := range options {
+			if !slices.Contains(attribute.Options, option) {
+				return nil, fmt.Errorf("%w: %q is not an option of %v", xerrors.ErrBadRequest, option, attribute.Name)
+			}
+			if !slices.Contains(values, option) {
+				values = append(values, option)
+			}
+		}
+
+		if len(combinations)*len(values) > maxVariants {
+			return nil, fmt.Errorf("%w: at most %v variants can be generated at once", xerrors.ErrBadRequest, maxVariants)
+		}
+
+		var next [][]ItemAttributeValue
+		for _, combination := range combinations {
+			for _, value := range values {
+				next = append(next, append(slices.Clone(combination), ItemAttributeValue{AttributeID: attribute.ID, Value: value}))
+			}
+		}
+		combinations = next
+	}
+
+	for attributeID := range selected {
+		if !slices.ContainsFunc(attributes, func(attribute ItemAttribute) bool { return attribute.ID == attributeID }) {
+			return nil, fmt.Errorf("%w: the attribute does not belong to the category of the item", xerrors.ErrBadRequest)
+		}
+	}
+
+	if len(combinations[0]) == 0 {
+		return nil, fmt.Errorf("%w: select at least one option to generate variants", xerrors.ErrBadRequest)
+	}
+
+	return combinations, nil
+}
+
+// itemMoved reports whether any goods movement of the item exists.
+func (s Service) itemMoved(ctx context.Context, id int64) (bool, error) {
+	_, err := s.db.goodsMovements(ctx, GoodsMovementFilter{itemID: sql.NullInt64{Valid: true, Int64: id}})
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// validateGTIN normalizes the GTIN of the item with the given id, 0 for new items, and checks that no other item has
+// the same GTIN. Leading zeros only pad shorter GTINs, so GTINs are compared as GTIN-14.
+func (s Service) validateGTIN(ctx context.Context, id int64, gtin string) (string, error) {
+	gtin, err := normalizeGTIN(gtin)
+	if err != nil || gtin == "" {
+		return gtin, err
+	}
+
+	items, err := s.db.items(ctx, ItemFilter{gtin: sql.NullString{Valid: true, String: gtin}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return "", err
+	}
+	for _, item := range items {
+		if item.ID != id {
+			return "", fmt.Errorf("%w: GTIN %v is already used by item %q", xerrors.ErrBadRequest, gtin, item.Name)
+		}
+	}
+
+	return gtin, nil
+}
+
+// normalizeGTIN removes spaces and hyphens from a GTIN and validates its length and check digit.
+func normalizeGTIN(gtin string) (string, error) {
+	gtin = strings.NewReplacer(" ", "", "-", "").Replace(gtin)
+	if gtin == "" {
+		return "", nil
+	}
+
+	switch len(gtin) {
+	case 8, 12, 13, 14:
+	default:
+		return "", fmt.Errorf("%w: a GTIN has 8, 12, 13 or 14 digits", xerrors.ErrBadRequest)
+	}
+
+	check, err := barcode.CheckDigit(gtin[:len(gtin)-1])
+	if err != nil {
+		return "", fmt.Errorf("%w: a GTIN may only contain digits", xerrors.ErrBadRequest)
+	}
+	if gtin[len(gtin)-1] != check {
+		return "", fmt.Errorf("%w: invalid check digit of GTIN %v, expected %c", xerrors.ErrBadRequest, gtin, check)
+	}
+
+	return gtin, nil
+}
+
+// itemLabels encodes the barcodes of the items of a label sheet, every item is repeated for its copies. Items are
+// labeled in the order they are given.
+func (s Service) itemLabels(ctx context.Context, params ItemLabelParams) ([]ItemLabel, error) {
+	if len(params.ItemIDs) == 0 {
+		return nil, fmt.Errorf("%w: select at least one item to print labels", xerrors.ErrBadRequest)
+	}
+
+	switch {
+	case params.Copies == 0:
+		params.Copies = 1
+	case params.Copies < 0 || params.Copies > maxLabelCopies:
+		return nil, fmt.Errorf("%w: copies must be between 1 and %d", xerrors.ErrBadRequest, maxLabelCopies)
+	}
+
+	switch params.Content {
+	case LabelContentSKU, LabelContentGTIN:
+	default:
+		return nil, fmt.Errorf("%w: unknown label content %q", xerrors.ErrBadRequest, params.Content)
+	}
+
+	var labels []ItemLabel
+	var missingGTIN []string
+	for _, id := range params.ItemIDs {
+		item, err := s.db.item(ctx, id)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown item %d", xerrors.ErrBadRequest, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if params.Content == LabelContentGTIN && item.GTIN == "" {
+			missingGTIN = append(missingGTIN, item.Name)
+			continue
+		}
+
+		label, err := itemLabel(item, params.Barcode, params.Content)
+		if err != nil {
+			return nil, err
+		}
+		for range params.Copies {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(missingGTIN) > 0 {
+		return nil, fmt.Errorf("%w: items without GTIN: %v", xerrors.ErrBadRequest, strings.Join(missingGTIN, ", "))
+	}
+
+	return labels, nil
+}
+
+const maxLabelCopies = 100
+
+// itemLabel encodes the SKU or the GTIN of an item. GTINs are encoded as GS1 element string with application identifier
+// 01 in Code 128 and as GS1 Digital Link in QR codes, so scanners can tell them apart from SKUs. EAN-13 only encodes
+// GTIN-12 and GTIN-13.
+func itemLabel(item Item, symbology string, content string) (ItemLabel, error) {
+	label := ItemLabel{Item: item, Text: item.SKU}
+
+	var gtin14 string
+	if content == LabelContentGTIN {
+		var err error
+		if gtin14, err = barcode.GTIN14(item.GTIN); err != nil {
+			return ItemLabel{}, xerrors.Join(xerrors.ErrInternal, err)
+		}
+	}
+
+	var err error
+	switch {
+	case symbology == BarcodeCode128 && content == LabelContentSKU:
+		label.Bars, err = barcode.Code128(item.SKU)
+	case symbology == BarcodeCode128 && content == LabelContentGTIN:
+		label.Text = "(01) " + gtin14
+		label.Bars, err = barcode.GS1128("01" + gtin14)
+	case symbology == BarcodeEAN13 && content == LabelContentSKU:
+		return ItemLabel{}, fmt.Errorf("%w: EAN-13 barcodes can only encode GTINs", xerrors.ErrBadRequest)
+	case symbology == BarcodeEAN13 && content == LabelContentGTIN:
+		if len(item.GTIN) != 12 && len(item.GTIN) != 13 {
+			return ItemLabel{}, fmt.Errorf("%w: the GTIN of %q has %d digits, EAN-13 barcodes encode 12 or 13", xerrors.ErrBadRequest,
+				item.Name, len(item.GTIN))
+		}
+		label.Text = strings.Repeat("0", 13-len(item.GTIN)) + item.GTIN
+		label.Bars, err = barcode.EAN13(item.GTIN)
+	case symbology == BarcodeQR && content == LabelContentSKU:
+		label.Matrix, err = barcode.QR([]byte(item.SKU))
+	case symbology == BarcodeQR && content == LabelContentGTIN:
+		label.Text = item.GTIN
+		label.Matrix, err = barcode.QR([]byte("https://id.gs1.org/01/" + gtin14))
+	default:
+		return ItemLabel{}, fmt.Errorf("%w: unknown barcode %q", xerrors.ErrBadRequest, symbology)
+	}
+	if err != nil {
+		return ItemLabel{}, fmt.Errorf("%w: the %v of %q can not be encoded: %v", xerrors.ErrBadRequest, content, item.Name, err)
+	}
+
+	return label, nil
+}
+
+// resolveTracking validates the tracking mode of an item, items without one are not tracked.
+func resolveTracking(tracking string) (string, error) {
+	switch tracking {
+	case "":
+		return TrackingNone, nil
+	case TrackingNone, TrackingBatch, TrackingSerial:
+		return tracking, nil
+	default:
+		return "", fmt.Errorf("%w: unknown tracking mode %q", xerrors.ErrBadRequest, tracking)
+	}
+}
+
+func (s Service) validateBaseUnit(ctx context.Context, unitID int64) error {
+	if unitID == 0 {
+		return fmt.Errorf("%w: base unit is required", xerrors.ErrBadRequest)
+	}
+
+	_, err := s.db.unit(ctx, unitID)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return fmt.Errorf("%w: unknown base unit", xerrors.ErrBadRequest)
+	}
+
+	return err
+}
+
+func (s Service) unit(ctx context.Context, id int64) (Unit, error) {
+	return s.db.unit(ctx, id)
+}
+
+func (s Service) units(ctx context.Context, filter UnitFilter) ([]Unit, error) {
+	return s.db.units(ctx, filter)
+}
+
+func (s Service) createUnit(ctx context.Context, params UnitParams) (Unit, error) {
+	params, err := normalizeUnitParams(params)
+	if err != nil {
+		return Unit{}, err
+	}
+
+	return s.db.createUnit(ctx, params)
+}
+
+// updateUnit updates a unit. The number of decimals can only be increased, existing quantities would otherwise no longer
+// be valid in the unit.
+func (s Service) updateUnit(ctx context.Context, id int64, params UnitParams) (Unit, error) {
+	params, err := normalizeUnitParams(params)
+	if err != nil {
+		return Unit{}, err
+	}
+
+	unit, err := s.db.unit(ctx, id)
+	if err != nil {
+		return Unit{}, err
+	}
+
+	if params.Decimals < unit.Decimals {
+		return Unit{}, fmt.Errorf("%w: the number of decimals of a unit can not be reduced", xerrors.ErrBadRequest)
+	}
+
+	return s.db.updateUnit(ctx, id, params)
+}
+
+func normalizeUnitParams(params UnitParams) (UnitParams, error) {
+	params.Code = strings.ToUpper(strings.TrimSpace(params.Code))
+	params.Name = strings.TrimSpace(params.Name)
+
+	if params.Code == "" || params.Name == "" {
+		return UnitParams{}, fmt.Errorf("%w: unit code and name are required", xerrors.ErrBadRequest)
+	}
+
+	if params.Decimals < 0 || params.Decimals > 3 {
+		return UnitParams{}, fmt.Errorf("%w: units can have between 0 and 3 decimals", xerrors.ErrBadRequest)
+	}
+
+	return params, nil
+}
+
+func (s Service) itemUnits(ctx context.Context, itemID int64) ([]ItemUnit, error) {
+	return s.db.itemUnits(ctx, itemID)
+}
+
+func (s Service) setItemUnit(ctx context.Context, itemID int64, params ItemUnitParams) (ItemUnit, error) {
+	item, err := s.db.item(ctx, itemID)
+	if err != nil {
+		return ItemUnit{}, err
+	}
+
+	if params.UnitID == item.BaseUnitID {
+		return ItemUnit{}, fmt.Errorf("%w: the base unit can not be an alternative unit", xerrors.ErrBadRequest)
+	}
+
+	if params.Factor <= 0 {
+		return ItemUnit{}, fmt.Errorf("%w: conversion factor has to be positive", xerrors.ErrBadRequest)
+	}
+
+	if _, err := s.db.unit(ctx, params.UnitID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return ItemUnit{}, fmt.Errorf("%w: unknown unit", xerrors.ErrBadRequest)
+		}
+		return ItemUnit{}, err
+	}
+
+	return s.db.setItemUnit(ctx, itemID, params)
+}
+
+func (s Service) deleteItemUnit(ctx context.Context, itemID int64, unitID int64) error {
+	return s.db.deleteItemUnit(ctx, itemID, unitID)
+}
+
+func (s Service) bomLines(ctx context.Context, itemID int64) ([]BOMLine, error) {
+	return s.db.bomLines(ctx, sql.NullInt64{Valid: true, Int64: itemID})
+}
+
+// setBOMLine adds a component to the bill of materials of an item, the quantity is converted into the base unit of the
+// component.
+func (s Service) setBOMLine(ctx context.Context, itemID int64, params BOMLineParams) error {
+	if _, err := s.db.item(ctx, itemID); err != nil {
+		return err
+	}
+
+	if params.ComponentID == itemID {
+		return fmt.Errorf("%w: an item can not be a component of itself", xerrors.ErrBadRequest)
+	}
+
+	conversion, err := s.unitConversion(ctx, params.ComponentID, params.UnitID)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return fmt.Errorf("%w: unknown component", xerrors.ErrBadRequest)
+	}
+	if err != nil {
+		return err
+	}
+
+	quantity, err := conversion.ToBase(params.Quantity)
+	if err != nil {
+		return err
+	}
+
+	return s.db.setBOMLine(ctx, itemID, params.ComponentID, quantity)
+}
+
+func (s Service) deleteBOMLine(ctx context.Context, itemID int64, componentID int64) error {
+	return s.db.deleteBOMLine(ctx, itemID, componentID)
+}
+
+// bomExplosion explodes the bill of materials of quantity base units of an item over all levels. Required quantities
+// are rounded up to the decimals of the base units of the components.
+func (s Service) bomExplosion(ctx context.Context, itemID int64, quantity float64) (BOMExplosion, error) {
+	item, err := s.db.item(ctx, itemID)
+	if err != nil {
+		return BOMExplosion{}, err
+	}
+
+	items, err := s.db.items(ctx, ItemFilter{})
+	if err != nil {
+		return BOMExplosion{}, err
+	}
+
+	units, err := s.db.units(ctx, UnitFilter{})
+	if err != nil {
+		return BOMExplosion{}, err
+	}
+
+	lines, err := s.db.bomLines(ctx, sql.NullInt64{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return BOMExplosion{}, err
+	}
+
+	b := bom{
+		currency: item.Currency,
+		items:    make(map[int64]Item, len(items)),
+		units:    make(map[int64]Unit, len(units)),
+		lines:    make(map[int64][]BOMLine),
+		costs:    make(map[int64]float64),
+	}
+	for _, item := range items {
+		b.items[item.ID] = item
+	}
+	for _, unit := range units {
+		b.units[unit.ID] = unit
+	}
+	for _, line := range lines {
+		b.lines[line.ItemID] = append(b.lines[line.ItemID], line)
+	}
+
+	baseUnit := b.units[item.BaseUnitID]
+	if err := baseUnit.Validate(quantity); err != nil {
+		return BOMExplosion{}, err
+	}
+
+	explosion := BOMExplosion{Item: item, Quantity: quantity, UnitCode: baseUnit.Code}
+	requirements := make(map[int64]*ComponentRequirement)
+	if err := b.explode(&explosion, requirements, item.ID, quantity, 1, map[int64]bool{}); err != nil {
+		return BOMExplosion{}, err
+	}
+
+	for _, requirement := range requirements {
+		requirement.Cost = int64(math.Round(requirement.Quantity * float64(requirement.Component.NetPrice)))
+		explosion.Requirements = append(explosion.Requirements, *requirement)
+	}
+	slices.SortFunc(explosion.Requirements, func(a, b ComponentRequirement) int {
+		return cmp.Or(strings.Compare(a.Component.Name, b.Component.Name), cmp.Compare(a.Component.ID, b.Component.ID))
+	})
+
+	unitCost, err := b.unitCost(item.ID, map[int64]bool{})
+	if err != nil {
+		return BOMExplosion{}, err
+	}
+	explosion.Cost = int64(math.Round(quantity * unitCost))
+
+	return explosion, nil
+}
+
+// rolledUpCost returns the cost of one base unit of an item, see bomExplosion.
+func (s Service) rolledUpCost(ctx context.Context, itemID int64) (int64, error) {
+	explosion, err := s.bomExplosion(ctx, itemID, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return explosion.Cost, nil
+}
+
+// bom holds all bills of materials for explosions, lines are by item ID. Costs are in currency, the currency of the
+// exploded item.
+type bom struct {
+	currency string
+	items    map[int64]Item
+	units    map[int64]Unit
+	lines    map[int64][]BOMLine
+	// costs caches the rolled up costs of one base unit by item ID.
+	costs map[int64]float64
+}
+
+// explode appends the components of quantity of an item to the explosion depth first and adds the components that are
+// not assembled to requirements. path holds the items above the item and guards against cycles.
+func (b bom) explode(explosion *BOMExplosion, requirements map[int64]*ComponentRequirement, itemID int64, quantity float64, level int, path map[int64]bool) error {
+	if path[itemID] {
+		return fmt.Errorf("%w: the bill of materials of item %v is cyclic", xerrors.ErrInternal, b.items[itemID].Name)
+	}
+	path[itemID] = true
+	defer delete(path, itemID)
+
+	for _, line := range b.lines[itemID] {
+		component := b.items[line.ComponentID]
+		unit := b.units[component.BaseUnitID]
+		required := unit.RoundUp(quantity * line.Quantity)
+
+		unitCost, err := b.unitCost(component.ID, path)
+		if err != nil {
+			return err
+		}
+
+		_, assembly := b.lines[component.ID]
+		explosion.Lines = append(explosion.Lines, ExplosionLine{
+			Level:       level,
+			Component:   component,
+			QuantityPer: line.Quantity,
+			Quantity:    required,
+			UnitCode:    unit.Code,
+			Cost:        int64(math.Round(required * unitCost)),
+			Assembly:    assembly,
+		})
+
+		if assembly {
+			if err := b.explode(explosion, requirements, component.ID, required, level+1, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		requirement, ok := requirements[component.ID]
+		if !ok {
+			requirement = &ComponentRequirement{Component: component, UnitCode: unit.Code}
+			requirements[component.ID] = requirement
+		}
+		requirement.Quantity = unit.Round(requirement.Quantity + required)
+	}
+
+	return nil
+}
+
+// unitCost returns the cost of one base unit of an item, the net price for items without a bill of materials and the
+// sum of the costs of the components otherwise. Items without a bill of materials have to be priced in the currency of
+// the bill of materials.
+func (b bom) unitCost(itemID int64, path map[int64]bool) (float64, error) {
+	if cost, ok := b.costs[itemID]; ok {
+		return cost, nil
+	}
+
+	lines, ok := b.lines[itemID]
+	if !ok {
+		item := b.items[itemID]
+		if item.Currency != b.currency {
+			return 0, fmt.Errorf("%w: component %v is priced in %v, the bill of materials is costed in %v", xerrors.ErrBadRequest,
+				item.Name, item.Currency, b.currency)
+		}
+		return float64(item.NetPrice), nil
+	}
+
+	if path[itemID] {
+		return 0, fmt.Errorf("%w: the bill of materials of item %v is cyclic", xerrors.ErrInternal, b.items[itemID].Name)
+	}
+	path[itemID] = true
+	defer delete(path, itemID)
+
+	var cost float64
+	for _, line := range lines {
+		componentCost, err := b.unitCost(line.ComponentID, path)
+		if err != nil {
+			return 0, err
+		}
+		cost += line.Quantity * componentCost
+	}
+
+	b.costs[itemID] = cost
+	return cost, nil
+}
+
+// unitConversion returns the conversion of quantities of an item entered in a unit into its base unit. A unit ID of zero
+// stands for the base unit. Units that are neither the base unit nor an alternative unit of the item are rejected.
+func (s Service) unitConversion(ctx context.Context, itemID int64, unitID int64) (UnitConversion, error) {
+	item, err := s.db.item(ctx, itemID)
+	if err != nil {
+		return UnitConversion{}, err
+	}
+
+	baseUnit, err := s.db.unit(ctx, item.BaseUnitID)
+	if err != nil {
+		return UnitConversion{}, err
+	}
+
+	if unitID == 0 || unitID == item.BaseUnitID {
+		return UnitConversion{Unit: baseUnit, BaseUnit: baseUnit, Factor: 1}, nil
+	}
+
+	itemUnit, err := s.db.itemUnit(ctx, itemID, unitID)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return UnitConversion{}, fmt.Errorf("%w: the unit is not defined for item %v", xerrors.ErrBadRequest, item.Name)
+	}
+	if err != nil {
+		return UnitConversion{}, err
+	}
+
+	unit := Unit{ID: itemUnit.UnitID, Code: itemUnit.Code, Name: itemUnit.Name, Decimals: itemUnit.Decimals}
+	return UnitConversion{Unit: unit, BaseUnit: baseUnit, Factor: itemUnit.Factor}, nil
+}
+
+func (s Service) address(ctx context.Context, id int64) (Address, error) {
+	return s.db.address(ctx, id)
+}
+
+func (s Service) addresses(ctx context.Context, filter AddressFilter) ([]Address, error) {
+	return s.db.addresses(ctx, filter)
+}
+
+// createAddress creates a normalized address. If an equal address exists it returns a DuplicateAddressError, unless
+// params allow duplicates.
+func (s Service) createAddress(ctx context.Context, params AddressParams) (Address, error) {
+	params, err := s.normalizeAddress(ctx, params)
+	if err != nil {
+		return Address{}, err
+	}
+
+	if !params.AllowDuplicate {
+		existing, err := s.db.equalAddress(ctx, params)
+		if err == nil {
+			return Address{}, DuplicateAddressError{Existing: existing}
+		}
+		if !errors.Is(err, xerrors.ErrNotFound) {
+			return Address{}, err
+		}
+	}
+
+	return s.db.createAddress(ctx, s.geocodeAddress(ctx, params))
+}
+
+func (s Service) updateAddress(ctx context.Context, id int64, params AddressParams) (Address, error) {
+	params, err := s.normalizeAddress(ctx, params)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return s.db.updateAddress(ctx, id, s.geocodeAddress(ctx, params))
+}
+
+// normalizeAddress collapses whitespace, resolves the country to its ISO 3166 code and validates the postal code against
+// the pattern of the country. Postal codes are upper cased, cities and streets written in a single case are title
+// cased.
+func (s Service) normalizeAddress(ctx context.Context, params AddressParams) (AddressParams, error) {
+	params.ZIP = strings.ToUpper(strings.Join(strings.Fields(params.ZIP), " "))
+	params.City = normalizeCasing(strings.Join(strings.Fields(params.City), " "))
+	params.Street = normalizeCasing(strings.Join(strings.Fields(params.Street), " "))
+	params.Country = strings.Join(strings.Fields(params.Country), " ")
+
+	if params.City == "" || params.Street == "" {
+		return AddressParams{}, fmt.Errorf("%w: city and street are required", xerrors.ErrBadRequest)
+	}
+
+	if params.Country == "" {
+		return AddressParams{}, fmt.Errorf("%w: country is required", xerrors.ErrBadRequest)
+	}
+	country, err := s.db.countryByName(ctx, params.Country)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return AddressParams{}, fmt.Errorf("%w: unknown country %q, use an ISO 3166 code like DE", xerrors.ErrBadRequest, params.Country)
+	}
+	if err != nil {
+		return AddressParams{}, err
+	}
+	params.Country = country.Code
+
+	valid, err := validPostalCode(country, params.ZIP)
+	if err != nil {
+		return AddressParams{}, err
+	}
+	if !valid {
+		return AddressParams{}, fmt.Errorf("%w: %q is not a valid postal code in %v", xerrors.ErrBadRequest, params.ZIP, country.Name)
+	}
+
+	return params, nil
+}
+
+// validPostalCode reports whether zip matches the postal code pattern of country, any postal code is valid in countries
+// without a pattern.
+func validPostalCode(country Country, zip string) (bool, error) {
+	if country.PostalCodePattern == "" {
+		return true, nil
+	}
+
+	pattern, err := regexp.Compile("^(?:" + country.PostalCodePattern + ")$")
+	if err != nil {
+		return false, xerrors.Join(xerrors.ErrInternal, fmt.Errorf("invalid postal code pattern of %v: %w", country.Code, err))
+	}
+
+	return pattern.MatchString(zip), nil
+}
+
+// normalizeCasing title cases text written entirely in upper or lower case and keeps mixed case text as it is.
+func normalizeCasing(text string) string {
+	if strings.ToUpper(text) != text && strings.ToLower(text) != text {
+		return text
+	}
+
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+func (s Service) countries(ctx context.Context, filter CountryFilter) ([]Country, error) {
+	return s.db.countries(ctx, filter)
+}
+
+// geocodeAddress fills in the coordinates of addresses saved without them. Addresses the geocoder can not locate are
+// saved without coordinates, as the coordinates are only used for maps and distances.
+func (s Service) geocodeAddress(ctx context.Context, params AddressParams) AddressParams {
+	if params.Latitude != 0 || params.Longitude != 0 || s.geocoder == nil {
+		return params
+	}
+
+	query := GeocodeQuery{
+		Country: strings.TrimSpace(params.Country),
+		ZIP:     strings.TrimSpace(params.ZIP),
+		City:    strings.TrimSpace(params.City),
+	}
+	coordinates, err := s.geocoder.Geocode(ctx, query)
+	if err != nil {
+		if !errors.Is(err, xerrors.ErrNotFound) {
+			slog.Error("Unable to geocode address", "error", err)
+		}
+		return params
+	}
+
+	params.Latitude = coordinates.Latitude
+	params.Longitude = coordinates.Longitude
+	return params
+}
+
+func (s Service) postalCodeCountries(ctx context.Context) ([]PostalCodeCountry, error) {
+	return s.db.postalCodeCountries(ctx)
+}
+
+// importPostalCodes imports a GeoNames postal code dump and returns the number of imported places. The postal codes of
+// the countries in the dump are replaced.
+func (s Service) importPostalCodes(ctx context.Context, dump io.Reader) (int, error) {
+	codes, err := parsePostalCodes(dump)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(codes) == 0 {
+		return 0, fmt.Errorf("%w: the file contains no postal codes", xerrors.ErrBadRequest)
+	}
+
+	return len(codes), s.db.importPostalCodes(ctx, codes)
+}
+
+// parsePostalCodes reads the tab separated GeoNames postal code format, see
+// https://download.geonames.org/export/zip/readme.txt. Places listed twice for a postal code are only kept once.
+func parsePostalCodes(dump io.Reader) ([]PostalCode, error) {
+	const (
+		countryColumn   = 0
+		zipColumn       = 1
+		placeColumn     = 2
+		latitudeColumn  = 9
+		longitudeColumn = 10
+	)
+
+	reader := csv.NewReader(dump)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	type key struct{ country, zip, place string }
+	seen := make(map[key]bool)
+
+	codes := make([]PostalCode, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read the postal codes: %v", xerrors.ErrBadRequest, err)
+		}
+
+		row, _ := reader.FieldPos(0)
+		if len(record) <= longitudeColumn {
+			return nil, fmt.Errorf("%w: row %v of the postal codes is incomplete", xerrors.ErrBadRequest, row)
+		}
+
+		code := PostalCode{
+			Country: strings.ToUpper(strings.TrimSpace(record[countryColumn])),
+			ZIP:     strings.TrimSpace(record[zipColumn]),
+			Place:   strings.TrimSpace(record[placeColumn]),
+		}
+		if len(code.Country) != 2 || code.ZIP == "" || utf8.RuneCountInString(code.ZIP) > 20 || utf8.RuneCountInString(code.Place) > 180 {
+			return nil, fmt.Errorf("%w: row %v of the postal codes has no valid country, postal code or place", xerrors.ErrBadRequest, row)
+		}
+
+		if code.Latitude, err = strconv.ParseFloat(strings.TrimSpace(record[latitudeColumn]), 64); err != nil || math.Abs(code.Latitude) > 90 {
+			return nil, fmt.Errorf("%w: row %v of the postal codes has no valid latitude", xerrors.ErrBadRequest, row)
+		}
+		if code.Longitude, err = strconv.ParseFloat(strings.TrimSpace(record[longitudeColumn]), 64); err != nil || math.Abs(code.Longitude) > 180 {
+			return nil, fmt.Errorf("%w: row %v of the postal codes has no valid longitude", xerrors.ErrBadRequest, row)
+		}
+
+		k := key{code.Country, code.ZIP, code.Place}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+func (s Service) plantLocations(ctx context.Context) ([]PlantLocation, error) {
+	return s.db.plantLocations(ctx)
+}
+
+// earthRadius is the mean radius of the earth in kilometers.
+const earthRadius = 6371.0088
+
+// maxDistance is the largest great-circle distance in kilometers, half the circumference of the earth.
+const maxDistance = math.Pi * earthRadius
+
+// maxNearestPlants is the largest number of plants nearestPlants returns.
+const maxNearestPlants = 100
+
+// distance returns the great-circle distance between two positions in kilometers, see the haversine formula.
+func distance(from, to Coordinates) float64 {
+	lat1, lat2 := from.Latitude*math.Pi/180, to.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(min(h, 1)))
+}
+
+// boundingBox returns a box containing all positions within radius kilometers of center. Boxes reaching a pole span
+// all longitudes.
+func boundingBox(center Coordinates, radius float64) BoundingBox {
+	angle := radius / earthRadius * 180 / math.Pi
+	box := BoundingBox{
+		MinLatitude:  center.Latitude - angle,
+		MaxLatitude:  center.Latitude + angle,
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 {
+		box.MinLatitude, box.MaxLatitude = max(box.MinLatitude, -90), min(box.MaxLatitude, 90)
+		return box
+	}
+
+	// The longitudes of the box are widest at the latitude touching the circle, see
+	// http://janmatuschek.de/LatitudeLongitudeBoundingCoordinates.
+	spread := math.Asin(math.Sin(radius/earthRadius)/math.Cos(center.Latitude*math.Pi/180)) * 180 / math.Pi
+	if radius >= maxDistance/2 || math.IsNaN(spread) {
+		return box
+	}
+
+	box.MinLongitude, box.MaxLongitude = center.Longitude-spread, center.Longitude+spread
+	if box.MinLongitude < -180 {
+		box.MinLongitude += 360
+	}
+	if box.MaxLongitude > 180 {
+		box.MaxLongitude -= 360
+	}
+	return box
+}
+
+func validateCoordinates(coordinates Coordinates) error {
+	if math.Abs(coordinates.Latitude) > 90 || math.Abs(coordinates.Longitude) > 180 ||
+		math.IsNaN(coordinates.Latitude) || math.IsNaN(coordinates.Longitude) {
+		return fmt.Errorf("%w: latitude has to be between -90 and 90, longitude between -180 and 180", xerrors.ErrBadRequest)
+	}
+
+	return nil
+}
+
+// addressCoordinates returns the coordinates of an address, addresses without coordinates can not be used for
+// distances.
+func (s Service) addressCoordinates(ctx context.Context, id int64) (Coordinates, error) {
+	address, err := s.db.address(ctx, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return Coordinates{}, fmt.Errorf("%w: unknown address", xerrors.ErrBadRequest)
+	}
+	if err != nil {
+		return Coordinates{}, err
+	}
+
+	if address.Latitude == 0 && address.Longitude == 0 {
+		return Coordinates{}, fmt.Errorf("%w: the address has no coordinates", xerrors.ErrBadRequest)
+	}
+
+	return Coordinates{Latitude: address.Latitude, Longitude: address.Longitude}, nil
+}
+
+// plantsWithin returns the plants within radius kilometers of center, nearest first.
+func (s Service) plantsWithin(ctx context.Context, center Coordinates, radius float64) ([]PlantDistance, error) {
+	if err := validateCoordinates(center); err != nil {
+		return nil, err
+	}
+
+	if !(radius > 0) {
+		return nil, fmt.Errorf("%w: radius has to be greater than zero", xerrors.ErrBadRequest)
+	}
+
+	return s.plantDistances(ctx, center, min(radius, maxDistance))
+}
+
+// nearestPlants returns the limit plants nearest to center, nearest first. The radius searched is widened until it
+// contains enough plants, so only plants around center have to be queried.
+func (s Service) nearestPlants(ctx context.Context, center Coordinates, limit int) ([]PlantDistance, error) {
+	if err := validateCoordinates(center); err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > maxNearestPlants {
+		return nil, fmt.Errorf("%w: the number of plants has to be between 1 and %v", xerrors.ErrBadRequest, maxNearestPlants)
+	}
+
+	for radius := 50.0; ; radius *= 4 {
+		radius = min(radius, maxDistance)
+
+		plants, err := s.plantDistances(ctx, center, radius)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(plants) >= limit {
+			return plants[:limit], nil
+		}
+		if radius == maxDistance {
+			return plants, nil
+		}
+	}
+}
+
+// plantDistances returns the plants within radius kilometers of center, nearest first. Only the plants in the bounding
+// box of the radius are queried and measured.
+func (s Service) plantDistances(ctx context.Context, center Coordinates, radius float64) ([]PlantDistance, error) {
+	locations, err := s.db.plantLocationsInBox(ctx, boundingBox(center, radius))
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	plants := make([]PlantDistance, 0, len(locations))
+	for _, location := range locations {
+		d := distance(center, Coordinates{Latitude: location.Latitude, Longitude: location.Longitude})
+		if d <= radius {
+			plants = append(plants, PlantDistance{PlantLocation: location, Distance: d})
+		}
+	}
+
+	slices.SortStableFunc(plants, func(a, b PlantDistance) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	return plants, nil
+}
+
+func (s Service) plant(ctx context.Context, id int64) (Plant, error) {
+	return s.db.plant(ctx, id)
+}
+
+func (s Service) plants(ctx context.Context, filter PlantFilter) ([]Plant, error) {
+	return s.db.plants(ctx, filter)
+}
+
+func (s Service) createPlant(ctx context.Context, params PlantParams) (Plant, error) {
+	return s.db.createPlant(ctx, params)
+}
+
+func (s Service) updatePlant(ctx context.Context, id int64, params PlantParams) (Plant, error) {
+	return s.db.updatePlant(ctx, id, params)
+}
+
+func (s Service) customer(ctx context.Context, id int64) (Customer, error) {
+	return s.db.customer(ctx, id)
+}
+
+func (s Service) customers(ctx context.Context, filter CustomerFilter) ([]Customer, error) {
+	return s.db.customers(ctx, filter)
+}
+
+func (s Service) createCustomer(ctx context.Context, params CustomerParams) (Customer, error) {
+	if err := s.validateCustomerGroup(ctx, params.GroupID); err != nil {
+		return Customer{}, err
+	}
+
+	return s.db.createCustomer(ctx, params)
+}
+
+func (s Service) updateCustomer(ctx context.Context, id int64, params CustomerParams) (Customer, error) {
+	if err := s.validateCustomerGroup(ctx, params.GroupID); err != nil {
+		return Customer{}, err
+	}
+
+	return s.db.updateCustomer(ctx, id, params)
+}
+
+// validateCustomerGroup checks that a customer group exists, zero stands for no group.
+func (s Service) validateCustomerGroup(ctx context.Context, id int64) error {
+	if id == 0 {
+		return nil
+	}
+
+	_, err := s.db.customerGroup(ctx, id)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return fmt.Errorf("%w: unknown customer group", xerrors.ErrBadRequest)
+	}
+
+	return err
+}
+
+func (s Service) customerGroup(ctx context.Context, id int64) (CustomerGroup, error) {
+	return s.db.customerGroup(ctx, id)
+}
+
+func (s Service) customerGroups(ctx context.Context, filter CustomerGroupFilter) ([]CustomerGroup, error) {
+	return s.db.customerGroups(ctx, filter)
+}
+
+func (s Service) createCustomerGroup(ctx context.Context, params CustomerGroupParams) (CustomerGroup, error) {
+	if params.Name = strings.TrimSpace(params.Name); params.Name == "" {
+		return CustomerGroup{}, fmt.Errorf("%w: customer group name is required", xerrors.ErrBadRequest)
+	}
+
+	return s.db.createCustomerGroup(ctx, params)
+}
+
+func (s Service) updateCustomerGroup(ctx context.Context, id int64, params CustomerGroupParams) (CustomerGroup, error) {
+	if params.Name = strings.TrimSpace(params.Name); params.Name == "" {
+		return CustomerGroup{}, fmt.Errorf("%w: customer group name is required", xerrors.ErrBadRequest)
+	}
+
+	return s.db.updateCustomerGroup(ctx, id, params)
+}
+
+func (s Service) priceList(ctx context.Context, id int64) (PriceList, error) {
+	return s.db.priceList(ctx, id)
+}
+
+func (s Service) priceLists(ctx context.Context, filter PriceListFilter) ([]PriceList, error) {
+	return s.db.priceLists(ctx, filter)
+}
+
+func (s Service) createPriceList(ctx context.Context, params PriceListParams) (PriceList, error) {
+	params, err := s.validatePriceListParams(ctx, params)
+	if err != nil {
+		return PriceList{}, err
+	}
+
+	return s.db.createPriceList(ctx, params)
+}
+
+func (s Service) updatePriceList(ctx context.Context, id int64, params PriceListParams) (PriceList, error) {
+	params, err := s.validatePriceListParams(ctx, params)
+	if err != nil {
+		return PriceList{}, err
+	}
+
+	return s.db.updatePriceList(ctx, id, params)
+}
+
+func (s Service) validatePriceListParams(ctx context.Context, params PriceListParams) (PriceListParams, error) {
+	if params.Name = strings.TrimSpace(params.Name); params.Name == "" {
+		return PriceListParams{}, fmt.Errorf("%w: price list name is required", xerrors.ErrBadRequest)
+	}
+
+	var err error
+	if params.Currency, err = normalizeCurrency(params.Currency); err != nil {
+		return PriceListParams{}, err
+	}
+
+	for _, date := range []string{params.ValidFrom, params.ValidTo} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return PriceListParams{}, fmt.Errorf("%w: validity dates have to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+		}
+	}
+	if params.ValidFrom != "" && params.ValidTo != "" && params.ValidTo < params.ValidFrom {
+		return PriceListParams{}, fmt.Errorf("%w: the list can not be valid to a date before it is valid from", xerrors.ErrBadRequest)
+	}
+
+	if params.CustomerGroupID != 0 && params.CustomerID != 0 {
+		return PriceListParams{}, fmt.Errorf("%w: a price list applies to a customer group or a customer, not both", xerrors.ErrBadRequest)
+	}
+	if err := s.validateCustomerGroup(ctx, params.CustomerGroupID); err != nil {
+		return PriceListParams{}, err
+	}
+	if params.CustomerID != 0 {
+		if _, err := s.db.customer(ctx, params.CustomerID); err != nil {
+			if errors.Is(err, xerrors.ErrNotFound) {
+				return PriceListParams{}, fmt.Errorf("%w: unknown customer", xerrors.ErrBadRequest)
+			}
+			return PriceListParams{}, err
+		}
+	}
+
+	return params, nil
+}
+
+func (s Service) prices(ctx context.Context, priceListID int64) ([]Price, error) {
+	return s.db.prices(ctx, priceListID)
+}
+
+func (s Service) setPrice(ctx context.Context, priceListID int64, params PriceParams) (Price, error) {
+	if _, err := s.db.priceList(ctx, priceListID); err != nil {
+		return Price{}, err
+	}
+
+	if _, err := s.db.item(ctx, params.ItemID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return Price{}, fmt.Errorf("%w: unknown item", xerrors.ErrBadRequest)
+		}
+		return Price{}, err
+	}
+
+	if params.MinQuantity < 0 {
+		return Price{}, fmt.Errorf("%w: minimum quantity can not be negative", xerrors.ErrBadRequest)
+	}
+	if params.Price < 0 {
+		return Price{}, fmt.Errorf("%w: price can not be negative", xerrors.ErrBadRequest)
+	}
+
+	return s.db.setPrice(ctx, priceListID, params)
+}
+
+func (s Service) deletePrice(ctx context.Context, priceListID int64, id int64) error {
+	return s.db.deletePrice(ctx, priceListID, id)
+}
+
+// normalizeCurrency validates a three letter ISO currency code and returns it in upper case.
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w: currency has to be a three letter ISO code", xerrors.ErrBadRequest)
+	}
+
+	return currency, nil
+}
+
+// Price determines the net price per base unit of an item for a quantity, customer and date. Prices of the customer
+// take precedence over prices of its customer group, which take precedence over general prices. Among the lists of the
+// same kind the list valid from the latest date wins, within the list the highest quantity scale that the quantity
+// reaches. Only lists in the currency asked for apply, which defaults to the currency of the item. Without any list
+// price the net price of the item is used, unless the item is priced in another currency.
+func (s Service) Price(ctx context.Context, query PriceQuery) (EffectivePrice, error) {
+	if _, err := time.Parse(time.DateOnly, query.Date); err != nil {
+		return EffectivePrice{}, fmt.Errorf("%w: date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if query.Quantity < 0 {
+		return EffectivePrice{}, fmt.Errorf("%w: quantity can not be negative", xerrors.ErrBadRequest)
+	}
+
+	item, err := s.db.item(ctx, query.ItemID)
+	if err != nil {
+		return EffectivePrice{}, err
+	}
+
+	if query.Currency == "" {
+		query.Currency = item.Currency
+	}
+	if query.Currency, err = normalizeCurrency(query.Currency); err != nil {
+		return EffectivePrice{}, err
+	}
+
+	var groupID sql.NullInt64
+	if query.CustomerID != 0 {
+		customer, err := s.db.customer(ctx, query.CustomerID)
+		if err != nil {
+			return EffectivePrice{}, err
+		}
+		groupID = customer.GroupID
+	}
+
+	candidates, err := s.db.priceCandidates(ctx, query, groupID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return EffectivePrice{}, err
+	}
+
+	candidate, ok := bestPrice(candidates)
+	if !ok {
+		if item.Currency != query.Currency {
+			return EffectivePrice{}, fmt.Errorf("%w: %v has no price in %v", xerrors.ErrBadRequest, item.Name, query.Currency)
+		}
+		return EffectivePrice{ItemID: item.ID, Quantity: query.Quantity, Price: item.NetPrice, Source: PriceSourceItem,
+			Currency: item.Currency}, nil
+	}
+
+	return EffectivePrice{
+		ItemID:      item.ID,
+		Quantity:    query.Quantity,
+		Price:       candidate.Price,
+		Source:      candidate.source(),
+		PriceListID: sql.NullInt64{Valid: true, Int64: candidate.PriceListID},
+		Currency:    candidate.Currency,
+		MinQuantity: candidate.MinQuantity,
+	}, nil
+}
+
+// priceSourceRanks orders price sources from the most to the least specific.
+var priceSourceRanks = map[string]int{
+	PriceSourceCustomer:      0,
+	PriceSourceCustomerGroup: 1,
+	PriceSourceGeneral:       2,
+}
+
+// bestPrice picks the price that applies out of the candidates, see Price.
+func bestPrice(candidates []priceCandidate) (priceCandidate, bool) {
+	if len(candidates) == 0 {
+		return priceCandidate{}, false
+	}
+
+	return slices.MinFunc(candidates, func(a, b priceCandidate) int {
+		return cmp.Or(
+			cmp.Compare(priceSourceRanks[a.source()], priceSourceRanks[b.source()]),
+			strings.Compare(b.ValidFrom, a.ValidFrom),
+			cmp.Compare(b.PriceListID, a.PriceListID),
+			cmp.Compare(b.MinQuantity, a.MinQuantity),
+		)
+	}), true
+}
+
+func (s Service) goodsMovement(ctx context.Context, id int64) (GoodsMovement, error) {
+	return s.db.goodsMovement(ctx, id)
+}
+
+func (s Service) goodsMovements(ctx context.Context, filter GoodsMovementFilter) ([]GoodsMovement, error) {
+	return s.db.goodsMovements(ctx, filter)
+}
+
+func (s Service) createGoodsMovement(ctx context.Context, params GoodsMovementParams) (GoodsMovement, error) {
+	params.Reference = strings.TrimSpace(params.Reference)
+	params.BatchNumber = strings.TrimSpace(params.BatchNumber)
+
+	if err := validateGoodsMovementParams(params); err != nil {
+		return GoodsMovement{}, err
+	}
+
+	conversion, err := s.unitConversion(ctx, params.ItemID, params.UnitID)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+	if params.Quantity, err = conversion.ToBase(params.Quantity); err != nil {
+		return GoodsMovement{}, err
+	}
+
+	bins := []struct {
+		binID   int64
+		plantID int64
+	}{
+		{params.FromBinID, params.FromPlantID},
+		{params.ToBinID, params.ToPlantID},
+	}
+	for _, b := range bins {
+		if b.binID == 0 {
+			continue
+		}
+
+		bin, err := s.db.bin(ctx, b.binID)
+		if err != nil {
+			return GoodsMovement{}, err
+		}
+		if bin.PlantID != b.plantID {
+			return GoodsMovement{}, fmt.Errorf("%w: bin %v does not belong to the selected plant", xerrors.ErrBadRequest, bin.Name)
+		}
+	}
+
+	movement, err := s.db.createGoodsMovement(ctx, params)
+	if err != nil {
+		return GoodsMovement{}, err
+	}
+
+	s.movementsRecorded(ctx)
+	return movement, nil
+}
+
+func validateGoodsMovementParams(params GoodsMovementParams) error {
+	if _, err := time.Parse(time.DateOnly, params.Date); err != nil {
+		return fmt.Errorf("%w: movement date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if params.ItemID == 0 {
+		return fmt.Errorf("%w: movement item is required", xerrors.ErrBadRequest)
+	}
+
+	if params.Quantity <= 0 {
+		return fmt.Errorf("%w: movement quantity has to be positive", xerrors.ErrBadRequest)
+	}
+
+	from, to := params.FromPlantID != 0, params.ToPlantID != 0
+	switch params.Type {
+	case MovementReceipt:
+		if from || !to {
+			return fmt.Errorf("%w: receipts need a destination plant only", xerrors.ErrBadRequest)
+		}
+	case MovementIssue:
+		if !from || to {
+			return fmt.Errorf("%w: issues need a source plant only", xerrors.ErrBadRequest)
+		}
+	case MovementTransfer:
+		if !from || !to || (params.FromPlantID == params.ToPlantID && params.FromBinID == params.ToBinID) {
+			return fmt.Errorf("%w: transfers need two different plants or bins", xerrors.ErrBadRequest)
+		}
+	case MovementAdjustment:
+		if from == to {
+			return fmt.Errorf("%w: adjustments need either a source plant to decrease or a destination plant to increase stock", xerrors.ErrBadRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown movement type %q", xerrors.ErrBadRequest, params.Type)
+	}
+
+	if (params.FromBinID != 0 && !from) || (params.ToBinID != 0 && !to) {
+		return fmt.Errorf("%w: bins can only be selected together with their plant", xerrors.ErrBadRequest)
+	}
+
+	return validateTrackingParams(params.ManufactureDate, params.ExpiryDate, params.serialNumbers())
+}
+
+// validateTrackingParams checks the optional batch dates and that no serial number is given twice.
+func validateTrackingParams(manufactureDate string, expiryDate string, serialNumbers []string) error {
+	if manufactureDate != "" {
+		if _, err := time.Parse(time.DateOnly, manufactureDate); err != nil {
+			return fmt.Errorf("%w: manufacture date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+		}
+	}
+
+	if expiryDate != "" {
+		if _, err := time.Parse(time.DateOnly, expiryDate); err != nil {
+			return fmt.Errorf("%w: expiry date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+		}
+	}
+
+	if manufactureDate != "" && expiryDate != "" && expiryDate < manufactureDate {
+		return fmt.Errorf("%w: expiry date can not be before the manufacture date", xerrors.ErrBadRequest)
+	}
+
+	seen := make(map[string]bool, len(serialNumbers))
+	for _, number := range serialNumbers {
+		if seen[number] {
+			return fmt.Errorf("%w: serial number %v is given twice", xerrors.ErrBadRequest, number)
+		}
+		seen[number] = true
+	}
+
+	return nil
+}
+
+func (s Service) stock(ctx context.Context, filter StockFilter) ([]Stock, error) {
+	return s.db.stock(ctx, filter)
+}
+
+func (s Service) storageLocation(ctx context.Context, id int64) (StorageLocation, error) {
+	return s.db.storageLocation(ctx, id)
+}
+
+func (s Service) storageLocations(ctx context.Context, filter StorageLocationFilter) ([]StorageLocation, error) {
+	return s.db.storageLocations(ctx, filter)
+}
+
+func (s Service) createStorageLocation(ctx context.Context, params StorageLocationParams) (StorageLocation, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return StorageLocation{}, fmt.Errorf("%w: storage location name is required", xerrors.ErrBadRequest)
+	}
+
+	return s.db.createStorageLocation(ctx, params)
+}
+
+func (s Service) updateStorageLocation(ctx context.Context, id int64, params StorageLocationParams) (StorageLocation, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return StorageLocation{}, fmt.Errorf("%w: storage location name is required", xerrors.ErrBadRequest)
+	}
+
+	return s.db.updateStorageLocation(ctx, id, params)
+}
+
+func (s Service) bin(ctx context.Context, id int64) (Bin, error) {
+	return s.db.bin(ctx, id)
+}
+
+func (s Service) bins(ctx context.Context, filter BinFilter) ([]Bin, error) {
+	return s.db.bins(ctx, filter)
+}
+
+func (s Service) createBin(ctx context.Context, params BinParams) (Bin, error) {
+	params, err := s.validateBinParams(ctx, params)
+	if err != nil {
+		return Bin{}, err
+	}
+
+	return s.db.createBin(ctx, params)
+}
+
+// updateBin updates a bin. A bin can only be limited if all of its stock can be converted to the capacity unit.
+func (s Service) updateBin(ctx context.Context, id int64, params BinParams) (Bin, error) {
+	params, err := s.validateBinParams(ctx, params)
+	if err != nil {
+		return Bin{}, err
+	}
+
+	if params.Capacity > 0 {
+		load, err := s.db.binLoad(ctx, id, params.CapacityUnitID)
+		if err != nil {
+			return Bin{}, err
+		}
+		if len(load.Unconvertible) > 0 {
+			return Bin{}, fmt.Errorf("%w: the bin holds items that can not be converted to the capacity unit: %v",
+				xerrors.ErrBadRequest, strings.Join(load.Unconvertible, ", "))
+		}
+	}
+
+	return s.db.updateBin(ctx, id, params)
+}
+
+// binLoad returns the stock of a limited bin in its capacity unit.
+func (s Service) binLoad(ctx context.Context, bin Bin) (BinLoad, error) {
+	return s.db.binLoad(ctx, bin.ID, bin.CapacityUnitID.Int64)
+}
+
+// validateBinParams requires a capacity unit for limited bins, unlimited bins have none.
+func (s Service) validateBinParams(ctx context.Context, params BinParams) (BinParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return BinParams{}, fmt.Errorf("%w: bin name is required", xerrors.ErrBadRequest)
+	}
+
+	switch {
+	case params.Capacity < 0:
+		return BinParams{}, fmt.Errorf("%w: bin capacity can not be negative", xerrors.ErrBadRequest)
+	case params.Capacity == 0:
+		params.CapacityUnitID = 0
+	case params.CapacityUnitID == 0:
+		return BinParams{}, fmt.Errorf("%w: limited bins need a capacity unit", xerrors.ErrBadRequest)
+	default:
+		_, err := s.db.unit(ctx, params.CapacityUnitID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return BinParams{}, fmt.Errorf("%w: unknown capacity unit", xerrors.ErrBadRequest)
+		}
+		if err != nil {
+			return BinParams{}, err
+		}
+	}
+
+	return params, nil
+}
+
+func (s Service) supplier(ctx context.Context, id int64) (Supplier, error) {
+	return s.db.supplier(ctx, id)
+}
+
+func (s Service) suppliers(ctx context.Context, filter SupplierFilter) ([]Supplier, error) {
+	return s.db.suppliers(ctx, filter)
+}
+
+func (s Service) createSupplier(ctx context.Context, params SupplierParams) (Supplier, error) {
+	return s.db.createSupplier(ctx, params)
+}
+
+func (s Service) updateSupplier(ctx context.Context, id int64, params SupplierParams) (Supplier, error) {
+	return s.db.updateSupplier(ctx, id, params)
+}
+
+func (s Service) purchaseOrder(ctx context.Context, id int64) (PurchaseOrder, error) {
+	return s.db.purchaseOrder(ctx, id)
+}
+
+func (s Service) purchaseOrders(ctx context.Context, filter PurchaseOrderFilter) ([]PurchaseOrderHeader, error) {
+	return s.db.purchaseOrders(ctx, filter)
+}
+
+func (s Service) createPurchaseOrder(ctx context.Context, params PurchaseOrderParams) (PurchaseOrder, error) {
+	lines, err := s.resolvePurchaseOrder(ctx, &params)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	return s.db.createPurchaseOrder(ctx, params.PurchaseOrderHeaderParams, lines)
+}
+
+func (s Service) updatePurchaseOrder(ctx context.Context, id int64, params PurchaseOrderParams) (PurchaseOrder, error) {
+	lines, err := s.resolvePurchaseOrder(ctx, &params)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	return s.db.updatePurchaseOrder(ctx, id, params.PurchaseOrderHeaderParams, lines)
+}
+
+// resolvePurchaseOrder validates the params, converts line quantities into the base unit of the items and fills in
+// default prices.
+func (s Service) resolvePurchaseOrder(ctx context.Context, params *PurchaseOrderParams) ([]orderLineValues, error) {
+	date, err := time.Parse(time.DateOnly, params.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	deliveryDate, err := time.Parse(time.DateOnly, params.DeliveryDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: delivery date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if deliveryDate.Before(date) {
+		return nil, fmt.Errorf("%w: delivery date can not be before the order date", xerrors.ErrBadRequest)
+	}
+
+	if _, err := s.db.supplier(ctx, params.SupplierID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown supplier", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if _, err := s.db.plant(ctx, params.PlantID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown delivery plant", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if params.Currency, err = normalizeCurrency(params.Currency); err != nil {
+		return nil, err
+	}
+
+	if len(params.Lines) == 0 {
+		return nil, fmt.Errorf("%w: purchase order needs at least one line", xerrors.ErrBadRequest)
+	}
+
+	// Lines without a price default to the net price of items priced in the currency of the order.
+	return s.resolveOrderLines(ctx, params.Lines, func(item Item, _ float64) (int64, error) {
+		if item.Currency != params.Currency {
+			return 0, fmt.Errorf("%w: %v is priced in %v, enter a price in %v", xerrors.ErrBadRequest, item.Name, item.Currency,
+				params.Currency)
+		}
+		return item.NetPrice, nil
+	})
+}
+
+// basePriceFunc returns the default price per base unit of a quantity of an item.
+type basePriceFunc func(item Item, baseQuantity float64) (int64, error)
+
+// resolveOrderLines validates the lines of a purchase or sales order, converts their quantities into the base unit of
+// the items and fills in default prices.
+func (s Service) resolveOrderLines(ctx context.Context, params []OrderLineParams, basePrice basePriceFunc) ([]orderLineValues, error) {
+	lines := make([]orderLineValues, 0, len(params))
+	for i, lineParams := range params {
+		item, err := s.db.item(ctx, lineParams.ItemID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: line %v references an unknown item", xerrors.ErrBadRequest, i+1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		conversion, err := s.unitConversion(ctx, item.ID, lineParams.UnitID)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+
+		baseQuantity, err := conversion.ToBase(lineParams.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+
+		line := orderLineValues{
+			ItemID:       item.ID,
+			Quantity:     lineParams.Quantity,
+			UnitID:       conversion.Unit.ID,
+			BaseQuantity: baseQuantity,
+			Price:        lineParams.Price.Int64,
+		}
+		if !lineParams.Price.Valid {
+			price, err := basePrice(item, baseQuantity)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %w", i+1, err)
+			}
+			line.Price = int64(math.Round(float64(price) * conversion.Factor))
+		}
+
+		if line.Price < 0 {
+			return nil, fmt.Errorf("%w: price of line %v can not be negative", xerrors.ErrBadRequest, i+1)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func (s Service) cancelPurchaseOrder(ctx context.Context, id int64) (PurchaseOrderHeader, error) {
+	return s.db.cancelPurchaseOrder(ctx, id)
+}
+
+// receivePurchaseOrder posts a receipt into the delivery plant of the order for every line with a quantity.
+func (s Service) receivePurchaseOrder(ctx context.Context, id int64, params GoodsReceiptParams) (PurchaseOrder, error) {
+	if _, err := time.Parse(time.DateOnly, params.Date); err != nil {
+		return PurchaseOrder{}, fmt.Errorf("%w: receipt date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	order, err := s.db.purchaseOrder(ctx, id)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	reference := strings.TrimSpace(params.Reference)
+	if reference == "" {
+		reference = order.Reference()
+	}
+
+	var movements []GoodsMovementParams
+	for _, lineParams := range params.Lines {
+		if lineParams.Quantity == 0 {
+			continue
+		}
+
+		var line *PurchaseOrderLine
+		for i := range order.Lines {
+			if order.Lines[i].ID == lineParams.LineID {
+				line = &order.Lines[i]
+			}
+		}
+		if line == nil {
+			return PurchaseOrder{}, fmt.Errorf("%w: line %v does not belong to the purchase order", xerrors.ErrBadRequest, lineParams.LineID)
+		}
+
+		conversion, err := s.unitConversion(ctx, line.ItemID, 0)
+		if err != nil {
+			return PurchaseOrder{}, err
+		}
+		quantity, err := conversion.ToBase(lineParams.Quantity)
+		if err != nil {
+			return PurchaseOrder{}, err
+		}
+
+		if lineParams.BinID != 0 {
+			bin, err := s.db.bin(ctx, lineParams.BinID)
+			if err != nil {
+				return PurchaseOrder{}, err
+			}
+			if bin.PlantID != order.PlantID {
+				return PurchaseOrder{}, fmt.Errorf("%w: bin %v is not in the delivery plant", xerrors.ErrBadRequest, bin.Name)
+			}
+		}
+
+		batchNumber := strings.TrimSpace(lineParams.BatchNumber)
+		if err := validateTrackingParams("", lineParams.ExpiryDate, splitSerialNumbers(lineParams.SerialNumbers)); err != nil {
+			return PurchaseOrder{}, err
+		}
+
+		movements = append(movements, GoodsMovementParams{
+			Type:                MovementReceipt,
+			Date:                params.Date,
+			ItemID:              line.ItemID,
+			ToPlantID:           order.PlantID,
+			ToBinID:             lineParams.BinID,
+			Quantity:            quantity,
+			Reference:           reference,
+			PurchaseOrderLineID: line.ID,
+			BatchNumber:         batchNumber,
+			ExpiryDate:          lineParams.ExpiryDate,
+			SerialNumbers:       lineParams.SerialNumbers,
+		})
+	}
+
+	if len(movements) == 0 {
+		return PurchaseOrder{}, fmt.Errorf("%w: enter the received quantity of at least one line", xerrors.ErrBadRequest)
+	}
+
+	order, err = s.db.receivePurchaseOrder(ctx, id, movements)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	s.movementsRecorded(ctx)
+	return order, nil
+}
+
+func (s Service) planningParameters(ctx context.Context, itemID int64) ([]PlanningParameters, error) {
+	return s.db.planningParameters(ctx, PlanningParametersFilter{itemID: sql.NullInt64{Valid: true, Int64: itemID}})
+}
+
+func (s Service) setPlanningParameters(ctx context.Context, itemID int64, params PlanningParametersParams) (PlanningParameters, error) {
+	if _, err := s.db.item(ctx, itemID); err != nil {
+		return PlanningParameters{}, err
+	}
+
+	if params.ReorderPoint < 0 || params.SafetyStock < 0 || params.LotSize < 0 || params.LeadTimeDays < 0 {
+		return PlanningParameters{}, fmt.Errorf("%w: planning parameters can not be negative", xerrors.ErrBadRequest)
+	}
+
+	if params.SafetyStock > params.ReorderPoint {
+		return PlanningParameters{}, fmt.Errorf("%w: safety stock can not be above the reorder point", xerrors.ErrBadRequest)
+	}
+
+	if _, err := s.db.plant(ctx, params.PlantID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return PlanningParameters{}, fmt.Errorf("%w: unknown plant", xerrors.ErrBadRequest)
+		}
+		return PlanningParameters{}, err
+	}
+
+	if params.SupplierID != 0 {
+		if _, err := s.db.supplier(ctx, params.SupplierID); err != nil {
+			if errors.Is(err, xerrors.ErrNotFound) {
+				return PlanningParameters{}, fmt.Errorf("%w: unknown supplier", xerrors.ErrBadRequest)
+			}
+			return PlanningParameters{}, err
+		}
+	}
+
+	return s.db.setPlanningParameters(ctx, itemID, params)
+}
+
+func (s Service) deletePlanningParameters(ctx context.Context, itemID int64, plantID int64) error {
+	return s.db.deletePlanningParameters(ctx, itemID, plantID)
+}
+
+func (s Service) projections(ctx context.Context, filter PlanningParametersFilter) ([]Projection, error) {
+	return s.db.projections(ctx, filter)
+}
+
+// planningRun replaces all open purchase proposals with a proposal for every item and plant whose projected stock is
+// below its reorder point. Accepted and discarded proposals are kept.
+func (s Service) planningRun(ctx context.Context) ([]PurchaseProposal, error) {
+	projections, err := s.db.projections(ctx, PlanningParametersFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	today := time.Now()
+	var proposals []PurchaseProposal
+	for _, projection := range projections {
+		quantity, ok := projection.Proposal()
+		if !ok {
+			continue
+		}
+
+		proposals = append(proposals, PurchaseProposal{
+			ItemID:            projection.ItemID,
+			PlantID:           projection.PlantID,
+			SupplierID:        projection.SupplierID,
+			Quantity:          quantity,
+			DeliveryDate:      today.AddDate(0, 0, projection.LeadTimeDays).Format(time.DateOnly),
+			ProjectedQuantity: projection.Projected(),
+			BelowSafetyStock:  projection.Projected() < projection.SafetyStock,
+		})
+	}
+
+	return s.db.replacePurchaseProposals(ctx, proposals)
+}
+
+func (s Service) purchaseProposal(ctx context.Context, id int64) (PurchaseProposal, error) {
+	return s.db.purchaseProposal(ctx, id)
+}
+
+func (s Service) purchaseProposals(ctx context.Context, filter PurchaseProposalFilter) ([]PurchaseProposal, error) {
+	return s.db.purchaseProposals(ctx, filter)
+}
+
+func (s Service) updatePurchaseProposal(ctx context.Context, id int64, params PurchaseProposalParams) (PurchaseProposal, error) {
+	proposal, err := s.db.purchaseProposal(ctx, id)
+	if err != nil {
+		return PurchaseProposal{}, err
+	}
+
+	if _, err := time.Parse(time.DateOnly, params.DeliveryDate); err != nil {
+		return PurchaseProposal{}, fmt.Errorf("%w: delivery date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	conversion, err := s.unitConversion(ctx, proposal.ItemID, 0)
+	if err != nil {
+		return PurchaseProposal{}, err
+	}
+
+	params.Quantity, err = conversion.ToBase(params.Quantity)
+	if err != nil {
+		return PurchaseProposal{}, err
+	}
+
+	if params.SupplierID != 0 {
+		if _, err := s.db.supplier(ctx, params.SupplierID); err != nil {
+			if errors.Is(err, xerrors.ErrNotFound) {
+				return PurchaseProposal{}, fmt.Errorf("%w: unknown supplier", xerrors.ErrBadRequest)
+			}
+			return PurchaseProposal{}, err
+		}
+	}
+
+	return s.db.updatePurchaseProposal(ctx, id, params)
+}
+
+// acceptPurchaseProposal turns an open proposal into a purchase order dated today. Delivery dates in the past are moved
+// to today.
+func (s Service) acceptPurchaseProposal(ctx context.Context, id int64) (PurchaseOrder, error) {
+	proposal, err := s.db.purchaseProposal(ctx, id)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	if !proposal.SupplierID.Valid {
+		return PurchaseOrder{}, fmt.Errorf("%w: a supplier is required to accept a purchase proposal", xerrors.ErrBadRequest)
+	}
+
+	item, err := s.db.item(ctx, proposal.ItemID)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	// The order is placed in the currency of the item, so that the line defaults to its net price.
+	today := time.Now().Format(time.DateOnly)
+	params := PurchaseOrderParams{
+		PurchaseOrderHeaderParams: PurchaseOrderHeaderParams{
+			SupplierID:   proposal.SupplierID.Int64,
+			PlantID:      proposal.PlantID,
+			Date:         today,
+			DeliveryDate: max(proposal.DeliveryDate, today),
+			Currency:     item.Currency,
+		},
+		Lines: []OrderLineParams{{ItemID: proposal.ItemID, Quantity: proposal.Quantity}},
+	}
+
+	lines, err := s.resolvePurchaseOrder(ctx, &params)
+	if err != nil {
+		return PurchaseOrder{}, err
+	}
+
+	return s.db.acceptPurchaseProposal(ctx, id, params.PurchaseOrderHeaderParams, lines)
+}
+
+func (s Service) discardPurchaseProposal(ctx context.Context, id int64) (PurchaseProposal, error) {
+	return s.db.discardPurchaseProposal(ctx, id)
+}
+
+func (s Service) inventoryCount(ctx context.Context, id int64) (InventoryCount, error) {
+	return s.db.inventoryCount(ctx, id)
+}
+
+func (s Service) inventoryCounts(ctx context.Context, filter InventoryCountFilter) ([]InventoryCountHeader, error) {
+	return s.db.inventoryCounts(ctx, filter)
+}
+
+func (s Service) createInventoryCount(ctx context.Context, params InventoryCountParams) (InventoryCount, error) {
+	params.Note = strings.TrimSpace(params.Note)
+
+	if _, err := time.Parse(time.DateOnly, params.Date); err != nil {
+		return InventoryCount{}, fmt.Errorf("%w: count date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if _, err := s.db.plant(ctx, params.PlantID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return InventoryCount{}, fmt.Errorf("%w: unknown plant", xerrors.ErrBadRequest)
+		}
+		return InventoryCount{}, err
+	}
+
+	slices.Sort(params.ItemIDs)
+	params.ItemIDs = slices.Compact(params.ItemIDs)
+	if len(params.ItemIDs) == 0 {
+		return InventoryCount{}, fmt.Errorf("%w: select at least one item to count", xerrors.ErrBadRequest)
+	}
+
+	for _, itemID := range params.ItemIDs {
+		item, err := s.db.item(ctx, itemID)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return InventoryCount{}, fmt.Errorf("%w: unknown item %v", xerrors.ErrBadRequest, itemID)
+		}
+		if err != nil {
+			return InventoryCount{}, err
+		}
+
+		if item.Tracking == TrackingSerial {
+			return InventoryCount{}, fmt.Errorf("%w: item %v is serialized and can not be counted by quantity", xerrors.ErrBadRequest, item.Name)
+		}
+	}
+
+	return s.db.createInventoryCount(ctx, params)
+}
+
+// setCountedQuantities checks counted quantities against the base units of the items and stores them.
+func (s Service) setCountedQuantities(ctx context.Context, id int64, quantities CountedQuantities) (InventoryCount, error) {
+	count, err := s.db.inventoryCount(ctx, id)
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	lines := make(map[int64]InventoryCountLine, len(count.Lines))
+	for _, line := range count.Lines {
+		lines[line.ID] = line
+	}
+
+	for lineID, quantity := range quantities {
+		line, ok := lines[lineID]
+		if !ok {
+			return InventoryCount{}, fmt.Errorf("%w: line %v does not belong to the inventory count", xerrors.ErrBadRequest, lineID)
+		}
+
+		if quantity < 0 {
+			return InventoryCount{}, fmt.Errorf("%w: counted quantity of %v can not be negative", xerrors.ErrBadRequest, line.ItemName)
+		}
+		if quantity > 0 {
+			unit := Unit{Code: line.UnitCode, Decimals: line.Decimals}
+			if err := unit.Validate(quantity); err != nil {
+				return InventoryCount{}, fmt.Errorf("%v: %w", line.ItemName, err)
+			}
+		}
+	}
+
+	return s.db.setCountedQuantities(ctx, id, quantities)
+}
+
+// importCountSheet reads counted quantities from a CSV count sheet and stores them. The sheet needs a header row with
+// the columns line and counted, rows with an empty counted column are skipped.
+func (s Service) importCountSheet(ctx context.Context, id int64, sheet io.Reader) (InventoryCount, error) {
+	quantities, err := parseCountSheet(sheet)
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	if len(quantities) == 0 {
+		return InventoryCount{}, fmt.Errorf("%w: the count sheet contains no counted quantities", xerrors.ErrBadRequest)
+	}
+
+	return s.setCountedQuantities(ctx, id, quantities)
+}
+
+func parseCountSheet(sheet io.Reader) (CountedQuantities, error) {
+	reader := csv.NewReader(sheet)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read the header of the count sheet", xerrors.ErrBadRequest)
+	}
+
+	lineColumn, countedColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "line":
+			lineColumn = i
+		case "counted":
+			countedColumn = i
+		}
+	}
+	if lineColumn < 0 || countedColumn < 0 {
+		return nil, fmt.Errorf("%w: the count sheet needs the columns line and counted", xerrors.ErrBadRequest)
+	}
+
+	quantities := make(CountedQuantities)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read the count sheet: %v", xerrors.ErrBadRequest, err)
+		}
+
+		row, _ := reader.FieldPos(0)
+		if lineColumn >= len(record) || countedColumn >= len(record) {
+			return nil, fmt.Errorf("%w: row %v of the count sheet is incomplete", xerrors.ErrBadRequest, row)
+		}
+
+		counted := strings.TrimSpace(record[countedColumn])
+		if counted == "" {
+			continue
+		}
+
+		lineID, err := strconv.ParseInt(strings.TrimSpace(record[lineColumn]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %v of the count sheet has no valid line", xerrors.ErrBadRequest, row)
+		}
+
+		quantity, err := strconv.ParseFloat(strings.ReplaceAll(counted, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: counted quantity in row %v of the count sheet has to be a number", xerrors.ErrBadRequest, row)
+		}
+
+		if _, ok := quantities[lineID]; ok {
+			return nil, fmt.Errorf("%w: line %v is counted twice in the count sheet", xerrors.ErrBadRequest, lineID)
+		}
+		quantities[lineID] = quantity
+	}
+
+	return quantities, nil
+}
+
+// approveInventoryCount posts the differences of a fully counted inventory count as adjustments dated today.
+func (s Service) approveInventoryCount(ctx context.Context, id int64) (InventoryCount, error) {
+	count, err := s.db.approveInventoryCount(ctx, id, time.Now().Format(time.DateOnly))
+	if err != nil {
+		return InventoryCount{}, err
+	}
+
+	s.movementsRecorded(ctx)
+	return count, nil
+}
+
+func (s Service) cancelInventoryCount(ctx context.Context, id int64) (InventoryCountHeader, error) {
+	return s.db.cancelInventoryCount(ctx, id)
+}
+
+func (s Service) salesOrder(ctx context.Context, id int64) (SalesOrder, error) {
+	return s.db.salesOrder(ctx, id)
+}
+
+func (s Service) salesOrders(ctx context.Context, filter SalesOrderFilter) ([]SalesOrderHeader, error) {
+	return s.db.salesOrders(ctx, filter)
+}
+
+func (s Service) createSalesOrder(ctx context.Context, params SalesOrderParams) (SalesOrder, error) {
+	lines, err := s.resolveSalesOrder(ctx, &params)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+
+	return s.db.createSalesOrder(ctx, params.SalesOrderHeaderParams, lines)
+}
+
+func (s Service) updateSalesOrder(ctx context.Context, id int64, params SalesOrderParams) (SalesOrder, error) {
+	lines, err := s.resolveSalesOrder(ctx, &params)
+	if err != nil {
+		return SalesOrder{}, err
+	}
+
+	return s.db.updateSalesOrder(ctx, id, params.SalesOrderHeaderParams, lines)
+}
+
+// resolveSalesOrder validates the params, fills in the shipping address of the customer if none is given and resolves
+// the lines, see resolveOrderLines and Price.
+func (s Service) resolveSalesOrder(ctx context.Context, params *SalesOrderParams) ([]orderLineValues, error) {
+	date, err := time.Parse(time.DateOnly, params.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	requestedDeliveryDate, err := time.Parse(time.DateOnly, params.RequestedDeliveryDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: requested delivery date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	if requestedDeliveryDate.Before(date) {
+		return nil, fmt.Errorf("%w: requested delivery date can not be before the order date", xerrors.ErrBadRequest)
+	}
+
+	customer, err := s.db.customer(ctx, params.CustomerID)
+	if err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown customer", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if params.ShippingAddressID == 0 {
+		params.ShippingAddressID = customer.AddressID
+	} else if _, err := s.db.address(ctx, params.ShippingAddressID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown shipping address", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if _, err := s.db.plant(ctx, params.PlantID); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown plant", xerrors.ErrBadRequest)
+		}
+		return nil, err
+	}
+
+	if params.Currency, err = normalizeCurrency(params.Currency); err != nil {
+		return nil, err
+	}
+
+	if len(params.Lines) == 0 {
+		return nil, fmt.Errorf("%w: sales order needs at least one line", xerrors.ErrBadRequest)
+	}
+
+	// Lines without a price are priced for the customer on the order date in the currency of the order.
+	return s.resolveOrderLines(ctx, params.Lines, func(item Item, baseQuantity float64) (int64, error) {
+		price, err := s.Price(ctx, PriceQuery{ItemID: item.ID, Quantity: baseQuantity, CustomerID: customer.ID, Date: params.Date,
+			Currency: params.Currency})
+		return price.Price, err
+	})
+}
+
+func (s Service) cancelSalesOrder(ctx context.Context, id int64) (SalesOrderHeader, error) {
+	return s.db.cancelSalesOrder(ctx, id)
+}
+
+func (s Service) delivery(ctx context.Context, id int64) (Delivery, error) {
+	return s.db.delivery(ctx, id)
+}
+
+func (s Service) deliveries(ctx context.Context, filter DeliveryFilter) ([]DeliveryHeader, error) {
+	return s.db.deliveries(ctx, filter)
+}
+
+// createDelivery creates a delivery for every line of the sales order with a quantity, lines are picked from bins of the
+// plant of the order.
+func (s Service) createDelivery(ctx context.Context, orderID int64, params DeliveryParams) (Delivery, error) {
+	if _, err := time.Parse(time.DateOnly, params.Date); err != nil {
+		return Delivery{}, fmt.Errorf("%w: delivery date has to be formatted as YYYY-MM-DD", xerrors.ErrBadRequest)
+	}
+
+	order, err := s.db.salesOrder(ctx, orderID)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	var lines []deliveryLineValues
+	for _, lineParams := range params.Lines {
+		if lineParams.Quantity == 0 {
+			continue
+		}
+
+		var orderLine *SalesOrderLine
+		for i := range order.Lines {
+			if order.Lines[i].ID == lineParams.SalesOrderLineID {
+				orderLine = &order.Lines[i]
+			}
+		}
+		if orderLine == nil {
+			return Delivery{}, fmt.Errorf("%w: line %v does not belong to the sales order", xerrors.ErrBadRequest, lineParams.SalesOrderLineID)
+		}
+
+		conversion, err := s.unitConversion(ctx, orderLine.ItemID, 0)
+		if err != nil {
+			return Delivery{}, err
+		}
+		quantity, err := conversion.ToBase(lineParams.Quantity)
+		if err != nil {
+			return Delivery{}, err
+		}
+
+		if lineParams.BinID != 0 {
+			bin, err := s.db.bin(ctx, lineParams.BinID)
+			if err != nil {
+				return Delivery{}, err
+			}
+			if bin.PlantID != order.PlantID {
+				return Delivery{}, fmt.Errorf("%w: bin %v is not in the plant of the sales order", xerrors.ErrBadRequest, bin.Name)
+			}
+		}
+
+		batchID, serialNumberIDs, err := s.resolveDeliveryTracking(ctx, order.PlantID, orderLine.ItemID, quantity, lineParams)
+		if err != nil {
+			return Delivery{}, err
+		}
+
+		lines = append(lines, deliveryLineValues{
+			SalesOrderLineID: orderLine.ID,
+			Quantity:         quantity,
+			BinID:            nullID(lineParams.BinID),
+			BatchID:          nullID(batchID),
+			SerialNumberIDs:  serialNumberIDs,
+		})
+	}
+
+	if len(lines) == 0 {
+		return Delivery{}, fmt.Errorf("%w: enter the quantity to deliver of at least one line", xerrors.ErrBadRequest)
+	}
+
+	return s.db.createDelivery(ctx, orderID, params.Date, lines)
+}
+
+// resolveDeliveryTracking checks the batch or serial numbers of a delivery line against the tracking mode of the item
+// and the stock of the plant and returns their IDs. Stock is checked again when the delivery is shipped.
+func (s Service) resolveDeliveryTracking(ctx context.Context, plantID int64, itemID int64, quantity float64, params DeliveryLineParams) (int64, []int64, error) {
+	item, err := s.db.item(ctx, itemID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	numbers := splitSerialNumbers(params.SerialNumbers)
+	if item.Tracking != TrackingBatch && params.BatchID != 0 {
+		return 0, nil, fmt.Errorf("%w: item %v is not batch managed", xerrors.ErrBadRequest, item.Name)
+	}
+	if item.Tracking != TrackingSerial && len(numbers) > 0 {
+		return 0, nil, fmt.Errorf("%w: item %v is not serialized", xerrors.ErrBadRequest, item.Name)
+	}
+
+	switch item.Tracking {
+	case TrackingBatch:
+		if params.BatchID == 0 {
+			return 0, nil, fmt.Errorf("%w: item %v is batch managed, a batch is required", xerrors.ErrBadRequest, item.Name)
+		}
+
+		stock, err := s.db.batchStock(ctx, itemID, sql.NullInt64{Valid: true, Int64: plantID})
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return 0, nil, err
+		}
+		for _, batch := range stock {
+			if batch.ID == params.BatchID {
+				return batch.ID, nil, nil
+			}
+		}
+		return 0, nil, fmt.Errorf("%w: the selected batch of item %v is not in stock in the plant", xerrors.ErrBadRequest, item.Name)
+
+	case TrackingSerial:
+		if float64(len(numbers)) != quantity {
+			return 0, nil, fmt.Errorf("%w: item %v is serialized, %v serial numbers are required", xerrors.ErrBadRequest, item.Name, quantity)
+		}
+		if err := validateTrackingParams("", "", numbers); err != nil {
+			return 0, nil, err
+		}
+
+		ids := make([]int64, 0, len(numbers))
+		for _, number := range numbers {
+			serialNumbers, err := s.db.serialNumbers(ctx, SerialNumberFilter{
+				itemID:  sql.NullInt64{Valid: true, Int64: itemID},
+				number:  sql.NullString{Valid: true, String: number},
+				plantID: sql.NullInt64{Valid: true, Int64: plantID},
+			})
+			if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+				return 0, nil, err
+			}
+
+			// The filter matches with LIKE, so only accept the exact number.
+			var id int64
+			for _, serialNumber := range serialNumbers {
+				if serialNumber.Number == number {
+					id = serialNumber.ID
+				}
+			}
+			if id == 0 {
+				return 0, nil, fmt.Errorf("%w: serial number %v of item %v is not in stock in the plant", xerrors.ErrBadRequest, number, item.Name)
+			}
+			ids = append(ids, id)
+		}
+		return 0, ids, nil
+	}
+
+	return 0, nil, nil
+}
+
+func (s Service) shipDelivery(ctx context.Context, id int64) (Delivery, error) {
+	delivery, err := s.db.shipDelivery(ctx, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	s.movementsRecorded(ctx)
+	return delivery, nil
+}
+
+func (s Service) cancelDelivery(ctx context.Context, id int64) (Delivery, error) {
+	return s.db.cancelDelivery(ctx, id)
+}
+
+func (s Service) pickingList(ctx context.Context, plantID int64, deliveryID sql.NullInt64) ([]PickingListLine, error) {
+	return s.db.pickingList(ctx, plantID, deliveryID)
+}
+
+// carrierList returns the registered carriers ordered by name.
+func (s Service) carrierList() []Carrier {
+	carriers := make([]Carrier, 0, len(s.carriers))
+	for _, carrier := range s.carriers {
+		carriers = append(carriers, carrier)
+	}
+	slices.SortFunc(carriers, func(a Carrier, b Carrier) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return carriers
+}
+
+func (s Service) carrier(code string) (Carrier, error) {
+	carrier, ok := s.carriers[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown carrier %v", xerrors.ErrBadRequest, code)
+	}
+	return carrier, nil
+}
+
+func (s Service) shipment(ctx context.Context, id int64) (Shipment, error) {
+	return s.db.shipment(ctx, id)
+}
+
+func (s Service) shipments(ctx context.Context, filter ShipmentFilter) ([]ShipmentHeader, error) {
+	return s.db.shipments(ctx, filter)
+}
+
+func (s Service) createShipment(ctx context.Context, deliveryID int64, params ShipmentParams) (Shipment, error) {
+	carrier, err := s.carrier(params.Carrier)
+	if err != nil {
+		return Shipment{}, err
+	}
+	if !slices.ContainsFunc(carrier.Services(), func(service CarrierService) bool { return service.Code == params.Service }) {
+		return Shipment{}, fmt.Errorf("%w: %v does not offer service %v", xerrors.ErrBadRequest, carrier.Name(), params.Service)
+	}
+
+	return s.db.createShipment(ctx, deliveryID, params)
+}
+
+func (s Service) createShipmentPackage(ctx context.Context, shipmentID int64, params ShipmentPackageParams) (Shipment, error) {
+	if params.Length <= 0 || params.Width <= 0 || params.Height <= 0 {
+		return Shipment{}, fmt.Errorf("%w: package dimensions have to be positive", xerrors.ErrBadRequest)
+	}
+	if params.Weight <= 0 {
+		return Shipment{}, fmt.Errorf("%w: package weight has to be positive", xerrors.ErrBadRequest)
+	}
+
+	return s.db.createShipmentPackage(ctx, shipmentID, params)
+}
+
+func (s Service) deleteShipmentPackage(ctx context.Context, shipmentID int64, packageID int64) (Shipment, error) {
+	return s.db.deleteShipmentPackage(ctx, shipmentID, packageID)
+}
+
+// createShippingLabel registers an open shipment with its carrier and stores the tracking numbers. If they can not be
+// stored, the label is cancelled with the carrier again.
+func (s Service) createShippingLabel(ctx context.Context, id int64) (Shipment, error) {
+	label, err := s.shippingLabel(ctx, id)
+	if err != nil {
+		return Shipment{}, err
+	}
+	if !label.Open() {
+		return Shipment{}, fmt.Errorf("%w: labels can only be created for open shipments", xerrors.ErrBadRequest)
+	}
+	if len(label.Packages) == 0 {
+		return Shipment{}, fmt.Errorf("%w: add at least one package before creating the label", xerrors.ErrBadRequest)
+	}
+
+	carrier, err := s.carrier(label.Carrier)
+	if err != nil {
+		return Shipment{}, err
+	}
+
+	carrierLabel, err := carrier.CreateLabel(ctx, LabelRequest{
+		Reference: label.Reference(),
+		Service:   label.Service,
+		From:      label.From,
+		To:        label.To,
+		Packages:  label.Packages,
+	})
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		return Shipment{}, err
+	}
+	if err != nil {
+		return Shipment{}, xerrors.Join(xerrors.ErrInternal, fmt.Errorf("unable to create label with carrier %v: %w", carrier.Code(), err))
+	}
+
+	shipment, err := s.db.labelShipment(ctx, id, carrierLabel)
+	if err != nil {
+		if cancelErr := carrier.CancelLabel(ctx, carrierLabel.TrackingNumber); cancelErr != nil {
+			slog.Error("Unable to cancel label", "carrier", carrier.Code(), "tracking_number", carrierLabel.TrackingNumber, "error", cancelErr)
+		}
+		return Shipment{}, err
+	}
+
+	return shipment, nil
+}
+
+// cancelShipment cancels a shipment, the label of a labeled shipment is cancelled with the carrier first.
+func (s Service) cancelShipment(ctx context.Context, id int64) (Shipment, error) {
+	shipment, err := s.db.shipment(ctx, id)
+	if err != nil {
+		return Shipment{}, err
+	}
+
+	if shipment.Labeled() {
+		carrier, err := s.carrier(shipment.Carrier)
+		if err != nil {
+			return Shipment{}, err
+		}
+		if err := carrier.CancelLabel(ctx, shipment.TrackingNumber); err != nil {
+			if errors.Is(err, xerrors.ErrBadRequest) {
+				return Shipment{}, err
+			}
+			return Shipment{}, xerrors.Join(xerrors.ErrInternal, fmt.Errorf("unable to cancel label with carrier %v: %w", carrier.Code(), err))
+		}
+	}
+
+	return s.db.cancelShipment(ctx, id)
+}
+
+// shippingLabel returns what is printed on the labels of a shipment. The sender is the plant of the sales order, the
+// recipient the customer at the shipping address of the order.
+func (s Service) shippingLabel(ctx context.Context, id int64) (ShippingLabel, error) {
+	shipment, err := s.db.shipment(ctx, id)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+
+	delivery, err := s.db.delivery(ctx, shipment.DeliveryID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+
+	order, err := s.db.salesOrder(ctx, delivery.SalesOrderID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+
+	plant, err := s.db.plant(ctx, order.PlantID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+	from, err := s.labelAddress(ctx, plant.Name, plant.AddressID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+
+	customer, err := s.db.customer(ctx, order.CustomerID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+	to, err := s.labelAddress(ctx, customer.Name, order.ShippingAddressID)
+	if err != nil {
+		return ShippingLabel{}, err
+	}
+
+	label := ShippingLabel{
+		Shipment:          shipment,
+		CarrierName:       shipment.Carrier,
+		ServiceName:       shipment.Service,
+		DeliveryReference: delivery.Reference(),
+		From:              from,
+		To:                to,
+	}
+
+	// Labels of carriers that are no longer registered are printed with the codes.
+	if carrier, ok := s.carriers[shipment.Carrier]; ok {
+		label.CarrierName = carrier.Name()
+		for _, service := range carrier.Services() {
+			if service.Code == shipment.Service {
+				label.ServiceName = service.Name
+			}
+		}
+	}
+
+	return label, nil
+}
+
+func (s Service) labelAddress(ctx context.Context, name string, addressID int64) (LabelAddress, error) {
+	address, err := s.db.address(ctx, addressID)
+	if err != nil {
+		return LabelAddress{}, err
+	}
+
+	country, err := s.db.country(ctx, address.Country)
+	if err != nil {
+		return LabelAddress{}, err
+	}
+
+	return LabelAddress{Name: name, Address: address, CountryName: country.Name}, nil
+}
+
+func (s Service) batch(ctx context.Context, id int64) (Batch, error) {
+	return s.db.batch(ctx, id)
+}
+
+func (s Service) batches(ctx context.Context, filter BatchFilter) ([]Batch, error) {
+	return s.db.batches(ctx, filter)
+}
+
+func (s Service) updateBatch(ctx context.Context, id int64, params BatchParams) (Batch, error) {
+	if err := validateTrackingParams(params.ManufactureDate, params.ExpiryDate, nil); err != nil {
+		return Batch{}, err
+	}
+
+	return s.db.updateBatch(ctx, id, params)
+}
+
+// batchStock returns the batches of an item in stock, first expiring first, which is the order batches should be
+// issued in.
+func (s Service) batchStock(ctx context.Context, itemID int64, plantID sql.NullInt64) ([]BatchStock, error) {
+	return s.db.batchStock(ctx, itemID, plantID)
+}
+
+func (s Service) serialNumber(ctx context.Context, id int64) (SerialNumber, error) {
+	return s.db.serialNumber(ctx, id)
+}
+
+func (s Service) serialNumbers(ctx context.Context, filter SerialNumberFilter) ([]SerialNumber, error) {
+	return s.db.serialNumbers(ctx, filter)
+}
+
+func (s Service) batchTrace(ctx context.Context, batchID int64) ([]TraceMovement, error) {
+	return s.db.trace(ctx, sql.NullInt64{Valid: true, Int64: batchID}, sql.NullInt64{})
+}
+
+func (s Service) serialNumberTrace(ctx context.Context, serialNumberID int64) ([]TraceMovement, error) {
+	return s.db.trace(ctx, sql.NullInt64{}, sql.NullInt64{Valid: true, Int64: serialNumberID})
+}
+
+// The following methods give other modules read access to logistics master data.
+
+func (s Service) Item(ctx context.Context, id int64) (Item, error) {
+	return s.db.item(ctx, id)
+}
+
+func (s Service) Items(ctx context.Context, filter ItemFilter) ([]Item, error) {
+	return s.db.items(ctx, filter)
+}
+
+func (s Service) Units(ctx context.Context) ([]Unit, error) {
+	return s.db.units(ctx, UnitFilter{})
+}
+
+func (s Service) UnitConversion(ctx context.Context, itemID int64, unitID int64) (UnitConversion, error) {
+	return s.unitConversion(ctx, itemID, unitID)
+}
+
+func (s Service) Plants(ctx context.Context) ([]Plant, error) {
+	return s.db.plants(ctx, PlantFilter{})
+}
+
+func (s Service) Address(ctx context.Context, id int64) (Address, error) {
+	return s.db.address(ctx, id)
+}
+
+func (s Service) Customer(ctx context.Context, id int64) (Customer, error) {
+	return s.db.customer(ctx, id)
+}
+
+func (s Service) Customers(ctx context.Context, filter CustomerFilter) ([]Customer, error) {
+	return s.db.customers(ctx, filter)
 }
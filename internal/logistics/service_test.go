@@ -0,0 +1,281 @@
+package logistics
+
+import (
+	"database/sql"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+func TestItemPrices(t *testing.T) {
+	tests := []struct {
+		name      string
+		basis     itemPriceBasis
+		wantNet   int64
+		wantGross int64
+	}{
+		{
+			name:      "gross from net",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryNet, NetPrice: 999, Rate: 19},
+			wantNet:   999,
+			wantGross: 1189,
+		},
+		{
+			name:      "gross from net rounded half up",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryNet, NetPrice: 50, Rate: 19},
+			wantNet:   50,
+			wantGross: 60,
+		},
+		{
+			name:      "gross from net rounded to the currency increment",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryNet, NetPrice: 1000, Rate: 7.7, Increment: 5},
+			wantNet:   1000,
+			wantGross: 1075,
+		},
+		{
+			name:      "gross from net rounded up to the currency increment",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryNet, NetPrice: 1050, Rate: 19, Increment: 5},
+			wantNet:   1050,
+			wantGross: 1250,
+		},
+		{
+			name:      "net from gross",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryGross, GrossPrice: 1000, Rate: 19},
+			wantNet:   840,
+			wantGross: 1000,
+		},
+		{
+			name:      "entered gross is not rounded to the currency increment",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryGross, GrossPrice: 1077, Rate: 7.7, Increment: 5},
+			wantNet:   1000,
+			wantGross: 1077,
+		},
+		{
+			name:      "zero rate",
+			basis:     itemPriceBasis{PriceEntry: PriceEntryGross, GrossPrice: 1234},
+			wantNet:   1234,
+			wantGross: 1234,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, gross := itemPrices(tt.basis)
+			if net != tt.wantNet || gross != tt.wantGross {
+				t.Errorf("itemPrices() = %v, %v, want %v, %v", net, gross, tt.wantNet, tt.wantGross)
+			}
+		})
+	}
+}
+
+func TestDeriveItemPrices(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    ItemParams
+		rate      float64
+		increment int64
+		want      ItemParams
+		wantErr   string
+	}{
+		{
+			name:   "entered net price replaces the gross price",
+			params: ItemParams{PriceEntry: PriceEntryNet, NetPrice: 1000, GrossPrice: 1},
+			rate:   19,
+			want:   ItemParams{PriceEntry: PriceEntryNet, NetPrice: 1000, GrossPrice: 1190},
+		},
+		{
+			name:   "matching prices without entry are net",
+			params: ItemParams{NetPrice: 1000, GrossPrice: 1190},
+			rate:   19,
+			want:   ItemParams{PriceEntry: PriceEntryNet, NetPrice: 1000, GrossPrice: 1190},
+		},
+		{
+			// 1003 is not a multiple of the increment, so the prices only match if the gross price was entered.
+			name:      "matching prices without entry are gross",
+			params:    ItemParams{NetPrice: 931, GrossPrice: 1003},
+			rate:      7.7,
+			increment: 5,
+			want:      ItemParams{PriceEntry: PriceEntryGross, NetPrice: 931, GrossPrice: 1003},
+		},
+		{
+			name:    "mismatching prices without entry",
+			params:  ItemParams{NetPrice: 1000, GrossPrice: 1200},
+			rate:    19,
+			wantErr: "it has to be 1190",
+		},
+		{
+			name:    "negative price",
+			params:  ItemParams{PriceEntry: PriceEntryGross, GrossPrice: -1},
+			rate:    19,
+			wantErr: "prices can not be negative",
+		},
+		{
+			name:    "unknown price entry",
+			params:  ItemParams{PriceEntry: "list", NetPrice: 1000},
+			rate:    19,
+			wantErr: "unknown price entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deriveItemPrices(tt.params, tt.rate, tt.increment)
+			if tt.wantErr != "" {
+				if !errors.Is(err, xerrors.ErrBadRequest) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want bad request containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.PriceEntry != tt.want.PriceEntry || got.NetPrice != tt.want.NetPrice || got.GrossPrice != tt.want.GrossPrice {
+				t.Errorf("prices = %v %v/%v, want %v %v/%v",
+					got.PriceEntry, got.NetPrice, got.GrossPrice, tt.want.PriceEntry, tt.want.NetPrice, tt.want.GrossPrice)
+			}
+		})
+	}
+}
+
+func TestParseCountSheet(t *testing.T) {
+	tests := []struct {
+		name    string
+		sheet   string
+		want    CountedQuantities
+		wantErr string
+	}{
+		{
+			name:  "counted and uncounted lines",
+			sheet: "\ufeffLine,Item,Counted\n1,Screw,5\n2,Nut,\n3,Cable,\"2,5\"\n",
+			want:  CountedQuantities{1: 5, 3: 2.5},
+		},
+		{
+			name:  "columns in any order",
+			sheet: "Counted, Item, LINE\n0,Screw,1\n",
+			want:  CountedQuantities{1: 0},
+		},
+		{
+			name:    "empty sheet",
+			sheet:   "",
+			wantErr: "unable to read the header",
+		},
+		{
+			name:    "missing column",
+			sheet:   "line,item\n1,Screw\n",
+			wantErr: "needs the columns line and counted",
+		},
+		{
+			name:    "incomplete row",
+			sheet:   "line,item,counted\n1,Screw,5\n2,Nut\n",
+			wantErr: "row 3 of the count sheet is incomplete",
+		},
+		{
+			name:    "malformed row",
+			sheet:   "line,item,counted\n1,\"Screw,5\n",
+			wantErr: "unable to read the count sheet",
+		},
+		{
+			name:    "invalid line",
+			sheet:   "line,item,counted\nfirst,Screw,5\n",
+			wantErr: "row 2 of the count sheet has no valid line",
+		},
+		{
+			name:    "invalid quantity",
+			sheet:   "line,item,counted\n1,Screw,five\n",
+			wantErr: "has to be a number",
+		},
+		{
+			name:    "duplicate line",
+			sheet:   "line,item,counted\n1,Screw,5\n1,Screw,6\n",
+			wantErr: "line 1 is counted twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCountSheet(strings.NewReader(tt.sheet))
+			if tt.wantErr != "" {
+				if !errors.Is(err, xerrors.ErrBadRequest) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want bad request containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("quantities = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestPrice(t *testing.T) {
+	group := sql.NullInt64{Valid: true, Int64: 1}
+	customer := sql.NullInt64{Valid: true, Int64: 1}
+
+	general := priceCandidate{PriceListID: 1, ValidFrom: "2024-06-01", MinQuantity: 100, Price: 80}
+	groupPrice := priceCandidate{PriceListID: 2, ValidFrom: "2024-01-01", CustomerGroupID: group, Price: 90}
+	customerPrice := priceCandidate{PriceListID: 3, ValidFrom: "2023-01-01", CustomerID: customer, Price: 95}
+
+	tests := []struct {
+		name       string
+		candidates []priceCandidate
+		want       priceCandidate
+	}{
+		{
+			name:       "customer before group and general",
+			candidates: []priceCandidate{general, groupPrice, customerPrice},
+			want:       customerPrice,
+		},
+		{
+			name:       "group before general",
+			candidates: []priceCandidate{general, groupPrice},
+			want:       groupPrice,
+		},
+		{
+			name: "latest valid from",
+			candidates: []priceCandidate{
+				{PriceListID: 1, ValidFrom: "2024-01-01", Price: 100},
+				{PriceListID: 2, ValidFrom: "2024-03-01", Price: 110},
+				{PriceListID: 3, Price: 90},
+			},
+			want: priceCandidate{PriceListID: 2, ValidFrom: "2024-03-01", Price: 110},
+		},
+		{
+			name: "highest scale",
+			candidates: []priceCandidate{
+				{PriceListID: 1, ValidFrom: "2024-01-01", MinQuantity: 0, Price: 100},
+				{PriceListID: 1, ValidFrom: "2024-01-01", MinQuantity: 50, Price: 85},
+				{PriceListID: 1, ValidFrom: "2024-01-01", MinQuantity: 10, Price: 95},
+			},
+			want: priceCandidate{PriceListID: 1, ValidFrom: "2024-01-01", MinQuantity: 50, Price: 85},
+		},
+		{
+			name: "latest valid from before highest scale",
+			candidates: []priceCandidate{
+				{PriceListID: 1, ValidFrom: "2024-01-01", MinQuantity: 50, Price: 85},
+				{PriceListID: 2, ValidFrom: "2024-02-01", MinQuantity: 0, Price: 100},
+			},
+			want: priceCandidate{PriceListID: 2, ValidFrom: "2024-02-01", MinQuantity: 0, Price: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := bestPrice(tt.candidates)
+			if !ok || got != tt.want {
+				t.Errorf("bestPrice() = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := bestPrice(nil); ok {
+		t.Error("bestPrice() without candidates reported a price")
+	}
+}
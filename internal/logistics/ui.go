@@ -3,42 +3,430 @@ package logistics
 import (
 	"context"
 	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
 	"log/slog"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/tombuente/apex/internal/barcode"
+	"github.com/tombuente/apex/internal/database"
 	"github.com/tombuente/apex/internal/flash"
+	"github.com/tombuente/apex/internal/pdf"
 	"github.com/tombuente/apex/internal/templates"
 	"github.com/tombuente/apex/internal/xerrors"
 	"github.com/tombuente/apex/internal/xui"
 )
 
 type UI struct {
-	service   Service
-	templates map[string]*template.Template
+	service    Service
+	templates  map[string]*template.Template
+	letterhead pdf.Letterhead
 }
 
 type itemData struct {
 	Message    flash.Message
 	Resource   *Item
 	Categories []ItemCategory
+	Units      []Unit
+	ItemUnits  []ItemUnit
+	// Batches are the batches of a batch managed item in stock, first expiring first.
+	Batches []BatchStock
+	// SerialNumbers are the serial numbers of a serialized item in stock.
+	SerialNumbers []SerialNumber
+	PlantNames    map[int64]string
+	// Components are the lines of the bill of materials of the item, Items can be selected as further components.
+	Components []BOMLine
+	Items      []Item
+	// Cost is the cost of one base unit of the item rolled up from its bill of materials, CostError tells why it can not
+	// be rolled up.
+	Cost      int64
+	CostError string
+	// Planning are the planning parameters of the item per plant.
+	Planning  []PlanningParameters
+	Plants    []Plant
+	Suppliers []Supplier
+	// SupplierNames are the names of the suppliers by ID.
+	SupplierNames map[int64]string
+	// Attributes are the attribute inputs of the form, Variants the variants of the item and Parent the item a variant
+	// was generated from.
+	Attributes []attributeField
+	Variants   []Item
+	Parent     *Item
+	// TaxCategories can be selected for the item, its derived price is calculated with their rate.
+	TaxCategories []TaxCategory
+}
+
+// attributeField is an attribute input of the item form. Categories are the space separated IDs of the categories the
+// attribute applies to, the form only enables the attributes of the selected category.
+type attributeField struct {
+	ItemAttribute
+	Categories string
+	Applies    bool
+	Value      string
+}
+
+type itemListData struct {
+	Message    flash.Message
+	Resources  []Item
+	Query      url.Values
+	Categories []ItemCategory
+	// CategoryPaths are the paths of the categories by ID.
+	CategoryPaths map[int64]string
+}
+
+type itemCategoryData struct {
+	Message    flash.Message
+	Resource   *ItemCategory
+	Categories []ItemCategory
+	Units      []Unit
+	// Subcategories are the direct children of the category, Items the items of the category and of all subcategories.
+	Subcategories []ItemCategory
+	Items         []Item
+	// Attributes are the attributes of the category including the attributes inherited from its ancestors.
+	Attributes []ItemAttribute
+}
+
+type bomExplosionData struct {
+	Message   flash.Message
+	Explosion BOMExplosion
+}
+
+type customerData struct {
+	Message   flash.Message
+	Resource  *Customer
+	Addresses []Address
+	Groups    []CustomerGroup
+	// PriceLists are the price lists of the customer itself.
+	PriceLists []PriceList
+}
+
+type priceListData struct {
+	Message        flash.Message
+	Resource       *PriceList
+	Customers      []Customer
+	CustomerGroups []CustomerGroup
+	Prices         []Price
+	Items          []Item
+}
+
+// priceDeterminationData shows the effective price for the query, Result is empty until an item has been selected.
+type priceDeterminationData struct {
+	Message   flash.Message
+	Query     url.Values
+	Items     []Item
+	Customers []Customer
+	Result    *EffectivePrice
+	PriceList *PriceList
+}
+
+// plantNearbyData shows the plants near an address or position, Plants is empty until a position has been queried.
+type plantNearbyData struct {
+	Message   flash.Message
+	Query     url.Values
+	Addresses []Address
+	Plants    []PlantDistance
+	Searched  bool
+}
+
+// postalCodeData lists the countries postal codes have been imported for.
+type postalCodeData struct {
+	Message   flash.Message
+	Countries []PostalCodeCountry
+}
+
+type goodsMovementData struct {
+	Message  flash.Message
+	Resource *GoodsMovement
+	Items    []Item
+	Plants   []Plant
+	Bins     []Bin
+	Units    []Unit
+	Batch    Batch
+}
+
+type goodsMovementListData struct {
+	Message    flash.Message
+	Resources  []GoodsMovement
+	Query      url.Values
+	Items      []Item
+	Plants     []Plant
+	ItemNames  map[int64]string
+	PlantNames map[int64]string
+}
+
+type stockListData struct {
+	Message   flash.Message
+	Resources []Stock
+	Query     url.Values
+	Items     []Item
+	Plants    []Plant
+}
+
+type addressListData struct {
+	Message   flash.Message
+	Resources []Address
+	Query     url.Values
+	Countries []Country
+}
+
+type plantListData struct {
+	Message   flash.Message
+	Resources []Plant
+	Query     url.Values
+}
+
+// addressData offers Duplicate for reuse when the address of the create form already exists.
+type addressData struct {
+	Message   flash.Message
+	Resource  *Address
+	Countries []Country
+	Duplicate *Address
+}
+
+type supplierData struct {
+	Message   flash.Message
+	Resource  *Supplier
+	Addresses []Address
+}
+
+type purchaseOrderData struct {
+	Message   flash.Message
+	Resource  *PurchaseOrder
+	Suppliers []Supplier
+	Plants    []Plant
+	Items     []Item
+	Units     []Unit
+}
+
+type purchaseOrderListData struct {
+	Message       flash.Message
+	Resources     []PurchaseOrderHeader
+	Query         url.Values
+	Suppliers     []Supplier
+	SupplierNames map[int64]string
+	PlantNames    map[int64]string
+}
+
+type goodsReceiptData struct {
+	Message   flash.Message
+	Resource  PurchaseOrder
+	Date      string
+	ItemNames map[int64]string
+	// BaseUnitCodes are the codes of the base units of the items on the order by item ID.
+	BaseUnitCodes map[int64]string
+	Tracking      map[int64]string
+	Bins          []Bin
+}
+
+type salesOrderData struct {
+	Message    flash.Message
+	Resource   *SalesOrder
+	Customers  []Customer
+	Plants     []Plant
+	Addresses  []Address
+	Items      []Item
+	Units      []Unit
+	Deliveries []DeliveryHeader
+}
+
+type salesOrderListData struct {
+	Message       flash.Message
+	Resources     []SalesOrderHeader
+	Query         url.Values
+	Customers     []Customer
+	CustomerNames map[int64]string
+}
+
+type deliveryCreateData struct {
+	Message       flash.Message
+	Resource      SalesOrder
+	Date          string
+	ItemNames     map[int64]string
+	BaseUnitCodes map[int64]string
+	Tracking      map[int64]string
+	Bins          []Bin
+	// Batches are the batches in stock in the plant of the order by item ID, first expiring first.
+	Batches map[int64][]BatchStock
+	// SerialNumbers are the serial numbers in stock in the plant of the order by item ID.
+	SerialNumbers map[int64][]SerialNumber
+}
+
+type deliveryData struct {
+	Message         flash.Message
+	Resource        *Delivery
+	SalesOrder      SalesOrderHeader
+	Customer        Customer
+	ShippingAddress Address
+	ItemNames       map[int64]string
+	BaseUnitCodes   map[int64]string
+	BinNames        map[int64]string
+	Shipments       []ShipmentHeader
+	Carriers        []carrierData
+}
+
+type shipmentData struct {
+	Message  flash.Message
+	Resource *Shipment
+	Label    ShippingLabel
+}
+
+type shipmentListData struct {
+	Message   flash.Message
+	Resources []ShipmentHeader
+	Query     url.Values
+	Carriers  []carrierData
+}
+
+// carrierData lists a registered carrier with its services in forms.
+type carrierData struct {
+	Code     string
+	Name     string
+	Services []CarrierService
+}
+
+type deliveryListData struct {
+	Message    flash.Message
+	Resources  []DeliveryHeader
+	Query      url.Values
+	Plants     []Plant
+	PlantNames map[int64]string
 }
 
 type plantData struct {
 	Message   flash.Message
 	Resource  *Plant
 	Addresses []Address
+	Layout    plantLayout
+}
+
+// plantLayout is the storage location and bin hierarchy of a plant together with the stock in each bin.
+type plantLayout struct {
+	Locations []storageLocationLayout
+	// Unassigned is the stock of the plant that is not in any bin.
+	Unassigned []Stock
+}
+
+type storageLocationLayout struct {
+	StorageLocation
+	Bins []binLayout
+}
+
+// binLayout is a bin with its stock, Quantity is the stock of limited bins in their capacity unit.
+type binLayout struct {
+	Bin
+	Stock    []Stock
+	Quantity float64
+}
+
+type storageLocationData struct {
+	Message  flash.Message
+	Resource *StorageLocation
+	Plant    Plant
+	Bins     []Bin
+	Units    []Unit
+}
+
+type batchListData struct {
+	Message   flash.Message
+	Resources []Batch
+	Query     url.Values
+	Items     []Item
+	ItemNames map[int64]string
+}
+
+type batchData struct {
+	Message    flash.Message
+	Resource   *Batch
+	Item       Item
+	Stock      []BatchStock
+	Trace      []TraceMovement
+	PlantNames map[int64]string
+}
+
+type serialNumberListData struct {
+	Message    flash.Message
+	Resources  []SerialNumber
+	Query      url.Values
+	Items      []Item
+	Plants     []Plant
+	ItemNames  map[int64]string
+	PlantNames map[int64]string
+}
+
+type serialNumberData struct {
+	Message    flash.Message
+	Resource   *SerialNumber
+	Item       Item
+	Trace      []TraceMovement
+	PlantNames map[int64]string
+}
+
+type purchaseProposalListData struct {
+	Message     flash.Message
+	Resources   []PurchaseProposal
+	Query       url.Values
+	Projections []Projection
+	Items       []Item
+	Plants      []Plant
+	// ItemNames, BaseUnitCodes and PlantNames are by ID.
+	ItemNames     map[int64]string
+	BaseUnitCodes map[int64]string
+	PlantNames    map[int64]string
+	SupplierNames map[int64]string
+}
+
+type purchaseProposalData struct {
+	Message      flash.Message
+	Resource     *PurchaseProposal
+	Item         Item
+	Plant        Plant
+	BaseUnitCode string
+	Suppliers    []Supplier
+}
+
+type inventoryCountData struct {
+	Message  flash.Message
+	Resource *InventoryCount
+	Date     string
+	Plant    Plant
+	Plants   []Plant
+	// Items are the items that can be counted, serialized items are left out.
+	Items []Item
+}
+
+type inventoryCountListData struct {
+	Message    flash.Message
+	Resources  []InventoryCountHeader
+	Query      url.Values
+	Plants     []Plant
+	PlantNames map[int64]string
+}
+
+type binData struct {
+	Message  flash.Message
+	Resource *Bin
+	Stock    []Stock
+	Units    []Unit
 }
 
-func NewUIRouter(templateFS fs.FS, service Service) (*chi.Mux, error) {
+func NewUIRouter(templateFS fs.FS, service Service, letterhead pdf.Letterhead) (*chi.Mux, error) {
 	ui := UI{
-		service:   service,
-		templates: make(map[string]*template.Template),
+		service:    service,
+		templates:  make(map[string]*template.Template),
+		letterhead: letterhead,
 	}
 
 	var err error
@@ -52,28 +440,208 @@ func NewUIRouter(templateFS fs.FS, service Service) (*chi.Mux, error) {
 
 	r.Route("/items", func(r chi.Router) {
 		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalItemData, ui.templates["item-create"]))
+		r.Get("/pdf", ui.itemListPDF)
+		r.Get("/labels", ui.itemLabelsPDF)
 		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.item, ui.makeAdditionalItemData, ui.templates["item-detail"]))
-		r.Get("/", xui.ListView(ui.makeItemFilter, ui.service.items, ui.templates["item-list"]))
+		r.Get("/", ui.itemListView)
 		r.Post("/{id}", xui.Update(ui.service.updateItem))
+		r.Post("/{id}/units", ui.setItemUnit)
+		r.Post("/{id}/units/{unitID}/delete", ui.deleteItemUnit)
+		r.Get("/{id}/explosion", ui.bomExplosionView)
+		r.Post("/{id}/components", ui.setBOMLine)
+		r.Post("/{id}/components/{componentID}/delete", ui.deleteBOMLine)
+		r.Post("/{id}/planning", ui.setPlanningParameters)
+		r.Post("/{id}/planning/{plantID}/delete", ui.deletePlanningParameters)
+		r.Post("/{id}/variants", ui.createVariants)
 		r.Post("/", xui.Create(ui.service.createItem))
 	})
 
+	r.Route("/itemcategories", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalItemCategoryData, ui.templates["item-category-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.itemCategory, ui.makeAdditionalItemCategoryData, ui.templates["item-category-detail"]))
+		r.Get("/", xui.ListView(ui.makeItemCategoryFilter, ui.service.itemCategories, ui.templates["item-category-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateItemCategory))
+		r.Post("/{id}/attributes", ui.createItemAttribute)
+		r.Post("/{id}/attributes/{attributeID}/delete", ui.deleteItemAttribute)
+		r.Post("/", xui.Create(ui.service.createItemCategory))
+	})
+
+	r.Route("/units", func(r chi.Router) {
+		r.Get("/new", xui.CreateView[Unit](ui.templates["unit-create"]))
+		r.Get("/{id}", xui.Detail(ui.service.unit, ui.templates["unit-detail"]))
+		r.Get("/", xui.ListView(ui.makeUnitFilter, ui.service.units, ui.templates["unit-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateUnit))
+		r.Post("/", xui.Create(ui.service.createUnit))
+	})
+
 	r.Route("/plants", func(r chi.Router) {
 		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalPlantData, ui.templates["plant-create"]))
+		r.Get("/pdf", ui.plantListPDF)
+		r.Get("/map", xui.BasicView(ui.templates["plant-map"]))
+		r.Get("/geojson", ui.plantGeoJSON)
+		r.Get("/nearby", ui.plantNearbyView)
+		r.Get("/{id}/picking-list", ui.pickingListPDF)
 		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.plant, ui.makeAdditionalPlantData, ui.templates["plant-detail"]))
-		r.Get("/", xui.ListView(ui.makePlantFilter, ui.service.plants, ui.templates["plant-list"]))
+		r.Get("/", ui.plantListView)
 		r.Post("/{id}", xui.Update(ui.service.updatePlant))
 		r.Post("/", xui.Create(ui.service.createPlant))
 	})
 
 	r.Route("/addresses", func(r chi.Router) {
-		r.Get("/new", xui.CreateView[Address](ui.templates["address-create"]))
-		r.Get("/{id}", xui.Detail(ui.service.address, ui.templates["address-detail"]))
-		r.Get("/", xui.ListView(ui.makeAddressFilter, ui.service.addresses, ui.templates["address-list"]))
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalAddressData, ui.templates["address-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.address, ui.makeAdditionalAddressData, ui.templates["address-detail"]))
+		r.Get("/", ui.addressListView)
 		r.Post("/{id}", xui.Update(ui.service.updateAddress))
-		r.Post("/", xui.Create(ui.service.createAddress))
+		r.Post("/", ui.createAddress)
+	})
+
+	r.Route("/postal-codes", func(r chi.Router) {
+		r.Get("/", ui.postalCodeView)
+		r.Post("/", ui.importPostalCodes)
+	})
+
+	r.Route("/tax-categories", func(r chi.Router) {
+		r.Get("/new", xui.CreateView[TaxCategory](ui.templates["tax-category-create"]))
+		r.Get("/{id}", xui.Detail(ui.service.taxCategory, ui.templates["tax-category-detail"]))
+		r.Get("/", xui.ListView(ui.makeTaxCategoryFilter, ui.service.taxCategories, ui.templates["tax-category-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateTaxCategory))
+		r.Post("/", xui.Create(ui.service.createTaxCategory))
+	})
+
+	r.Route("/currency-roundings", func(r chi.Router) {
+		r.Get("/", xui.ListView(ui.makeCurrencyRoundingFilter, ui.service.currencyRoundings, ui.templates["currency-rounding-list"]))
+		r.Post("/{currency}/delete", ui.deleteCurrencyRounding)
+		r.Post("/", xui.Create(ui.service.setCurrencyRounding))
+	})
+
+	r.Route("/customer-groups", func(r chi.Router) {
+		r.Get("/new", xui.CreateView[CustomerGroup](ui.templates["customer-group-create"]))
+		r.Get("/{id}", xui.Detail(ui.service.customerGroup, ui.templates["customer-group-detail"]))
+		r.Get("/", xui.ListView(ui.makeCustomerGroupFilter, ui.service.customerGroups, ui.templates["customer-group-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateCustomerGroup))
+		r.Post("/", xui.Create(ui.service.createCustomerGroup))
+	})
+
+	r.Route("/price-lists", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalPriceListData, ui.templates["price-list-create"]))
+		r.Get("/determination", ui.priceDeterminationView)
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.priceList, ui.makeAdditionalPriceListData, ui.templates["price-list-detail"]))
+		r.Get("/", xui.ListView(ui.makePriceListFilter, ui.service.priceLists, ui.templates["price-list-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updatePriceList))
+		r.Post("/{id}/prices", ui.setPrice)
+		r.Post("/{id}/prices/{priceID}/delete", ui.deletePrice)
+		r.Post("/", xui.Create(ui.service.createPriceList))
+	})
+
+	r.Route("/customers", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalCustomerData, ui.templates["customer-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.customer, ui.makeAdditionalCustomerData, ui.templates["customer-detail"]))
+		r.Get("/", xui.ListView(ui.makeCustomerFilter, ui.service.customers, ui.templates["customer-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateCustomer))
+		r.Post("/", xui.Create(ui.service.createCustomer))
+	})
+
+	r.Route("/suppliers", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalSupplierData, ui.templates["supplier-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.supplier, ui.makeAdditionalSupplierData, ui.templates["supplier-detail"]))
+		r.Get("/", xui.ListView(ui.makeSupplierFilter, ui.service.suppliers, ui.templates["supplier-list"]))
+		r.Post("/{id}", xui.Update(ui.service.updateSupplier))
+		r.Post("/", xui.Create(ui.service.createSupplier))
+	})
+
+	r.Route("/purchase-orders", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalPurchaseOrderData, ui.templates["purchase-order-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.purchaseOrder, ui.makeAdditionalPurchaseOrderData, ui.templates["purchase-order-detail"]))
+		r.Get("/{id}/receipt", ui.goodsReceiptView)
+		r.Get("/", ui.purchaseOrderListView)
+		r.Post("/{id}", xui.UpdateWithFormParser(parsePurchaseOrderForm, ui.service.updatePurchaseOrder))
+		r.Post("/{id}/cancel", ui.cancelPurchaseOrder)
+		r.Post("/{id}/receipt", ui.receivePurchaseOrder)
+		r.Post("/", xui.CreateWithFormParser(parsePurchaseOrderForm, ui.service.createPurchaseOrder))
 	})
 
+	r.Route("/sales-orders", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalSalesOrderData, ui.templates["sales-order-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.salesOrder, ui.makeAdditionalSalesOrderData, ui.templates["sales-order-detail"]))
+		r.Get("/{id}/delivery", ui.deliveryCreateView)
+		r.Get("/", ui.salesOrderListView)
+		r.Post("/{id}", xui.UpdateWithFormParser(parseSalesOrderForm, ui.service.updateSalesOrder))
+		r.Post("/{id}/cancel", ui.cancelSalesOrder)
+		r.Post("/{id}/delivery", ui.createDelivery)
+		r.Post("/", xui.CreateWithFormParser(parseSalesOrderForm, ui.service.createSalesOrder))
+	})
+
+	r.Route("/deliveries", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.delivery, ui.makeAdditionalDeliveryData, ui.templates["delivery-detail"]))
+		r.Get("/", ui.deliveryListView)
+		r.Post("/{id}/ship", ui.shipDelivery)
+		r.Post("/{id}/cancel", ui.cancelDelivery)
+		r.Post("/{id}/shipments", ui.createShipment)
+	})
+
+	r.Route("/shipments", func(r chi.Router) {
+		r.Get("/{id}/label", ui.shippingLabel)
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.shipment, ui.makeAdditionalShipmentData, ui.templates["shipment-detail"]))
+		r.Get("/", ui.shipmentListView)
+		r.Post("/{id}/packages", ui.createShipmentPackage)
+		r.Post("/{id}/packages/{packageID}/delete", ui.deleteShipmentPackage)
+		r.Post("/{id}/label", ui.createShippingLabel)
+		r.Post("/{id}/cancel", ui.cancelShipment)
+	})
+
+	r.Route("/purchase-proposals", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.purchaseProposal, ui.makeAdditionalPurchaseProposalData, ui.templates["purchase-proposal-detail"]))
+		r.Get("/", ui.purchaseProposalListView)
+		r.Post("/run", ui.planningRun)
+		r.Post("/{id}", xui.Update(ui.service.updatePurchaseProposal))
+		r.Post("/{id}/accept", ui.acceptPurchaseProposal)
+		r.Post("/{id}/discard", ui.discardPurchaseProposal)
+	})
+
+	r.Route("/inventory-counts", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalInventoryCountData, ui.templates["inventory-count-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.inventoryCount, ui.makeAdditionalInventoryCountData, ui.templates["inventory-count-detail"]))
+		r.Get("/{id}/sheet", ui.countSheet)
+		r.Get("/", ui.inventoryCountListView)
+		r.Post("/{id}/counts", ui.setCountedQuantities)
+		r.Post("/{id}/sheet", ui.importCountSheet)
+		r.Post("/{id}/approve", ui.approveInventoryCount)
+		r.Post("/{id}/cancel", ui.cancelInventoryCount)
+		r.Post("/", xui.CreateWithFormParser(parseInventoryCountForm, ui.service.createInventoryCount))
+	})
+
+	r.Route("/movements", func(r chi.Router) {
+		r.Get("/new", xui.CreateViewWithData(ui.makeAdditionalGoodsMovementData, ui.templates["movement-create"]))
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.goodsMovement, ui.makeAdditionalGoodsMovementData, ui.templates["movement-detail"]))
+		r.Get("/", ui.goodsMovementListView)
+		r.Post("/", xui.Create(ui.service.createGoodsMovement))
+	})
+
+	r.Route("/storage-locations", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.storageLocation, ui.makeAdditionalStorageLocationData, ui.templates["storage-location-detail"]))
+		r.Post("/{id}", xui.Update(ui.service.updateStorageLocation))
+		r.Post("/", xui.Create(ui.service.createStorageLocation))
+	})
+
+	r.Route("/bins", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.bin, ui.makeAdditionalBinData, ui.templates["bin-detail"]))
+		r.Post("/{id}", xui.Update(ui.service.updateBin))
+		r.Post("/", xui.Create(ui.service.createBin))
+	})
+
+	r.Route("/batches", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.batch, ui.makeAdditionalBatchData, ui.templates["batch-detail"]))
+		r.Get("/", ui.batchListView)
+		r.Post("/{id}", xui.Update(ui.service.updateBatch))
+	})
+
+	r.Route("/serial-numbers", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.serialNumber, ui.makeAdditionalSerialNumberData, ui.templates["serial-number-detail"]))
+		r.Get("/", ui.serialNumberListView)
+	})
+
+	r.Get("/stock", ui.stockListView)
+
 	return r, nil
 }
 
@@ -86,93 +654,3515 @@ func (ui UI) indexView(w http.ResponseWriter, r *http.Request) {
 }
 
 func (ui UI) makeAdditionalItemData(ctx context.Context, w http.ResponseWriter, r *http.Request, item *Item) (itemData, error) {
-	categories, err := ui.service.itemCategories(ctx)
+	categories, err := ui.service.itemCategories(ctx, ItemCategoryFilter{})
 	if err != nil {
 		return itemData{}, err
 	}
 
-	return itemData{
-		Message:    flash.Get(w, r),
-		Resource:   item,
-		Categories: categories,
-	}, nil
-}
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil {
+		return itemData{}, err
+	}
 
-func (ui UI) makeItemFilter(ctx context.Context, values url.Values) (ItemFilter, error) {
-	name := values.Get("name")
-	sku := values.Get("sku")
-	categoryID := values.Get("category_id")
-	grossPrice := values.Get("gross_price")
-	netPrice := values.Get("net_price")
+	taxCategories, err := ui.service.taxCategories(ctx, TaxCategoryFilter{})
+	if err != nil {
+		return itemData{}, err
+	}
 
-	filter := ItemFilter{}
+	data := itemData{
+		Message:       flash.Get(w, r),
+		Resource:      item,
+		Categories:    categories,
+		Units:         units,
+		TaxCategories: taxCategories,
+	}
 
-	if name != "" {
-		filter.name = sql.NullString{Valid: true, String: name}
+	if data.Attributes, err = ui.attributeFields(ctx, categories, item); err != nil {
+		return itemData{}, err
 	}
 
-	if sku != "" {
-		filter.sku = sql.NullString{Valid: true, String: sku}
+	if item == nil {
+		return data, nil
 	}
 
-	if categoryID != "" {
-		categoryID, err := strconv.ParseInt(categoryID, 10, 64)
+	if item.ParentID.Valid {
+		parent, err := ui.service.item(ctx, item.ParentID.Int64)
 		if err != nil {
-			return ItemFilter{}, fmt.Errorf("%w: unable to convert category id to integer", xerrors.ErrBadRequest)
+			return itemData{}, err
+		}
+		data.Parent = &parent
+	} else {
+		data.Variants, err = ui.service.items(ctx, ItemFilter{parentID: sql.NullInt64{Valid: true, Int64: item.ID}})
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return itemData{}, err
 		}
+	}
 
-		filter.categoryID = sql.NullInt64{Valid: true, Int64: categoryID}
+	data.ItemUnits, err = ui.service.itemUnits(ctx, item.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemData{}, err
 	}
 
-	if grossPrice != "" {
-		grossPrice, err := strconv.ParseInt(grossPrice, 10, 64)
-		if err != nil {
-			return ItemFilter{}, fmt.Errorf("%w: unable to convert gross price to integer", xerrors.ErrBadRequest)
+	switch item.Tracking {
+	case TrackingBatch:
+		data.Batches, err = ui.service.batchStock(ctx, item.ID, sql.NullInt64{})
+	case TrackingSerial:
+		data.SerialNumbers, err = ui.service.serialNumbers(ctx, SerialNumberFilter{itemID: sql.NullInt64{Valid: true, Int64: item.ID}})
+	}
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemData{}, err
+	}
+
+	if data.PlantNames, err = ui.plantNames(ctx); err != nil {
+		return itemData{}, err
+	}
+
+	data.Components, err = ui.service.bomLines(ctx, item.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemData{}, err
+	}
+
+	data.Items, err = ui.service.items(ctx, ItemFilter{})
+	if err != nil {
+		return itemData{}, err
+	}
+
+	data.Cost, err = ui.service.rolledUpCost(ctx, item.ID)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		data.CostError = err.Error()
+	} else if err != nil {
+		return itemData{}, err
+	}
+
+	data.Planning, err = ui.service.planningParameters(ctx, item.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemData{}, err
+	}
+
+	if _, data.Plants, err = ui.itemsAndPlants(ctx); err != nil {
+		return itemData{}, err
+	}
+
+	data.Suppliers, err = ui.service.suppliers(ctx, SupplierFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemData{}, err
+	}
+	data.SupplierNames = make(map[int64]string, len(data.Suppliers))
+	for _, supplier := range data.Suppliers {
+		data.SupplierNames[supplier.ID] = supplier.Name
+	}
+
+	return data, nil
+}
+
+// attributeFields returns the inputs of all attributes with the values of the item. Attributes apply to the items of
+// their category and of all subcategories, new items start in the first category.
+func (ui UI) attributeFields(ctx context.Context, categories []ItemCategory, item *Item) ([]attributeField, error) {
+	attributes, err := ui.service.itemAttributes(ctx, 0)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[int64]string)
+	var categoryID int64
+	if item != nil {
+		categoryID = item.CategoryID
+
+		itemValues, err := ui.service.itemAttributeValues(ctx, item.ID)
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return nil, err
+		}
+		for _, value := range itemValues {
+			values[value.AttributeID] = value.Value
 		}
+	} else if len(categories) > 0 {
+		categoryID = categories[0].ID
+	}
 
-		filter.categoryID = sql.NullInt64{Valid: true, Int64: grossPrice}
+	parents := make(map[int64]sql.NullInt64, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
 	}
 
-	if netPrice != "" {
-		netPrice, err := strconv.ParseInt(netPrice, 10, 64)
-		if err != nil {
-			return ItemFilter{}, fmt.Errorf("%w: unable to convert net price to integer", xerrors.ErrBadRequest)
+	fields := make([]attributeField, 0, len(attributes))
+	for _, attribute := range attributes {
+		field := attributeField{ItemAttribute: attribute, Value: values[attribute.ID]}
+
+		var applies []string
+		for _, category := range categories {
+			for id := (sql.NullInt64{Valid: true, Int64: category.ID}); id.Valid; id = parents[id.Int64] {
+				if id.Int64 == attribute.CategoryID {
+					applies = append(applies, category.GetID())
+					field.Applies = field.Applies || category.ID == categoryID
+					break
+				}
+			}
 		}
+		field.Categories = strings.Join(applies, " ")
 
-		filter.categoryID = sql.NullInt64{Valid: true, Int64: netPrice}
+		fields = append(fields, field)
 	}
 
-	return filter, nil
+	return fields, nil
 }
 
-func (ui UI) makeAddressFilter(ctx context.Context, values url.Values) (AddressFilter, error) {
-	// TODO: Remove dummy filter
-	filter := AddressFilter{
-		zip:       sql.NullString{},
-		city:      sql.NullString{},
-		street:    sql.NullString{},
-		country:   sql.NullString{},
-		latitude:  sql.NullFloat64{},
-		longitude: sql.NullFloat64{},
+func (ui UI) createVariants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
 	}
 
-	return filter, nil
-}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
 
-func (ui UI) makeAdditionalPlantData(ctx context.Context, w http.ResponseWriter, r *http.Request, plant *Plant) (plantData, error) {
-	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	params := VariantParams{Options: make(map[int64][]string)}
+	for key, options := range r.PostForm {
+		attribute, ok := strings.CutPrefix(key, "options[")
+		if !ok {
+			continue
+		}
+
+		attributeID, err := strconv.ParseInt(strings.TrimSuffix(attribute, "]"), 10, 64)
+		if err != nil {
+			http.Error(w, "malformatted attribute id", http.StatusBadRequest)
+			return
+		}
+		params.Options[attributeID] = options
+	}
+
+	variants, err := ui.service.createVariants(r.Context(), id, params)
 	if err != nil {
-		return plantData{}, err
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to create variants", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
 	}
 
-	return plantData{
-		Message:   flash.Get(w, r),
-		Resource:  plant,
-		Addresses: addresses,
-	}, nil
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: fmt.Sprintf("Success! %v variants have been created.", len(variants))})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
 }
 
-func (ui UI) makePlantFilter(ctx context.Context, values url.Values) (PlantFilter, error) {
-	// TODO: Remove dummy filter
-	return PlantFilter{}, nil
+func (ui UI) createItemAttribute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params ItemAttributeParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		http.Error(w, "unable to decode form", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := ui.service.createItemAttribute(r.Context(), id, params); err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to create item attribute", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The attribute has been added."})
+	http.Redirect(w, r, "/logistics/itemcategories/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) deleteItemAttribute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	attributeID, err := strconv.ParseInt(chi.URLParam(r, "attributeID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted attribute id", http.StatusBadRequest)
+		return
+	}
+
+	if err := ui.service.deleteItemAttribute(r.Context(), id, attributeID); err != nil {
+		slog.Error("Unable to delete item attribute", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The attribute has been removed."})
+	http.Redirect(w, r, "/logistics/itemcategories/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) setItemUnit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params ItemUnitParams
+	if params.UnitID, err = strconv.ParseInt(r.PostForm.Get("unit_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted unit id", http.StatusBadRequest)
+		return
+	}
+	if params.Factor, err = strconv.ParseFloat(r.PostForm.Get("factor"), 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: conversion factor has to be a number", xerrors.ErrBadRequest))
+		return
+	}
+
+	if _, err := ui.service.setItemUnit(r.Context(), id, params); err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to set item unit", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The unit has been saved."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) deleteItemUnit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	unitID, err := strconv.ParseInt(chi.URLParam(r, "unitID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted unit id", http.StatusBadRequest)
+		return
+	}
+
+	if err := ui.service.deleteItemUnit(r.Context(), id, unitID); err != nil {
+		slog.Error("Unable to delete item unit", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The unit has been removed."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) setBOMLine(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params BOMLineParams
+	if params.ComponentID, err = strconv.ParseInt(r.PostForm.Get("component_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted component id", http.StatusBadRequest)
+		return
+	}
+	if params.UnitID, err = strconv.ParseInt(r.PostForm.Get("unit_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted unit id", http.StatusBadRequest)
+		return
+	}
+	if params.Quantity, err = strconv.ParseFloat(r.PostForm.Get("quantity"), 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: component quantity has to be a number", xerrors.ErrBadRequest))
+		return
+	}
+
+	if err := ui.service.setBOMLine(r.Context(), id, params); err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to set bill of materials line", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The component has been saved."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) deleteBOMLine(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	componentID, err := strconv.ParseInt(chi.URLParam(r, "componentID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted component id", http.StatusBadRequest)
+		return
+	}
+
+	if err := ui.service.deleteBOMLine(r.Context(), id, componentID); err != nil {
+		slog.Error("Unable to delete bill of materials line", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The component has been removed."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) setPlanningParameters(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params PlanningParametersParams
+	if params.PlantID, err = strconv.ParseInt(r.PostForm.Get("plant_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted plant id", http.StatusBadRequest)
+		return
+	}
+	if params.SupplierID, err = strconv.ParseInt(r.PostForm.Get("supplier_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted supplier id", http.StatusBadRequest)
+		return
+	}
+	if params.ReorderPoint, err = strconv.ParseFloat(r.PostForm.Get("reorder_point"), 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: reorder point has to be a number", xerrors.ErrBadRequest))
+		return
+	}
+	if params.SafetyStock, err = strconv.ParseFloat(r.PostForm.Get("safety_stock"), 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: safety stock has to be a number", xerrors.ErrBadRequest))
+		return
+	}
+	if params.LotSize, err = strconv.ParseFloat(r.PostForm.Get("lot_size"), 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: lot size has to be a number", xerrors.ErrBadRequest))
+		return
+	}
+	if params.LeadTimeDays, err = strconv.Atoi(r.PostForm.Get("lead_time_days")); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: lead time has to be a whole number of days", xerrors.ErrBadRequest))
+		return
+	}
+
+	if _, err := ui.service.setPlanningParameters(r.Context(), id, params); err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to set planning parameters", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The planning parameters have been saved."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) deletePlanningParameters(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	plantID, err := strconv.ParseInt(chi.URLParam(r, "plantID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted plant id", http.StatusBadRequest)
+		return
+	}
+
+	if err := ui.service.deletePlanningParameters(r.Context(), id, plantID); err != nil {
+		slog.Error("Unable to delete planning parameters", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The planning parameters have been removed."})
+	http.Redirect(w, r, "/logistics/items/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+// bomExplosionView explodes the bill of materials of an item for the quantity query parameter, one base unit if it is
+// not set.
+func (ui UI) bomExplosionView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	quantity := 1.0
+	if value := r.URL.Query().Get("quantity"); value != "" {
+		if quantity, err = strconv.ParseFloat(value, 64); err != nil {
+			http.Error(w, "malformatted quantity", http.StatusBadRequest)
+			return
+		}
+	}
+
+	explosion, err := ui.service.bomExplosion(r.Context(), id, quantity)
+	if err != nil {
+		slog.Error("Unable to explode bill of materials", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	data := bomExplosionData{
+		Message:   flash.Get(w, r),
+		Explosion: explosion,
+	}
+
+	if err := ui.templates["bom-explosion"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) itemListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := ui.makeItemFilter(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := ui.service.items(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := ui.service.itemCategories(r.Context(), ItemCategoryFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query item categories", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := itemListData{
+		Message:       flash.Get(w, r),
+		Resources:     items,
+		Query:         query,
+		Categories:    categories,
+		CategoryPaths: make(map[int64]string, len(categories)),
+	}
+	for _, category := range categories {
+		data.CategoryPaths[category.ID] = category.Path
+	}
+
+	if err := ui.templates["item-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// categoryPaths returns the paths of all categories by ID.
+func (ui UI) categoryPaths(ctx context.Context) (map[int64]string, error) {
+	categories, err := ui.service.itemCategories(ctx, ItemCategoryFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	paths := make(map[int64]string, len(categories))
+	for _, category := range categories {
+		paths[category.ID] = category.Path
+	}
+
+	return paths, nil
+}
+
+func (ui UI) makeItemCategoryFilter(ctx context.Context, values url.Values) (ItemCategoryFilter, error) {
+	parentID, err := parseNullID(values, "parent_id")
+	if err != nil {
+		return ItemCategoryFilter{}, err
+	}
+
+	return ItemCategoryFilter{parentID: parentID}, nil
+}
+
+func (ui UI) makeAdditionalItemCategoryData(ctx context.Context, w http.ResponseWriter, r *http.Request, category *ItemCategory) (itemCategoryData, error) {
+	categories, err := ui.service.itemCategories(ctx, ItemCategoryFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemCategoryData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemCategoryData{}, err
+	}
+
+	data := itemCategoryData{
+		Message:    flash.Get(w, r),
+		Resource:   category,
+		Categories: categories,
+		Units:      units,
+	}
+	if category == nil {
+		return data, nil
+	}
+
+	data.Subcategories, err = ui.service.itemCategories(ctx, ItemCategoryFilter{parentID: sql.NullInt64{Valid: true, Int64: category.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemCategoryData{}, err
+	}
+
+	data.Items, err = ui.service.items(ctx, ItemFilter{categoryID: sql.NullInt64{Valid: true, Int64: category.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemCategoryData{}, err
+	}
+
+	data.Attributes, err = ui.service.itemAttributes(ctx, category.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemCategoryData{}, err
+	}
+
+	return data, nil
+}
+
+func (ui UI) makeUnitFilter(ctx context.Context, values url.Values) (UnitFilter, error) {
+	return UnitFilter{}, nil
+}
+
+func (ui UI) makeItemFilter(ctx context.Context, values url.Values) (ItemFilter, error) {
+	name := values.Get("name")
+	sku := values.Get("sku")
+	categoryID := values.Get("category_id")
+	grossPrice := values.Get("gross_price")
+	netPrice := values.Get("net_price")
+
+	filter := ItemFilter{}
+
+	if name != "" {
+		filter.name = sql.NullString{Valid: true, String: name}
+	}
+
+	if sku != "" {
+		filter.sku = sql.NullString{Valid: true, String: sku}
+	}
+
+	if categoryID != "" {
+		categoryID, err := strconv.ParseInt(categoryID, 10, 64)
+		if err != nil {
+			return ItemFilter{}, fmt.Errorf("%w: unable to convert category id to integer", xerrors.ErrBadRequest)
+		}
+
+		filter.categoryID = sql.NullInt64{Valid: true, Int64: categoryID}
+	}
+
+	if grossPrice != "" {
+		grossPrice, err := strconv.ParseInt(grossPrice, 10, 64)
+		if err != nil {
+			return ItemFilter{}, fmt.Errorf("%w: unable to convert gross price to integer", xerrors.ErrBadRequest)
+		}
+
+		filter.grossPrice = sql.NullInt64{Valid: true, Int64: grossPrice}
+	}
+
+	if netPrice != "" {
+		netPrice, err := strconv.ParseInt(netPrice, 10, 64)
+		if err != nil {
+			return ItemFilter{}, fmt.Errorf("%w: unable to convert net price to integer", xerrors.ErrBadRequest)
+		}
+
+		filter.netPrice = sql.NullInt64{Valid: true, Int64: netPrice}
+	}
+
+	if tracking := values.Get("tracking"); tracking != "" {
+		filter.tracking = sql.NullString{Valid: true, String: tracking}
+	}
+
+	if gtin := values.Get("gtin"); gtin != "" {
+		filter.gtin = sql.NullString{Valid: true, String: gtin}
+	}
+
+	var err error
+	if filter.parentID, err = parseNullID(values, "parent_id"); err != nil {
+		return ItemFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (ui UI) itemListPDF(w http.ResponseWriter, r *http.Request) {
+	filter, err := ui.makeItemFilter(r.Context(), r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := ui.service.items(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	categoryNames, err := ui.categoryPaths(r.Context())
+	if err != nil {
+		slog.Error("Unable to query item categories", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	var rows [][]string
+	for _, item := range items {
+		rows = append(rows, []string{item.GetID(), item.Name, item.SKU, categoryNames[item.CategoryID],
+			strconv.FormatInt(item.NetPrice, 10), strconv.FormatInt(item.GrossPrice, 10)})
+	}
+
+	report := pdf.NewReport(ui.letterhead, "Items")
+	report.Table([]pdf.Column{
+		{Title: "ID", Width: 1},
+		{Title: "Name", Width: 4},
+		{Title: "SKU", Width: 2.5},
+		{Title: "Category", Width: 2.5},
+		{Title: "Net price", Width: 1.5, Align: pdf.Right},
+		{Title: "Gross price", Width: 1.5, Align: pdf.Right},
+	}, rows)
+	report.Paragraph(fmt.Sprintf("%d items", len(items)))
+
+	xui.ServePDF(w, "items.pdf", report)
+}
+
+// itemLabelsPDF prints a label sheet for the items selected in the item list, see ItemLabelParams.
+func (ui UI) itemLabelsPDF(w http.ResponseWriter, r *http.Request) {
+	var params ItemLabelParams
+	if err := xui.Decoder.Decode(&params, r.URL.Query()); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: unable to decode label parameters", xerrors.ErrBadRequest))
+		return
+	}
+
+	labels, err := ui.service.itemLabels(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to create item labels", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	xui.ServePDF(w, "item-labels.pdf", itemLabelSheet(labels))
+}
+
+// Item label sheets have 3 by 8 labels on A4 paper, which fits common 70 x 37 mm label sheets.
+const (
+	itemLabelColumns = 3
+	itemLabelRows    = 8
+	itemLabelPadding = 10
+)
+
+// itemLabelSheet lays out the labels row by row. Linear barcodes are printed below the item name, QR codes left of it.
+func itemLabelSheet(labels []ItemLabel) *pdf.Document {
+	document := pdf.New(pdf.A4Width, pdf.A4Height)
+	width := pdf.A4Width / itemLabelColumns
+	height := pdf.A4Height / itemLabelRows
+
+	for i, label := range labels {
+		if i%(itemLabelColumns*itemLabelRows) == 0 {
+			document.AddPage()
+		}
+		cell := i % (itemLabelColumns * itemLabelRows)
+		left := float64(cell%itemLabelColumns) * width
+		top := float64(cell/itemLabelColumns) * height
+
+		x := left + itemLabelPadding
+		textWidth := width - 2*itemLabelPadding
+		if label.Matrix != nil {
+			size := height - 2*itemLabelPadding
+			document.Matrix(x, top+itemLabelPadding, size/float64(len(label.Matrix)), label.Matrix)
+			x += size + 6
+			textWidth -= size + 6
+		}
+		text := func(y float64, font pdf.Font, size float64, s string) {
+			document.Text(x, y, font, size, pdf.Left, pdf.Truncate(font, size, textWidth, s))
+		}
+
+		text(top+itemLabelPadding+8, pdf.HelveticaBold, 8, label.Name)
+		if label.Text != label.SKU {
+			text(top+itemLabelPadding+18, pdf.Helvetica, 7, "SKU "+label.SKU)
+		}
+
+		if label.Matrix != nil {
+			if label.Text != label.SKU {
+				text(top+itemLabelPadding+28, pdf.Helvetica, 7, "GTIN "+label.Text)
+			} else {
+				text(top+itemLabelPadding+18, pdf.Helvetica, 7, label.SKU)
+			}
+			continue
+		}
+
+		moduleWidth := min(1.5, textWidth/float64(len(label.Bars)+20))
+		barsWidth := moduleWidth * float64(len(label.Bars))
+		document.Bars(left+(width-barsWidth)/2, top+itemLabelPadding+24, moduleWidth, 44, label.Bars)
+		document.Text(left+width/2, top+itemLabelPadding+78, pdf.Helvetica, 8, pdf.Center,
+			pdf.Truncate(pdf.Helvetica, 8, textWidth, label.Text))
+	}
+
+	return document
+}
+
+// addressListView lists the addresses matching the filter of the query, see makeAddressFilter.
+func (ui UI) addressListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := ui.makeAddressFilter(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	addresses, err := ui.service.addresses(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query addresses", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	countries, err := ui.service.countries(r.Context(), CountryFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query countries", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := addressListData{
+		Message:   flash.Get(w, r),
+		Resources: addresses,
+		Query:     query,
+		Countries: countries,
+	}
+	if err := ui.templates["address-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeAddressFilter(ctx context.Context, values url.Values) (AddressFilter, error) {
+	filter := AddressFilter{
+		zip:     database.ContainsPattern(values.Get("zip")),
+		city:    database.ContainsPattern(values.Get("city")),
+		street:  database.ContainsPattern(values.Get("street")),
+		country: database.ContainsPattern(values.Get("country")),
+	}
+
+	var err error
+	if filter.box, err = parseBoundingBox(values); err != nil {
+		return AddressFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseBoundingBox parses the optional bounds min_latitude, max_latitude, min_longitude and max_longitude of a
+// geographic filter.
+func parseBoundingBox(values url.Values) (boundingBoxFilter, error) {
+	var box boundingBoxFilter
+	bounds := []struct {
+		key   string
+		limit float64
+		value *sql.NullFloat64
+	}{
+		{"min_latitude", 90, &box.minLatitude},
+		{"max_latitude", 90, &box.maxLatitude},
+		{"min_longitude", 180, &box.minLongitude},
+		{"max_longitude", 180, &box.maxLongitude},
+	}
+
+	for _, bound := range bounds {
+		value := values.Get(bound.key)
+		if value == "" {
+			continue
+		}
+
+		degrees, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.Abs(degrees) > bound.limit {
+			return boundingBoxFilter{}, fmt.Errorf("%w: %v has to be a number between -%v and %v", xerrors.ErrBadRequest, bound.key, bound.limit, bound.limit)
+		}
+		*bound.value = sql.NullFloat64{Valid: true, Float64: degrees}
+	}
+
+	if box.minLatitude.Valid && box.maxLatitude.Valid && box.minLatitude.Float64 > box.maxLatitude.Float64 {
+		return boundingBoxFilter{}, fmt.Errorf("%w: min_latitude can not be greater than max_latitude", xerrors.ErrBadRequest)
+	}
+
+	return box, nil
+}
+
+func (ui UI) makeAdditionalPlantData(ctx context.Context, w http.ResponseWriter, r *http.Request, plant *Plant) (plantData, error) {
+	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	if err != nil {
+		return plantData{}, err
+	}
+
+	data := plantData{
+		Message:   flash.Get(w, r),
+		Resource:  plant,
+		Addresses: addresses,
+	}
+
+	if plant != nil {
+		data.Layout, err = ui.makePlantLayout(ctx, plant.ID)
+		if err != nil {
+			return plantData{}, err
+		}
+	}
+
+	return data, nil
+}
+
+func (ui UI) makePlantLayout(ctx context.Context, plantID int64) (plantLayout, error) {
+	locations, err := ui.service.storageLocations(ctx, StorageLocationFilter{plantID: sql.NullInt64{Valid: true, Int64: plantID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return plantLayout{}, err
+	}
+
+	bins, err := ui.service.bins(ctx, BinFilter{plantID: sql.NullInt64{Valid: true, Int64: plantID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return plantLayout{}, err
+	}
+
+	stock, err := ui.service.stock(ctx, StockFilter{plantID: sql.NullInt64{Valid: true, Int64: plantID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return plantLayout{}, err
+	}
+
+	layout := plantLayout{}
+	binStock := make(map[int64][]Stock)
+	for _, s := range stock {
+		if s.BinID.Valid {
+			binStock[s.BinID.Int64] = append(binStock[s.BinID.Int64], s)
+		} else {
+			layout.Unassigned = append(layout.Unassigned, s)
+		}
+	}
+
+	for _, location := range locations {
+		locationLayout := storageLocationLayout{StorageLocation: location}
+		for _, bin := range bins {
+			if bin.StorageLocationID != location.ID {
+				continue
+			}
+
+			b := binLayout{Bin: bin, Stock: binStock[bin.ID]}
+			if bin.Capacity > 0 {
+				load, err := ui.service.binLoad(ctx, bin)
+				if err != nil {
+					return plantLayout{}, err
+				}
+				b.Quantity = load.Quantity
+			}
+			locationLayout.Bins = append(locationLayout.Bins, b)
+		}
+		layout.Locations = append(layout.Locations, locationLayout)
+	}
+
+	return layout, nil
+}
+
+func (ui UI) makeAdditionalStorageLocationData(ctx context.Context, w http.ResponseWriter, r *http.Request, location *StorageLocation) (storageLocationData, error) {
+	plant, err := ui.service.plant(ctx, location.PlantID)
+	if err != nil {
+		return storageLocationData{}, err
+	}
+
+	bins, err := ui.service.bins(ctx, BinFilter{storageLocationID: sql.NullInt64{Valid: true, Int64: location.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return storageLocationData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return storageLocationData{}, err
+	}
+
+	return storageLocationData{
+		Message:  flash.Get(w, r),
+		Resource: location,
+		Plant:    plant,
+		Bins:     bins,
+		Units:    units,
+	}, nil
+}
+
+func (ui UI) makeAdditionalBinData(ctx context.Context, w http.ResponseWriter, r *http.Request, bin *Bin) (binData, error) {
+	stock, err := ui.service.stock(ctx, StockFilter{binID: sql.NullInt64{Valid: true, Int64: bin.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return binData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return binData{}, err
+	}
+
+	return binData{
+		Message:  flash.Get(w, r),
+		Resource: bin,
+		Stock:    stock,
+		Units:    units,
+	}, nil
+}
+
+func (ui UI) plantListPDF(w http.ResponseWriter, r *http.Request) {
+	filter, err := ui.makePlantFilter(r.Context(), r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plants, err := ui.service.plants(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	addresses, err := ui.service.addresses(r.Context(), AddressFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query addresses", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	addressByID := make(map[int64]Address, len(addresses))
+	for _, address := range addresses {
+		addressByID[address.ID] = address
+	}
+
+	var rows [][]string
+	for _, plant := range plants {
+		address := addressByID[plant.AddressID]
+		rows = append(rows, []string{plant.GetID(), plant.Name, address.Street, address.ZIP + " " + address.City, address.Country})
+	}
+
+	report := pdf.NewReport(ui.letterhead, "Plants")
+	report.Table([]pdf.Column{
+		{Title: "ID", Width: 1},
+		{Title: "Name", Width: 3},
+		{Title: "Street", Width: 3},
+		{Title: "City", Width: 3},
+		{Title: "Country", Width: 2},
+	}, rows)
+	report.Paragraph(fmt.Sprintf("%d plants", len(plants)))
+
+	xui.ServePDF(w, "plants.pdf", report)
+}
+
+// plantListView lists the plants matching the filter of the query, see makePlantFilter.
+func (ui UI) plantListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := ui.makePlantFilter(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plants, err := ui.service.plants(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	data := plantListData{
+		Message:   flash.Get(w, r),
+		Resources: plants,
+		Query:     query,
+	}
+	if err := ui.templates["plant-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makePlantFilter(ctx context.Context, values url.Values) (PlantFilter, error) {
+	filter := PlantFilter{
+		name:    database.ContainsPattern(values.Get("name")),
+		address: database.ContainsPattern(values.Get("address")),
+	}
+
+	var err error
+	if filter.addressID, err = parseNullID(values, "address_id"); err != nil {
+		return PlantFilter{}, err
+	}
+
+	if filter.box, err = parseBoundingBox(values); err != nil {
+		return PlantFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (ui UI) makeAdditionalCustomerData(ctx context.Context, w http.ResponseWriter, r *http.Request, customer *Customer) (customerData, error) {
+	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	if err != nil {
+		return customerData{}, err
+	}
+
+	groups, err := ui.service.customerGroups(ctx, CustomerGroupFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return customerData{}, err
+	}
+
+	data := customerData{
+		Message:   flash.Get(w, r),
+		Resource:  customer,
+		Addresses: addresses,
+		Groups:    groups,
+	}
+	if customer == nil {
+		return data, nil
+	}
+
+	data.PriceLists, err = ui.service.priceLists(ctx, PriceListFilter{customerID: sql.NullInt64{Valid: true, Int64: customer.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return customerData{}, err
+	}
+
+	return data, nil
+}
+
+func (ui UI) makeTaxCategoryFilter(ctx context.Context, values url.Values) (TaxCategoryFilter, error) {
+	return TaxCategoryFilter{}, nil
+}
+
+func (ui UI) makeCurrencyRoundingFilter(ctx context.Context, values url.Values) (CurrencyRoundingFilter, error) {
+	return CurrencyRoundingFilter{}, nil
+}
+
+// deleteCurrencyRounding removes the rounding of a currency, its gross prices are rounded to whole minor units again.
+func (ui UI) deleteCurrencyRounding(w http.ResponseWriter, r *http.Request) {
+	if err := ui.service.deleteCurrencyRounding(r.Context(), chi.URLParam(r, "currency")); err != nil {
+		slog.Error("Unable to delete currency rounding", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The rounding has been removed."})
+	http.Redirect(w, r, "/logistics/currency-roundings/", http.StatusFound)
+}
+
+func (ui UI) makeCustomerGroupFilter(ctx context.Context, values url.Values) (CustomerGroupFilter, error) {
+	return CustomerGroupFilter{}, nil
+}
+
+func (ui UI) makePriceListFilter(ctx context.Context, values url.Values) (PriceListFilter, error) {
+	var filter PriceListFilter
+	var err error
+	if filter.customerGroupID, err = parseNullID(values, "customer_group_id"); err != nil {
+		return PriceListFilter{}, err
+	}
+	if filter.customerID, err = parseNullID(values, "customer_id"); err != nil {
+		return PriceListFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (ui UI) makeAdditionalPriceListData(ctx context.Context, w http.ResponseWriter, r *http.Request, list *PriceList) (priceListData, error) {
+	customers, err := ui.service.customers(ctx, CustomerFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return priceListData{}, err
+	}
+
+	groups, err := ui.service.customerGroups(ctx, CustomerGroupFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return priceListData{}, err
+	}
+
+	data := priceListData{
+		Message:        flash.Get(w, r),
+		Resource:       list,
+		Customers:      customers,
+		CustomerGroups: groups,
+	}
+	if list == nil {
+		return data, nil
+	}
+
+	data.Prices, err = ui.service.prices(ctx, list.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return priceListData{}, err
+	}
+
+	data.Items, err = ui.service.items(ctx, ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return priceListData{}, err
+	}
+
+	return data, nil
+}
+
+func (ui UI) setPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params PriceParams
+	if params.ItemID, err = strconv.ParseInt(r.PostForm.Get("item_id"), 10, 64); err != nil {
+		http.Error(w, "malformatted item id", http.StatusBadRequest)
+		return
+	}
+	if minQuantity := r.PostForm.Get("min_quantity"); minQuantity != "" {
+		if params.MinQuantity, err = strconv.ParseFloat(minQuantity, 64); err != nil {
+			xui.RedirectBadRequest(w, r, fmt.Errorf("%w: minimum quantity has to be a number", xerrors.ErrBadRequest))
+			return
+		}
+	}
+	if params.Price, err = strconv.ParseInt(r.PostForm.Get("price"), 10, 64); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: price has to be a whole number", xerrors.ErrBadRequest))
+		return
+	}
+
+	if _, err := ui.service.setPrice(r.Context(), id, params); err != nil {
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			xui.RedirectBadRequest(w, r, err)
+			return
+		}
+		slog.Error("Unable to set price", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The price has been saved."})
+	http.Redirect(w, r, "/logistics/price-lists/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+func (ui UI) deletePrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	priceID, err := strconv.ParseInt(chi.URLParam(r, "priceID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted price id", http.StatusBadRequest)
+		return
+	}
+
+	if err := ui.service.deletePrice(r.Context(), id, priceID); err != nil {
+		slog.Error("Unable to delete price", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The price has been removed."})
+	http.Redirect(w, r, "/logistics/price-lists/"+strconv.FormatInt(id, 10), http.StatusFound)
+}
+
+// priceDeterminationView determines the price of an item for the quantity, customer and date of the query. Invalid
+// queries are shown as error message instead of a result.
+func (ui UI) priceDeterminationView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	if query.Get("date") == "" {
+		query.Set("date", time.Now().Format(time.DateOnly))
+	}
+	if query.Get("quantity") == "" {
+		query.Set("quantity", "1")
+	}
+
+	items, err := ui.service.items(ctx, ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	customers, err := ui.service.customers(ctx, CustomerFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query customers", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := priceDeterminationData{
+		Message:   flash.Get(w, r),
+		Query:     query,
+		Items:     items,
+		Customers: customers,
+	}
+
+	if query.Get("item_id") != "" {
+		price, list, err := ui.determinePrice(ctx, query)
+		switch {
+		case errors.Is(err, xerrors.ErrBadRequest) || errors.Is(err, xerrors.ErrNotFound):
+			data.Message = flash.Message{Level: flash.Error, Content: err.Error()}
+		case err != nil:
+			slog.Error("Unable to determine price", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		default:
+			data.Result = &price
+			data.PriceList = list
+		}
+	}
+
+	if err := ui.templates["price-determination"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) determinePrice(ctx context.Context, values url.Values) (EffectivePrice, *PriceList, error) {
+	query := PriceQuery{Date: values.Get("date"), Currency: values.Get("currency")}
+
+	var err error
+	if query.ItemID, err = strconv.ParseInt(values.Get("item_id"), 10, 64); err != nil {
+		return EffectivePrice{}, nil, fmt.Errorf("%w: unable to convert item id to integer", xerrors.ErrBadRequest)
+	}
+	if query.Quantity, err = strconv.ParseFloat(values.Get("quantity"), 64); err != nil {
+		return EffectivePrice{}, nil, fmt.Errorf("%w: quantity has to be a number", xerrors.ErrBadRequest)
+	}
+	if customerID := values.Get("customer_id"); customerID != "" {
+		if query.CustomerID, err = strconv.ParseInt(customerID, 10, 64); err != nil {
+			return EffectivePrice{}, nil, fmt.Errorf("%w: unable to convert customer id to integer", xerrors.ErrBadRequest)
+		}
+	}
+
+	price, err := ui.service.Price(ctx, query)
+	if err != nil || !price.PriceListID.Valid {
+		return price, nil, err
+	}
+
+	list, err := ui.service.priceList(ctx, price.PriceListID.Int64)
+	if err != nil {
+		return EffectivePrice{}, nil, err
+	}
+
+	return price, &list, nil
+}
+
+func (ui UI) makeCustomerFilter(ctx context.Context, values url.Values) (CustomerFilter, error) {
+	filter := CustomerFilter{}
+
+	if name := values.Get("name"); name != "" {
+		filter.name = sql.NullString{Valid: true, String: name}
+	}
+
+	var err error
+	if filter.groupID, err = parseNullID(values, "group_id"); err != nil {
+		return CustomerFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (ui UI) makeAdditionalGoodsMovementData(ctx context.Context, w http.ResponseWriter, r *http.Request, movement *GoodsMovement) (goodsMovementData, error) {
+	items, plants, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return goodsMovementData{}, err
+	}
+
+	bins, err := ui.service.bins(ctx, BinFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return goodsMovementData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return goodsMovementData{}, err
+	}
+
+	var batch Batch
+	if movement != nil && movement.BatchID.Valid {
+		if batch, err = ui.service.batch(ctx, movement.BatchID.Int64); err != nil {
+			return goodsMovementData{}, err
+		}
+	}
+
+	return goodsMovementData{
+		Message:  flash.Get(w, r),
+		Resource: movement,
+		Items:    items,
+		Plants:   plants,
+		Bins:     bins,
+		Units:    units,
+		Batch:    batch,
+	}, nil
+}
+
+func (ui UI) goodsMovementListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter, err := ui.makeGoodsMovementFilter(r.Context(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	movements, err := ui.service.goodsMovements(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query goods movements", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	items, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items and plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := goodsMovementListData{
+		Message:    flash.Get(w, r),
+		Resources:  movements,
+		Query:      query,
+		Items:      items,
+		Plants:     plants,
+		ItemNames:  make(map[int64]string, len(items)),
+		PlantNames: make(map[int64]string, len(plants)),
+	}
+	for _, item := range items {
+		data.ItemNames[item.ID] = item.Name
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+
+	if err := ui.templates["movement-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeGoodsMovementFilter(ctx context.Context, values url.Values) (GoodsMovementFilter, error) {
+	filter := GoodsMovementFilter{}
+
+	if movementType := values.Get("type"); movementType != "" {
+		filter.movementType = sql.NullString{Valid: true, String: movementType}
+	}
+
+	var err error
+	if filter.itemID, err = parseNullID(values, "item_id"); err != nil {
+		return GoodsMovementFilter{}, err
+	}
+
+	if filter.plantID, err = parseNullID(values, "plant_id"); err != nil {
+		return GoodsMovementFilter{}, err
+	}
+
+	return filter, nil
+}
+
+func (ui UI) stockListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := StockFilter{}
+	var err error
+	if filter.itemID, err = parseNullID(query, "item_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.plantID, err = parseNullID(query, "plant_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stock, err := ui.service.stock(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query stock", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	items, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items and plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := stockListData{
+		Message:   flash.Get(w, r),
+		Resources: stock,
+		Query:     query,
+		Items:     items,
+		Plants:    plants,
+	}
+
+	if err := ui.templates["stock-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) itemsAndPlants(ctx context.Context) ([]Item, []Plant, error) {
+	items, err := ui.service.items(ctx, ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	plants, err := ui.service.plants(ctx, PlantFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	return items, plants, nil
+}
+
+// parseNullID parses the optional ID query parameter key.
+func parseNullID(values url.Values, key string) (sql.NullInt64, error) {
+	value := values.Get(key)
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}, fmt.Errorf("%w: unable to convert %v to integer", xerrors.ErrBadRequest, key)
+	}
+
+	return sql.NullInt64{Valid: true, Int64: id}, nil
+}
+
+func (ui UI) makeAdditionalAddressData(ctx context.Context, w http.ResponseWriter, r *http.Request, address *Address) (addressData, error) {
+	countries, err := ui.service.countries(ctx, CountryFilter{})
+	if err != nil {
+		return addressData{}, err
+	}
+
+	return addressData{
+		Message:   flash.Get(w, r),
+		Resource:  address,
+		Countries: countries,
+	}, nil
+}
+
+// createAddress creates an address. If the address already exists the create form is shown again, offering to reuse
+// the existing address or to create the address anyway.
+func (ui UI) createAddress(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params AddressParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		slog.Error("Unable to decode form", "error", err)
+		http.Error(w, "unable to decode form", http.StatusBadRequest)
+		return
+	}
+
+	address, err := ui.service.createAddress(r.Context(), params)
+	var duplicate DuplicateAddressError
+	switch {
+	case errors.As(err, &duplicate):
+		data, err := ui.makeAdditionalAddressData(r.Context(), w, r, &Address{
+			ZIP:       params.ZIP,
+			City:      params.City,
+			Street:    params.Street,
+			Country:   params.Country,
+			Latitude:  params.Latitude,
+			Longitude: params.Longitude,
+		})
+		if err != nil {
+			slog.Error("Unable to create data", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		data.Message = flash.Message{Level: flash.Info, Content: "The address already exists."}
+		data.Duplicate = &duplicate.Existing
+
+		if err := ui.templates["address-create"].Execute(w, data); err != nil {
+			slog.Error("Unable to execute template", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+		return
+	case errors.Is(err, xerrors.ErrBadRequest):
+		xui.RedirectBadRequest(w, r, err)
+		return
+	case err != nil:
+		slog.Error("Unable to create address", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.EntryCreated(w)
+	http.Redirect(w, r, address.Redirect(), http.StatusFound)
+}
+
+func (ui UI) makeAdditionalSupplierData(ctx context.Context, w http.ResponseWriter, r *http.Request, supplier *Supplier) (supplierData, error) {
+	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	if err != nil {
+		return supplierData{}, err
+	}
+
+	return supplierData{
+		Message:   flash.Get(w, r),
+		Resource:  supplier,
+		Addresses: addresses,
+	}, nil
+}
+
+func (ui UI) makeSupplierFilter(ctx context.Context, values url.Values) (SupplierFilter, error) {
+	filter := SupplierFilter{}
+
+	if name := values.Get("name"); name != "" {
+		filter.name = sql.NullString{Valid: true, String: name}
+	}
+
+	return filter, nil
+}
+
+func (ui UI) makeAdditionalPurchaseOrderData(ctx context.Context, w http.ResponseWriter, r *http.Request, order *PurchaseOrder) (purchaseOrderData, error) {
+	suppliers, err := ui.service.suppliers(ctx, SupplierFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return purchaseOrderData{}, err
+	}
+
+	items, plants, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return purchaseOrderData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return purchaseOrderData{}, err
+	}
+
+	return purchaseOrderData{
+		Message:   flash.Get(w, r),
+		Resource:  order,
+		Suppliers: suppliers,
+		Plants:    plants,
+		Items:     items,
+		Units:     units,
+	}, nil
+}
+
+func (ui UI) purchaseOrderListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := PurchaseOrderFilter{}
+	var err error
+	if filter.supplierID, err = parseNullID(query, "supplier_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+
+	orders, err := ui.service.purchaseOrders(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query purchase orders", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	suppliers, err := ui.service.suppliers(r.Context(), SupplierFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query suppliers", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	_, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := purchaseOrderListData{
+		Message:       flash.Get(w, r),
+		Resources:     orders,
+		Query:         query,
+		Suppliers:     suppliers,
+		SupplierNames: make(map[int64]string, len(suppliers)),
+		PlantNames:    make(map[int64]string, len(plants)),
+	}
+	for _, supplier := range suppliers {
+		data.SupplierNames[supplier.ID] = supplier.Name
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+
+	if err := ui.templates["purchase-order-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) cancelPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	order, err := ui.service.cancelPurchaseOrder(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to cancel purchase order", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The purchase order has been cancelled."})
+	http.Redirect(w, r, order.Redirect(), http.StatusFound)
+}
+
+func (ui UI) goodsReceiptView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := ui.makeGoodsReceiptData(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to make data", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+	data.Message = flash.Get(w, r)
+
+	if err := ui.templates["goods-receipt"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeGoodsReceiptData(ctx context.Context, id int64) (goodsReceiptData, error) {
+	order, err := ui.service.purchaseOrder(ctx, id)
+	if err != nil {
+		return goodsReceiptData{}, err
+	}
+
+	lookup, err := ui.itemLookups(ctx)
+	if err != nil {
+		return goodsReceiptData{}, err
+	}
+
+	bins, err := ui.service.bins(ctx, BinFilter{plantID: sql.NullInt64{Valid: true, Int64: order.PlantID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return goodsReceiptData{}, err
+	}
+
+	return goodsReceiptData{
+		Resource:      order,
+		Date:          time.Now().Format(time.DateOnly),
+		ItemNames:     lookup.Names,
+		BaseUnitCodes: lookup.BaseUnitCodes,
+		Tracking:      lookup.Tracking,
+		Bins:          bins,
+	}, nil
+}
+
+// itemLookup maps item IDs to the names, the codes of the base units and the tracking modes of the items.
+type itemLookup struct {
+	Names         map[int64]string
+	BaseUnitCodes map[int64]string
+	Tracking      map[int64]string
+}
+
+func (ui UI) itemLookups(ctx context.Context) (itemLookup, error) {
+	items, _, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return itemLookup{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemLookup{}, err
+	}
+	unitCodes := make(map[int64]string, len(units))
+	for _, unit := range units {
+		unitCodes[unit.ID] = unit.Code
+	}
+
+	lookup := itemLookup{
+		Names:         make(map[int64]string, len(items)),
+		BaseUnitCodes: make(map[int64]string, len(items)),
+		Tracking:      make(map[int64]string, len(items)),
+	}
+	for _, item := range items {
+		lookup.Names[item.ID] = item.Name
+		lookup.BaseUnitCodes[item.ID] = unitCodes[item.BaseUnitID]
+		lookup.Tracking[item.ID] = item.Tracking
+	}
+
+	return lookup, nil
+}
+
+// plantNames returns the names of all plants by plant ID.
+func (ui UI) plantNames(ctx context.Context) (map[int64]string, error) {
+	_, plants, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(plants))
+	for _, plant := range plants {
+		names[plant.ID] = plant.Name
+	}
+
+	return names, nil
+}
+
+func (ui UI) receivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	params, err := parseGoodsReceiptForm(r.PostForm)
+	if err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: %v", xerrors.ErrBadRequest, err))
+		return
+	}
+
+	order, err := ui.service.receivePurchaseOrder(r.Context(), id, params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to receive purchase order", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The goods have been received."})
+	http.Redirect(w, r, order.Redirect(), http.StatusFound)
+}
+
+func parsePurchaseOrderForm(values url.Values) (PurchaseOrderParams, error) {
+	header := PurchaseOrderHeaderParams{
+		Date:         values.Get("date"),
+		DeliveryDate: values.Get("delivery_date"),
+		Currency:     values.Get("currency"),
+	}
+
+	var err error
+	if header.SupplierID, err = strconv.ParseInt(values.Get("supplier_id"), 10, 64); err != nil {
+		return PurchaseOrderParams{}, fmt.Errorf("unable to parse purchase order supplier_id to integer: %w", err)
+	}
+	if header.PlantID, err = strconv.ParseInt(values.Get("plant_id"), 10, 64); err != nil {
+		return PurchaseOrderParams{}, fmt.Errorf("unable to parse purchase order plant_id to integer: %w", err)
+	}
+
+	lines, err := parseOrderLines(values)
+	if err != nil {
+		return PurchaseOrderParams{}, err
+	}
+
+	return PurchaseOrderParams{PurchaseOrderHeaderParams: header, Lines: lines}, nil
+}
+
+// parseOrderLines parses the lines[] fields of the purchase and sales order forms.
+func parseOrderLines(values url.Values) ([]OrderLineParams, error) {
+	itemIDs := values["lines[].item_id"]
+	if len(values["lines[].quantity"]) != len(itemIDs) || len(values["lines[].unit_id"]) != len(itemIDs) ||
+		len(values["lines[].price"]) != len(itemIDs) {
+		return nil, errors.New("incomplete order lines")
+	}
+
+	var lines []OrderLineParams
+	for i := 0; i < len(itemIDs); i++ {
+		var line OrderLineParams
+		var err error
+		if line.ItemID, err = strconv.ParseInt(itemIDs[i], 10, 64); err != nil {
+			return nil, fmt.Errorf("unable to parse line item_id to integer: %w", err)
+		}
+		if line.Quantity, err = strconv.ParseFloat(values["lines[].quantity"][i], 64); err != nil {
+			return nil, fmt.Errorf("unable to parse line quantity to number: %w", err)
+		}
+		if line.UnitID, err = strconv.ParseInt(values["lines[].unit_id"][i], 10, 64); err != nil {
+			return nil, fmt.Errorf("unable to parse line unit_id to integer: %w", err)
+		}
+
+		if price := values["lines[].price"][i]; price != "" {
+			n, err := strconv.ParseInt(price, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse line price to integer: %w", err)
+			}
+			line.Price = sql.NullInt64{Valid: true, Int64: n}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func parseGoodsReceiptForm(values url.Values) (GoodsReceiptParams, error) {
+	params := GoodsReceiptParams{
+		Date:      values.Get("date"),
+		Reference: values.Get("reference"),
+	}
+
+	lineIDs := values["lines[].line_id"]
+	if len(values["lines[].quantity"]) != len(lineIDs) || len(values["lines[].bin_id"]) != len(lineIDs) ||
+		len(values["lines[].batch_number"]) != len(lineIDs) || len(values["lines[].expiry_date"]) != len(lineIDs) ||
+		len(values["lines[].serial_numbers"]) != len(lineIDs) {
+		return GoodsReceiptParams{}, errors.New("incomplete receipt lines")
+	}
+
+	for i := 0; i < len(lineIDs); i++ {
+		var line GoodsReceiptLineParams
+		var err error
+		if line.LineID, err = strconv.ParseInt(lineIDs[i], 10, 64); err != nil {
+			return GoodsReceiptParams{}, fmt.Errorf("unable to parse line id to integer: %w", err)
+		}
+		if quantity := values["lines[].quantity"][i]; quantity != "" {
+			if line.Quantity, err = strconv.ParseFloat(quantity, 64); err != nil {
+				return GoodsReceiptParams{}, fmt.Errorf("unable to parse received quantity to number: %w", err)
+			}
+		}
+		if line.BinID, err = strconv.ParseInt(values["lines[].bin_id"][i], 10, 64); err != nil {
+			return GoodsReceiptParams{}, fmt.Errorf("unable to parse bin id to integer: %w", err)
+		}
+		line.BatchNumber = values["lines[].batch_number"][i]
+		line.ExpiryDate = values["lines[].expiry_date"][i]
+		line.SerialNumbers = values["lines[].serial_numbers"][i]
+
+		params.Lines = append(params.Lines, line)
+	}
+
+	return params, nil
+}
+
+func (ui UI) makeAdditionalSalesOrderData(ctx context.Context, w http.ResponseWriter, r *http.Request, order *SalesOrder) (salesOrderData, error) {
+	customers, err := ui.service.customers(ctx, CustomerFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return salesOrderData{}, err
+	}
+
+	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return salesOrderData{}, err
+	}
+
+	items, plants, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return salesOrderData{}, err
+	}
+
+	units, err := ui.service.units(ctx, UnitFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return salesOrderData{}, err
+	}
+
+	var deliveries []DeliveryHeader
+	if order != nil {
+		deliveries, err = ui.service.deliveries(ctx, DeliveryFilter{salesOrderID: sql.NullInt64{Valid: true, Int64: order.ID}})
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return salesOrderData{}, err
+		}
+	}
+
+	return salesOrderData{
+		Message:    flash.Get(w, r),
+		Resource:   order,
+		Customers:  customers,
+		Plants:     plants,
+		Addresses:  addresses,
+		Items:      items,
+		Units:      units,
+		Deliveries: deliveries,
+	}, nil
+}
+
+func (ui UI) salesOrderListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := SalesOrderFilter{}
+	var err error
+	if filter.customerID, err = parseNullID(query, "customer_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+
+	orders, err := ui.service.salesOrders(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query sales orders", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	customers, err := ui.service.customers(r.Context(), CustomerFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query customers", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := salesOrderListData{
+		Message:       flash.Get(w, r),
+		Resources:     orders,
+		Query:         query,
+		Customers:     customers,
+		CustomerNames: make(map[int64]string, len(customers)),
+	}
+	for _, customer := range customers {
+		data.CustomerNames[customer.ID] = customer.Name
+	}
+
+	if err := ui.templates["sales-order-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) cancelSalesOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	order, err := ui.service.cancelSalesOrder(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to cancel sales order", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The sales order has been cancelled."})
+	http.Redirect(w, r, order.Redirect(), http.StatusFound)
+}
+
+func (ui UI) deliveryCreateView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := ui.makeDeliveryCreateData(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to make data", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+	data.Message = flash.Get(w, r)
+
+	if err := ui.templates["delivery-create"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeDeliveryCreateData(ctx context.Context, id int64) (deliveryCreateData, error) {
+	order, err := ui.service.salesOrder(ctx, id)
+	if err != nil {
+		return deliveryCreateData{}, err
+	}
+
+	lookup, err := ui.itemLookups(ctx)
+	if err != nil {
+		return deliveryCreateData{}, err
+	}
+
+	plantID := sql.NullInt64{Valid: true, Int64: order.PlantID}
+	bins, err := ui.service.bins(ctx, BinFilter{plantID: plantID})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return deliveryCreateData{}, err
+	}
+
+	data := deliveryCreateData{
+		Resource:      order,
+		Date:          order.RequestedDeliveryDate,
+		ItemNames:     lookup.Names,
+		BaseUnitCodes: lookup.BaseUnitCodes,
+		Tracking:      lookup.Tracking,
+		Bins:          bins,
+		Batches:       make(map[int64][]BatchStock),
+		SerialNumbers: make(map[int64][]SerialNumber),
+	}
+	for _, line := range order.Lines {
+		switch lookup.Tracking[line.ItemID] {
+		case TrackingBatch:
+			data.Batches[line.ItemID], err = ui.service.batchStock(ctx, line.ItemID, plantID)
+		case TrackingSerial:
+			data.SerialNumbers[line.ItemID], err = ui.service.serialNumbers(ctx, SerialNumberFilter{
+				itemID:  sql.NullInt64{Valid: true, Int64: line.ItemID},
+				plantID: plantID,
+			})
+		}
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return deliveryCreateData{}, err
+		}
+	}
+
+	return data, nil
+}
+
+func (ui UI) createDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	params, err := parseDeliveryForm(r.PostForm)
+	if err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: %v", xerrors.ErrBadRequest, err))
+		return
+	}
+
+	delivery, err := ui.service.createDelivery(r.Context(), id, params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to create delivery", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The delivery has been created."})
+	http.Redirect(w, r, delivery.Redirect(), http.StatusFound)
+}
+
+func (ui UI) makeAdditionalDeliveryData(ctx context.Context, w http.ResponseWriter, r *http.Request, delivery *Delivery) (deliveryData, error) {
+	order, err := ui.service.salesOrder(ctx, delivery.SalesOrderID)
+	if err != nil {
+		return deliveryData{}, err
+	}
+
+	customer, err := ui.service.customer(ctx, order.CustomerID)
+	if err != nil {
+		return deliveryData{}, err
+	}
+
+	address, err := ui.service.address(ctx, order.ShippingAddressID)
+	if err != nil {
+		return deliveryData{}, err
+	}
+
+	lookup, err := ui.itemLookups(ctx)
+	if err != nil {
+		return deliveryData{}, err
+	}
+
+	bins, err := ui.service.bins(ctx, BinFilter{plantID: sql.NullInt64{Valid: true, Int64: delivery.PlantID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return deliveryData{}, err
+	}
+	binNames := make(map[int64]string, len(bins))
+	for _, bin := range bins {
+		binNames[bin.ID] = bin.StorageLocationName + " / " + bin.Name
+	}
+
+	shipments, err := ui.service.shipments(ctx, ShipmentFilter{deliveryID: sql.NullInt64{Valid: true, Int64: delivery.ID}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return deliveryData{}, err
+	}
+
+	return deliveryData{
+		Message:         flash.Get(w, r),
+		Resource:        delivery,
+		SalesOrder:      order.SalesOrderHeader,
+		Customer:        customer,
+		ShippingAddress: address,
+		ItemNames:       lookup.Names,
+		BaseUnitCodes:   lookup.BaseUnitCodes,
+		BinNames:        binNames,
+		Shipments:       shipments,
+		Carriers:        ui.carriers(),
+	}, nil
+}
+
+func (ui UI) deliveryListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := DeliveryFilter{}
+	var err error
+	if filter.plantID, err = parseNullID(query, "plant_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+
+	deliveries, err := ui.service.deliveries(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query deliveries", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	_, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := deliveryListData{
+		Message:    flash.Get(w, r),
+		Resources:  deliveries,
+		Query:      query,
+		Plants:     plants,
+		PlantNames: make(map[int64]string, len(plants)),
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+
+	if err := ui.templates["delivery-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) shipDelivery(w http.ResponseWriter, r *http.Request) {
+	ui.changeDelivery(w, r, ui.service.shipDelivery, "Success! The delivery has been shipped.")
+}
+
+func (ui UI) cancelDelivery(w http.ResponseWriter, r *http.Request) {
+	ui.changeDelivery(w, r, ui.service.cancelDelivery, "Success! The delivery has been cancelled.")
+}
+
+// changeDelivery applies change to the delivery of the request and redirects back to it.
+func (ui UI) changeDelivery(w http.ResponseWriter, r *http.Request, change func(context.Context, int64) (Delivery, error), message string) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := change(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to change delivery", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: message})
+	http.Redirect(w, r, delivery.Redirect(), http.StatusFound)
+}
+
+// pickingListPDF serves the picking list of the open deliveries of a plant, or of a single delivery if the delivery_id
+// query parameter is set.
+func (ui UI) pickingListPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	deliveryID, err := parseNullID(r.URL.Query(), "delivery_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plant, err := ui.service.plant(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to query plant", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	lines, err := ui.service.pickingList(r.Context(), id, deliveryID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query picking list", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	var rows [][]string
+	for _, line := range lines {
+		bin := ""
+		if line.BinName.Valid {
+			bin = line.StorageLocationName.String + " / " + line.BinName.String
+		}
+		tracking := line.BatchNumber.String
+		if len(line.SerialNumbers) > 0 {
+			tracking = strings.Join(line.SerialNumbers, ", ")
+		}
+		quantity := strconv.FormatFloat(line.Quantity, 'f', -1, 64) + " " + line.UnitCode
+		rows = append(rows, []string{bin, line.ItemName, line.SKU, tracking, quantity, "DL-" + strconv.FormatInt(line.DeliveryID, 10), line.Date, ""})
+	}
+
+	fields := [][2]string{{"Plant", plant.Name}, {"Printed", time.Now().Format(time.DateOnly)}}
+	if deliveryID.Valid {
+		fields = append(fields, [2]string{"Delivery", "DL-" + strconv.FormatInt(deliveryID.Int64, 10)})
+	}
+
+	report := pdf.NewReport(ui.letterhead, "Picking list")
+	report.Fields(fields)
+	report.Table([]pdf.Column{
+		{Title: "Bin", Width: 2.5},
+		{Title: "Item", Width: 2.5},
+		{Title: "SKU", Width: 2},
+		{Title: "Batch / serial", Width: 2},
+		{Title: "Quantity", Width: 1.5, Align: pdf.Right},
+		{Title: "Delivery", Width: 1.5},
+		{Title: "Date", Width: 1.5},
+		{Title: "Picked", Width: 1},
+	}, rows)
+	report.Paragraph(fmt.Sprintf("%d lines", len(lines)))
+
+	xui.ServePDF(w, "picking-list-"+plant.GetID()+".pdf", report)
+}
+
+func (ui UI) carriers() []carrierData {
+	var carriers []carrierData
+	for _, carrier := range ui.service.carrierList() {
+		carriers = append(carriers, carrierData{Code: carrier.Code(), Name: carrier.Name(), Services: carrier.Services()})
+	}
+	return carriers
+}
+
+// createShipment creates a shipment for a delivery. The carrier and its service are selected together as
+// "carrier/service".
+func (ui UI) createShipment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params ShipmentParams
+	params.Carrier, params.Service, _ = strings.Cut(r.PostForm.Get("carrier_service"), "/")
+
+	shipment, err := ui.service.createShipment(r.Context(), id, params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to create shipment", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The shipment has been created."})
+	http.Redirect(w, r, shipment.Redirect(), http.StatusFound)
+}
+
+func (ui UI) makeAdditionalShipmentData(ctx context.Context, w http.ResponseWriter, r *http.Request, shipment *Shipment) (shipmentData, error) {
+	label, err := ui.service.shippingLabel(ctx, shipment.ID)
+	if err != nil {
+		return shipmentData{}, err
+	}
+
+	return shipmentData{
+		Message:  flash.Get(w, r),
+		Resource: shipment,
+		Label:    label,
+	}, nil
+}
+
+func (ui UI) shipmentListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := ShipmentFilter{}
+	var err error
+	if filter.deliveryID, err = parseNullID(query, "delivery_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if carrier := query.Get("carrier"); carrier != "" {
+		filter.carrier = sql.NullString{Valid: true, String: carrier}
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+
+	shipments, err := ui.service.shipments(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query shipments", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	data := shipmentListData{
+		Message:   flash.Get(w, r),
+		Resources: shipments,
+		Query:     query,
+		Carriers:  ui.carriers(),
+	}
+
+	if err := ui.templates["shipment-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) createShipmentPackage(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params ShipmentPackageParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: package dimensions and weight have to be numbers", xerrors.ErrBadRequest))
+		return
+	}
+
+	ui.changeShipment(w, r, func(ctx context.Context, id int64) (Shipment, error) {
+		return ui.service.createShipmentPackage(ctx, id, params)
+	}, "Success! The package has been added.")
+}
+
+func (ui UI) deleteShipmentPackage(w http.ResponseWriter, r *http.Request) {
+	packageID, err := strconv.ParseInt(chi.URLParam(r, "packageID"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted package id", http.StatusBadRequest)
+		return
+	}
+
+	ui.changeShipment(w, r, func(ctx context.Context, id int64) (Shipment, error) {
+		return ui.service.deleteShipmentPackage(ctx, id, packageID)
+	}, "Success! The package has been removed.")
+}
+
+func (ui UI) createShippingLabel(w http.ResponseWriter, r *http.Request) {
+	ui.changeShipment(w, r, ui.service.createShippingLabel, "Success! The label has been created.")
+}
+
+func (ui UI) cancelShipment(w http.ResponseWriter, r *http.Request) {
+	ui.changeShipment(w, r, ui.service.cancelShipment, "Success! The shipment has been cancelled.")
+}
+
+// changeShipment applies change to the shipment of the request and redirects back to it.
+func (ui UI) changeShipment(w http.ResponseWriter, r *http.Request, change func(context.Context, int64) (Shipment, error), message string) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := change(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to change shipment", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: message})
+	http.Redirect(w, r, shipment.Redirect(), http.StatusFound)
+}
+
+// shippingLabel serves the labels of a labeled shipment, one per package. They are served as ZPL for label printers if
+// the path ends in .zpl and as PDF with 4x6 inch pages otherwise.
+func (ui UI) shippingLabel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	label, err := ui.service.shippingLabel(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to query shipping label", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+	if !label.Labeled() {
+		http.Error(w, "the shipment has no label", http.StatusBadRequest)
+		return
+	}
+
+	if format, _ := r.Context().Value(middleware.URLFormatCtxKey).(string); format == "zpl" {
+		zpl := shippingLabelZPL(label)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "label-" + label.Reference() + ".zpl"}))
+		if _, err := io.WriteString(w, zpl); err != nil {
+			slog.Error("Unable to write ZPL", "error", err)
+		}
+		return
+	}
+
+	document, err := shippingLabelPDF(label)
+	if err != nil {
+		slog.Error("Unable to render shipping label", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	xui.ServePDF(w, "label-"+label.Reference()+".pdf", document)
+}
+
+// Labels are 4x6 inches, printed with 203 dpi by label printers.
+const (
+	labelDots    = 203
+	labelWidth   = 4
+	labelHeight  = 6
+	labelPadding = 30
+)
+
+// shippingLabelZPL returns one ZPL label per package, the tracking number of the package is printed as Code 128
+// barcode. Field data is UTF-8 with ^, ~ and _ hex escaped.
+func shippingLabelZPL(label ShippingLabel) string {
+	var b strings.Builder
+	width := labelWidth * labelDots
+	line := func(y int) {
+		fmt.Fprintf(&b, "^FO0,%d^GB%d,3,3^FS\n", y, width)
+	}
+	text := func(y int, size int, s string) {
+		fmt.Fprintf(&b, "^FO%d,%d^A0N,%d,%d^FB%d,1,0,L^FH^FD%s^FS\n", labelPadding, y, size, size, width-2*labelPadding, zplText(s))
+	}
+
+	for i, pkg := range label.Packages {
+		b.WriteString("^XA\n^CI28\n")
+		fmt.Fprintf(&b, "^PW%d\n^LL%d\n", width, labelHeight*labelDots)
+
+		text(30, 24, "FROM")
+		text(60, 28, label.From.Name)
+		text(95, 28, label.From.Street)
+		text(130, 28, label.From.ZIP+" "+label.From.City)
+		text(165, 28, label.From.CountryName)
+		line(210)
+
+		text(235, 24, "TO")
+		text(270, 48, label.To.Name)
+		text(340, 40, label.To.Street)
+		text(390, 40, label.To.ZIP+" "+label.To.City)
+		text(440, 40, strings.ToUpper(label.To.CountryName))
+		line(510)
+
+		text(535, 40, label.CarrierName+" "+label.ServiceName)
+		text(590, 32, fmt.Sprintf("Package %d of %d, %v kg", i+1, len(label.Packages), pkg.Weight))
+		text(635, 28, fmt.Sprintf("%v x %v x %v cm", pkg.Length, pkg.Width, pkg.Height))
+		line(690)
+
+		// The printer encodes the barcode itself, its width is estimated to center it and to fit long numbers.
+		x, moduleWidth := labelPadding, 3
+		if modules, err := barcode.Code128(pkg.TrackingNumber); err == nil {
+			moduleWidth = max(1, min(3, (width-2*labelPadding)/(len(modules)+20)))
+			x = max(0, (width-moduleWidth*len(modules))/2)
+		}
+		fmt.Fprintf(&b, "^FO%d,740^BY%d^BCN,280,Y,N,N,A^FH^FD%s^FS\n", x, moduleWidth, zplText(pkg.TrackingNumber))
+		line(1110)
+
+		text(1140, 28, label.Reference()+" / "+label.DeliveryReference+" / "+label.TrackingNumber)
+		b.WriteString("^XZ\n")
+	}
+
+	return b.String()
+}
+
+// zplText escapes s for a ^FH field, line breaks are replaced by spaces.
+func zplText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '^', '~', '_':
+			fmt.Fprintf(&b, "_%02X", r)
+		case '\n', '\r', '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// shippingLabelPDF lays out the labels like shippingLabelZPL on 4x6 inch pages.
+func shippingLabelPDF(label ShippingLabel) (*pdf.Document, error) {
+	width := float64(labelWidth * 72)
+	document := pdf.New(width, float64(labelHeight*72))
+	const margin = 10
+	text := func(y float64, font pdf.Font, size float64, s string) {
+		document.Text(margin, y, font, size, pdf.Left, pdf.Truncate(font, size, width-2*margin, s))
+	}
+
+	for i, pkg := range label.Packages {
+		document.AddPage()
+
+		text(18, pdf.Helvetica, 7, "FROM")
+		text(30, pdf.HelveticaBold, 9, label.From.Name)
+		text(41, pdf.Helvetica, 9, label.From.Street)
+		text(52, pdf.Helvetica, 9, label.From.ZIP+" "+label.From.City)
+		text(63, pdf.Helvetica, 9, label.From.CountryName)
+		document.Line(0, 74, width, 74, 1)
+
+		text(88, pdf.Helvetica, 7, "TO")
+		text(106, pdf.HelveticaBold, 16, label.To.Name)
+		text(126, pdf.Helvetica, 13, label.To.Street)
+		text(144, pdf.Helvetica, 13, label.To.ZIP+" "+label.To.City)
+		text(162, pdf.HelveticaBold, 13, strings.ToUpper(label.To.CountryName))
+		document.Line(0, 178, width, 178, 1)
+
+		text(196, pdf.HelveticaBold, 13, label.CarrierName+" "+label.ServiceName)
+		text(212, pdf.Helvetica, 10, fmt.Sprintf("Package %d of %d, %v kg", i+1, len(label.Packages), pkg.Weight))
+		text(226, pdf.Helvetica, 10, fmt.Sprintf("%v x %v x %v cm", pkg.Length, pkg.Width, pkg.Height))
+		document.Line(0, 240, width, 240, 1)
+
+		modules, err := barcode.Code128(pkg.TrackingNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode tracking number %v: %w", pkg.TrackingNumber, err)
+		}
+		moduleWidth := min(1.5, (width-2*margin)/float64(len(modules)+20))
+		barsWidth := moduleWidth * float64(len(modules))
+		document.Bars((width-barsWidth)/2, 258, moduleWidth, 96, modules)
+		document.Text(width/2, 370, pdf.Helvetica, 10, pdf.Center, pkg.TrackingNumber)
+		document.Line(0, 394, width, 394, 1)
+
+		text(414, pdf.Helvetica, 8, label.Reference()+" / "+label.DeliveryReference+" / "+label.TrackingNumber)
+	}
+
+	return document, nil
+}
+
+func parseSalesOrderForm(values url.Values) (SalesOrderParams, error) {
+	header := SalesOrderHeaderParams{
+		Date:                  values.Get("date"),
+		RequestedDeliveryDate: values.Get("requested_delivery_date"),
+		Currency:              values.Get("currency"),
+	}
+
+	var err error
+	if header.CustomerID, err = strconv.ParseInt(values.Get("customer_id"), 10, 64); err != nil {
+		return SalesOrderParams{}, fmt.Errorf("unable to parse sales order customer_id to integer: %w", err)
+	}
+	if header.PlantID, err = strconv.ParseInt(values.Get("plant_id"), 10, 64); err != nil {
+		return SalesOrderParams{}, fmt.Errorf("unable to parse sales order plant_id to integer: %w", err)
+	}
+	if header.ShippingAddressID, err = strconv.ParseInt(values.Get("shipping_address_id"), 10, 64); err != nil {
+		return SalesOrderParams{}, fmt.Errorf("unable to parse sales order shipping_address_id to integer: %w", err)
+	}
+
+	lines, err := parseOrderLines(values)
+	if err != nil {
+		return SalesOrderParams{}, err
+	}
+
+	return SalesOrderParams{SalesOrderHeaderParams: header, Lines: lines}, nil
+}
+
+func parseDeliveryForm(values url.Values) (DeliveryParams, error) {
+	params := DeliveryParams{
+		Date: values.Get("date"),
+	}
+
+	lineIDs := values["lines[].line_id"]
+	if len(values["lines[].quantity"]) != len(lineIDs) || len(values["lines[].bin_id"]) != len(lineIDs) ||
+		len(values["lines[].batch_id"]) != len(lineIDs) || len(values["lines[].serial_numbers"]) != len(lineIDs) {
+		return DeliveryParams{}, errors.New("incomplete delivery lines")
+	}
+
+	for i := 0; i < len(lineIDs); i++ {
+		var line DeliveryLineParams
+		var err error
+		if line.SalesOrderLineID, err = strconv.ParseInt(lineIDs[i], 10, 64); err != nil {
+			return DeliveryParams{}, fmt.Errorf("unable to parse line id to integer: %w", err)
+		}
+		if quantity := values["lines[].quantity"][i]; quantity != "" {
+			if line.Quantity, err = strconv.ParseFloat(quantity, 64); err != nil {
+				return DeliveryParams{}, fmt.Errorf("unable to parse delivery quantity to number: %w", err)
+			}
+		}
+		if line.BinID, err = strconv.ParseInt(values["lines[].bin_id"][i], 10, 64); err != nil {
+			return DeliveryParams{}, fmt.Errorf("unable to parse bin id to integer: %w", err)
+		}
+		if line.BatchID, err = strconv.ParseInt(values["lines[].batch_id"][i], 10, 64); err != nil {
+			return DeliveryParams{}, fmt.Errorf("unable to parse batch id to integer: %w", err)
+		}
+		line.SerialNumbers = values["lines[].serial_numbers"][i]
+
+		params.Lines = append(params.Lines, line)
+	}
+
+	return params, nil
+}
+
+func (ui UI) batchListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := BatchFilter{}
+	var err error
+	if filter.itemID, err = parseNullID(query, "item_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if number := query.Get("number"); number != "" {
+		filter.number = sql.NullString{Valid: true, String: number}
+	}
+
+	batches, err := ui.service.batches(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query batches", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	items, err := ui.service.items(r.Context(), ItemFilter{tracking: sql.NullString{Valid: true, String: TrackingBatch}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := batchListData{
+		Message:   flash.Get(w, r),
+		Resources: batches,
+		Query:     query,
+		Items:     items,
+		ItemNames: make(map[int64]string, len(items)),
+	}
+	for _, item := range items {
+		data.ItemNames[item.ID] = item.Name
+	}
+
+	if err := ui.templates["batch-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeAdditionalBatchData(ctx context.Context, w http.ResponseWriter, r *http.Request, batch *Batch) (batchData, error) {
+	item, err := ui.service.item(ctx, batch.ItemID)
+	if err != nil {
+		return batchData{}, err
+	}
+
+	stock, err := ui.service.batchStock(ctx, batch.ItemID, sql.NullInt64{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return batchData{}, err
+	}
+	var batchStock []BatchStock
+	for _, s := range stock {
+		if s.ID == batch.ID {
+			batchStock = append(batchStock, s)
+		}
+	}
+
+	trace, err := ui.service.batchTrace(ctx, batch.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return batchData{}, err
+	}
+
+	plantNames, err := ui.plantNames(ctx)
+	if err != nil {
+		return batchData{}, err
+	}
+
+	return batchData{
+		Message:    flash.Get(w, r),
+		Resource:   batch,
+		Item:       item,
+		Stock:      batchStock,
+		Trace:      trace,
+		PlantNames: plantNames,
+	}, nil
+}
+
+func (ui UI) serialNumberListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := SerialNumberFilter{}
+	var err error
+	if filter.itemID, err = parseNullID(query, "item_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.plantID, err = parseNullID(query, "plant_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if number := query.Get("number"); number != "" {
+		filter.number = sql.NullString{Valid: true, String: number}
+	}
+
+	serialNumbers, err := ui.service.serialNumbers(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query serial numbers", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	items, err := ui.service.items(r.Context(), ItemFilter{tracking: sql.NullString{Valid: true, String: TrackingSerial}})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	_, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := serialNumberListData{
+		Message:    flash.Get(w, r),
+		Resources:  serialNumbers,
+		Query:      query,
+		Items:      items,
+		Plants:     plants,
+		ItemNames:  make(map[int64]string, len(items)),
+		PlantNames: make(map[int64]string, len(plants)),
+	}
+	for _, item := range items {
+		data.ItemNames[item.ID] = item.Name
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+
+	if err := ui.templates["serial-number-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeAdditionalSerialNumberData(ctx context.Context, w http.ResponseWriter, r *http.Request, serialNumber *SerialNumber) (serialNumberData, error) {
+	item, err := ui.service.item(ctx, serialNumber.ItemID)
+	if err != nil {
+		return serialNumberData{}, err
+	}
+
+	trace, err := ui.service.serialNumberTrace(ctx, serialNumber.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return serialNumberData{}, err
+	}
+
+	plantNames, err := ui.plantNames(ctx)
+	if err != nil {
+		return serialNumberData{}, err
+	}
+
+	return serialNumberData{
+		Message:    flash.Get(w, r),
+		Resource:   serialNumber,
+		Item:       item,
+		Trace:      trace,
+		PlantNames: plantNames,
+	}, nil
+}
+
+func (ui UI) purchaseProposalListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := PurchaseProposalFilter{}
+	projectionFilter := PlanningParametersFilter{}
+	var err error
+	if filter.itemID, err = parseNullID(query, "item_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.plantID, err = parseNullID(query, "plant_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+	projectionFilter.itemID, projectionFilter.plantID = filter.itemID, filter.plantID
+
+	proposals, err := ui.service.purchaseProposals(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query purchase proposals", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	projections, err := ui.service.projections(r.Context(), projectionFilter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query projections", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	items, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items and plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	lookup, err := ui.itemLookups(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	suppliers, err := ui.service.suppliers(r.Context(), SupplierFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query suppliers", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := purchaseProposalListData{
+		Message:       flash.Get(w, r),
+		Resources:     proposals,
+		Query:         query,
+		Projections:   projections,
+		Items:         items,
+		Plants:        plants,
+		ItemNames:     lookup.Names,
+		BaseUnitCodes: lookup.BaseUnitCodes,
+		PlantNames:    make(map[int64]string, len(plants)),
+		SupplierNames: make(map[int64]string, len(suppliers)),
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+	for _, supplier := range suppliers {
+		data.SupplierNames[supplier.ID] = supplier.Name
+	}
+
+	if err := ui.templates["purchase-proposal-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeAdditionalPurchaseProposalData(ctx context.Context, w http.ResponseWriter, r *http.Request, proposal *PurchaseProposal) (purchaseProposalData, error) {
+	item, err := ui.service.item(ctx, proposal.ItemID)
+	if err != nil {
+		return purchaseProposalData{}, err
+	}
+
+	plant, err := ui.service.plant(ctx, proposal.PlantID)
+	if err != nil {
+		return purchaseProposalData{}, err
+	}
+
+	unit, err := ui.service.unit(ctx, item.BaseUnitID)
+	if err != nil {
+		return purchaseProposalData{}, err
+	}
+
+	suppliers, err := ui.service.suppliers(ctx, SupplierFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return purchaseProposalData{}, err
+	}
+
+	return purchaseProposalData{
+		Message:      flash.Get(w, r),
+		Resource:     proposal,
+		Item:         item,
+		Plant:        plant,
+		BaseUnitCode: unit.Code,
+		Suppliers:    suppliers,
+	}, nil
+}
+
+func (ui UI) planningRun(w http.ResponseWriter, r *http.Request) {
+	proposals, err := ui.service.planningRun(r.Context())
+	if err != nil {
+		slog.Error("Unable to run planning", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: fmt.Sprintf("Success! The planning run created %v purchase proposals.", len(proposals))})
+	http.Redirect(w, r, "/logistics/purchase-proposals?status="+ProposalStatusOpen, http.StatusFound)
+}
+
+func (ui UI) acceptPurchaseProposal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	order, err := ui.service.acceptPurchaseProposal(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to accept purchase proposal", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The purchase order has been created."})
+	http.Redirect(w, r, order.Redirect(), http.StatusFound)
+}
+
+func (ui UI) discardPurchaseProposal(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	proposal, err := ui.service.discardPurchaseProposal(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to discard purchase proposal", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The purchase proposal has been discarded."})
+	http.Redirect(w, r, proposal.Redirect(), http.StatusFound)
+}
+
+// maxPostalCodeFileSize is the largest postal code dump that can be uploaded, it fits the dumps of single countries.
+const maxPostalCodeFileSize = 64 << 20
+
+// plantGeoJSON returns the plants with coordinates as GeoJSON points for the plant map.
+func (ui UI) plantGeoJSON(w http.ResponseWriter, r *http.Request) {
+	locations, err := ui.service.plantLocations(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query plant locations", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(locations))}
+	for _, location := range locations {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{location.Longitude, location.Latitude},
+			},
+			Properties: map[string]any{
+				"id":      location.ID,
+				"name":    location.Name,
+				"address": fmt.Sprintf("%v, %v %v", location.Street, location.ZIP, location.City),
+				"url":     location.Redirect(),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	if err := json.NewEncoder(w).Encode(collection); err != nil {
+		slog.Error("Unable to write plant locations", "error", err)
+	}
+}
+
+// plantNearbyView lists the plants within the radius of the query or, without a radius, the nearest plants. The list
+// is returned as JSON if the path ends in .json.
+func (ui UI) plantNearbyView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	if query.Get("limit") == "" {
+		query.Set("limit", "5")
+	}
+	format, _ := ctx.Value(middleware.URLFormatCtxKey).(string)
+
+	data := plantNearbyData{
+		Message:  flash.Get(w, r),
+		Query:    query,
+		Searched: query.Get("address_id") != "" || query.Get("latitude") != "",
+	}
+
+	if data.Searched {
+		plants, err := ui.findPlants(ctx, query)
+		switch {
+		case errors.Is(err, xerrors.ErrBadRequest) && format == "json":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, xerrors.ErrBadRequest):
+			data.Message = flash.Message{Level: flash.Error, Content: err.Error()}
+		case err != nil:
+			slog.Error("Unable to find plants", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		data.Plants = plants
+	}
+
+	if format == "json" {
+		if data.Plants == nil {
+			data.Plants = []PlantDistance{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(data.Plants); err != nil {
+			slog.Error("Unable to write plants", "error", err)
+		}
+		return
+	}
+
+	addresses, err := ui.service.addresses(ctx, AddressFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query addresses", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	data.Addresses = addresses
+
+	if err := ui.templates["plant-nearby"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// findPlants finds the plants near the address or the latitude and longitude of values, within the radius if one is
+// given, otherwise the limit nearest ones.
+func (ui UI) findPlants(ctx context.Context, values url.Values) ([]PlantDistance, error) {
+	var center Coordinates
+	if addressID := values.Get("address_id"); addressID != "" {
+		id, err := strconv.ParseInt(addressID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to convert address id to integer", xerrors.ErrBadRequest)
+		}
+		if center, err = ui.service.addressCoordinates(ctx, id); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if center.Latitude, err = strconv.ParseFloat(values.Get("latitude"), 64); err != nil {
+			return nil, fmt.Errorf("%w: latitude has to be a number", xerrors.ErrBadRequest)
+		}
+		if center.Longitude, err = strconv.ParseFloat(values.Get("longitude"), 64); err != nil {
+			return nil, fmt.Errorf("%w: longitude has to be a number", xerrors.ErrBadRequest)
+		}
+	}
+
+	if radius := values.Get("radius"); radius != "" {
+		km, err := strconv.ParseFloat(radius, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: radius has to be a number", xerrors.ErrBadRequest)
+		}
+		return ui.service.plantsWithin(ctx, center, km)
+	}
+
+	limit, err := strconv.Atoi(values.Get("limit"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: the number of plants has to be a whole number", xerrors.ErrBadRequest)
+	}
+	return ui.service.nearestPlants(ctx, center, limit)
+}
+
+func (ui UI) postalCodeView(w http.ResponseWriter, r *http.Request) {
+	countries, err := ui.service.postalCodeCountries(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query postal code countries", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := postalCodeData{
+		Message:   flash.Get(w, r),
+		Countries: countries,
+	}
+	if err := ui.templates["postal-code-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) importPostalCodes(w http.ResponseWriter, r *http.Request) {
+	const redirect = "/logistics/postal-codes/"
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPostalCodeFileSize)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: "Unable to read the uploaded postal codes, the file might be too large."})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	defer file.Close()
+
+	count, err := ui.service.importPostalCodes(r.Context(), file)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: err.Error()})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to import postal codes", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: fmt.Sprintf("Success! %v places have been imported.", count)})
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// maxCountSheetSize is the largest count sheet that can be uploaded.
+const maxCountSheetSize = 1 << 20
+
+func (ui UI) makeAdditionalInventoryCountData(ctx context.Context, w http.ResponseWriter, r *http.Request, count *InventoryCount) (inventoryCountData, error) {
+	items, plants, err := ui.itemsAndPlants(ctx)
+	if err != nil {
+		return inventoryCountData{}, err
+	}
+
+	data := inventoryCountData{
+		Message:  flash.Get(w, r),
+		Resource: count,
+		Date:     time.Now().Format(time.DateOnly),
+		Plants:   plants,
+	}
+	for _, item := range items {
+		if item.Tracking != TrackingSerial {
+			data.Items = append(data.Items, item)
+		}
+	}
+
+	if count != nil {
+		if data.Plant, err = ui.service.plant(ctx, count.PlantID); err != nil {
+			return inventoryCountData{}, err
+		}
+	}
+
+	return data, nil
+}
+
+func (ui UI) inventoryCountListView(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := InventoryCountFilter{}
+	var err error
+	if filter.plantID, err = parseNullID(query, "plant_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if status := query.Get("status"); status != "" {
+		filter.status = sql.NullString{Valid: true, String: status}
+	}
+
+	counts, err := ui.service.inventoryCounts(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query inventory counts", "error", err)
+		http.Error(w, "unable to query resources", http.StatusInternalServerError)
+		return
+	}
+
+	_, plants, err := ui.itemsAndPlants(r.Context())
+	if err != nil {
+		slog.Error("Unable to query plants", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := inventoryCountListData{
+		Message:    flash.Get(w, r),
+		Resources:  counts,
+		Query:      query,
+		Plants:     plants,
+		PlantNames: make(map[int64]string, len(plants)),
+	}
+	for _, plant := range plants {
+		data.PlantNames[plant.ID] = plant.Name
+	}
+
+	if err := ui.templates["inventory-count-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseInventoryCountForm(values url.Values) (InventoryCountParams, error) {
+	params := InventoryCountParams{
+		Date: values.Get("date"),
+		Note: values.Get("note"),
+	}
+
+	var err error
+	if params.PlantID, err = strconv.ParseInt(values.Get("plant_id"), 10, 64); err != nil {
+		return InventoryCountParams{}, fmt.Errorf("unable to parse inventory count plant_id to integer: %w", err)
+	}
+
+	for _, value := range values["item_ids"] {
+		itemID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return InventoryCountParams{}, fmt.Errorf("unable to parse inventory count item_ids to integer: %w", err)
+		}
+		params.ItemIDs = append(params.ItemIDs, itemID)
+	}
+
+	return params, nil
+}
+
+func (ui UI) setCountedQuantities(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	quantities, err := parseCountedQuantities(r.PostForm)
+	if err != nil {
+		xui.RedirectBadRequest(w, r, fmt.Errorf("%w: %v", xerrors.ErrBadRequest, err))
+		return
+	}
+
+	count, err := ui.service.setCountedQuantities(r.Context(), id, quantities)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to set counted quantities", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The counted quantities have been saved."})
+	http.Redirect(w, r, count.Redirect(), http.StatusFound)
+}
+
+// parseCountedQuantities parses the lines[] fields of the inventory count form, lines without a counted quantity are
+// left out.
+func parseCountedQuantities(values url.Values) (CountedQuantities, error) {
+	lineIDs := values["lines[].line_id"]
+	if len(values["lines[].counted"]) != len(lineIDs) {
+		return nil, errors.New("incomplete count lines")
+	}
+
+	quantities := make(CountedQuantities, len(lineIDs))
+	for i := 0; i < len(lineIDs); i++ {
+		counted := values["lines[].counted"][i]
+		if counted == "" {
+			continue
+		}
+
+		lineID, err := strconv.ParseInt(lineIDs[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse line id to integer: %w", err)
+		}
+		if quantities[lineID], err = strconv.ParseFloat(counted, 64); err != nil {
+			return nil, fmt.Errorf("unable to parse counted quantity to number: %w", err)
+		}
+	}
+
+	return quantities, nil
+}
+
+// countSheet serves the lines of an inventory count as CSV, the counted column is filled in and the sheet uploaded
+// again. Book quantities are left out, so they do not influence the counting.
+func (ui UI) countSheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	count, err := ui.service.inventoryCount(r.Context(), id)
+	if err != nil {
+		slog.Error("Unable to query inventory count", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+count.Reference()+`.csv"`)
+
+	writer := csv.NewWriter(w)
+	records := [][]string{{"line", "sku", "item", "bin", "batch", "unit", "counted"}}
+	for _, line := range count.Lines {
+		counted := ""
+		if line.CountedQuantity.Valid {
+			counted = strconv.FormatFloat(line.CountedQuantity.Float64, 'f', -1, 64)
+		}
+		records = append(records, []string{strconv.FormatInt(line.ID, 10), line.ItemSKU, line.ItemName, line.BinName.String,
+			line.BatchNumber.String, line.UnitCode, counted})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		slog.Error("Unable to write count sheet", "error", err)
+	}
+}
+
+func (ui UI) importCountSheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+	redirect := InventoryCountHeader{ID: id}.Redirect()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCountSheetSize)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: "Unable to read the uploaded count sheet, it might be too large."})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	defer file.Close()
+
+	_, err = ui.service.importCountSheet(r.Context(), id, file)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		flash.Set(w, flash.Message{Level: flash.Error, Content: err.Error()})
+		http.Redirect(w, r, redirect, http.StatusFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to import count sheet", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The count sheet has been imported."})
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (ui UI) approveInventoryCount(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	count, err := ui.service.approveInventoryCount(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to approve inventory count", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The inventory count has been approved and its differences posted."})
+	http.Redirect(w, r, count.Redirect(), http.StatusFound)
+}
+
+func (ui UI) cancelInventoryCount(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "malformatted id", http.StatusBadRequest)
+		return
+	}
+
+	count, err := ui.service.cancelInventoryCount(r.Context(), id)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to cancel inventory count", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! The inventory count has been cancelled."})
+	http.Redirect(w, r, count.Redirect(), http.StatusFound)
 }
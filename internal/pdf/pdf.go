@@ -0,0 +1,326 @@
+// Package pdf writes simple PDF documents consisting of text, lines and rectangles. It only supports the standard
+// Helvetica fonts, which every PDF reader provides, so no fonts have to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page sizes in points (1/72 inch).
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// MM converts millimeters to points.
+func MM(mm float64) float64 {
+	return mm * 72 / 25.4
+}
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+type Align int
+
+const (
+	Left Align = iota
+	Right
+	Center
+)
+
+// Document is a PDF document. Coordinates are given in points with the origin in the top left corner of a page.
+type Document struct {
+	width  float64
+	height float64
+	pages  []*bytes.Buffer
+}
+
+func New(width float64, height float64) *Document {
+	return &Document{
+		width:  width,
+		height: height,
+	}
+}
+
+func (d *Document) Width() float64 {
+	return d.width
+}
+
+func (d *Document) Height() float64 {
+	return d.height
+}
+
+// AddPage starts a new page, all following drawing operations draw on it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// PageCount returns the amount of pages added so far.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline at y. x is the left, right or center of the text depending on align.
+func (d *Document) Text(x float64, y float64, font Font, size float64, align Align, s string) {
+	d.text(d.page(), x, y, font, size, align, s)
+}
+
+func (d *Document) text(page *bytes.Buffer, x float64, y float64, font Font, size float64, align Align, s string) {
+	switch align {
+	case Right:
+		x -= TextWidth(font, size, s)
+	case Center:
+		x -= TextWidth(font, size, s) / 2
+	}
+
+	fmt.Fprintf(page, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font+1, num(size), num(x), num(d.height-y), escape(s))
+}
+
+// Line draws a line from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1 float64, y1 float64, x2 float64, y2 float64, width float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s m %s %s l S\n", num(width), num(x1), num(d.height-y1), num(x2), num(d.height-y2))
+}
+
+// Rect draws a filled black rectangle with its top left corner at (x, y).
+func (d *Document) Rect(x float64, y float64, width float64, height float64) {
+	fmt.Fprintf(d.page(), "%s %s %s %s re f\n", num(x), num(d.height-y-height), num(width), num(height))
+}
+
+// StrokeRect draws the outline of a rectangle with its top left corner at (x, y).
+func (d *Document) StrokeRect(x float64, y float64, width float64, height float64, lineWidth float64) {
+	fmt.Fprintf(d.page(), "%s w %s %s %s %s re S\n", num(lineWidth), num(x), num(d.height-y-height), num(width), num(height))
+}
+
+// Bars draws a barcode with its top left corner at (x, y), every module is moduleWidth wide and true modules are filled.
+func (d *Document) Bars(x float64, y float64, moduleWidth float64, height float64, modules []bool) {
+	page := d.page()
+	if d.bars(page, x, y, moduleWidth, height, modules) {
+		fmt.Fprint(page, "f\n")
+	}
+}
+
+// Matrix draws a two-dimensional barcode like a QR code with its top left corner at (x, y), modules are indexed by row
+// and column and every module is a square of moduleSize.
+func (d *Document) Matrix(x float64, y float64, moduleSize float64, modules [][]bool) {
+	page := d.page()
+	filled := false
+	for row, rowModules := range modules {
+		if d.bars(page, x, y+float64(row)*moduleSize, moduleSize, moduleSize, rowModules) {
+			filled = true
+		}
+	}
+	if filled {
+		fmt.Fprint(page, "f\n")
+	}
+}
+
+// bars adds a rectangle for every run of true modules to the current path and reports whether it added any.
+func (d *Document) bars(page *bytes.Buffer, x float64, y float64, moduleWidth float64, height float64, modules []bool) bool {
+	added := false
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(modules) && modules[i] {
+			i++
+		}
+		fmt.Fprintf(page, "%s %s %s %s re\n", num(x+float64(start)*moduleWidth), num(d.height-y-height), num(float64(i-start)*moduleWidth), num(height))
+		added = true
+	}
+	return added
+}
+
+// Gray sets the gray level used for following text and shapes, 0 is black and 1 is white.
+func (d *Document) Gray(level float64) {
+	fmt.Fprintf(d.page(), "%s g %s G\n", num(level), num(level))
+}
+
+// WriteTo writes the document. A document without pages is written with one empty page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	out := &countingWriter{w: w}
+	var offsets []int64
+
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	fmt.Fprint(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are fixed, pages and their content streams follow in pairs.
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+i*2))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(d.width), num(d.height), 6+i*2))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return out.n, err
+		}
+		if err := zw.Close(); err != nil {
+			return out.n, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.n, out.err
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// escape encodes s as WinAnsi and escapes it for use in a PDF string. Characters which can not be encoded are replaced by a question mark.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c := winAnsi(r)
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// winAnsiSpecials maps the characters of WinAnsiEncoding which differ from Latin-1.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c,
+	'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+func winAnsi(r rune) byte {
+	switch {
+	case r >= 0x20 && r < 0x7f:
+		return byte(r)
+	case r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	case r == '\t' || r == '\n' || r == '\r':
+		return ' '
+	}
+
+	if c, ok := winAnsiSpecials[r]; ok {
+		return c
+	}
+	return '?'
+}
+
+// TextWidth returns the width of s in points.
+func TextWidth(font Font, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+
+	var total int
+	for _, r := range s {
+		c := winAnsi(r)
+		if c >= 32 && c < 127 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+
+	return float64(total) * size / 1000
+}
+
+// Truncate shortens s so it fits into width, truncated text ends with dots.
+func Truncate(font Font, size float64, width float64, s string) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if t := string(runes) + "..."; TextWidth(font, size, t) <= width {
+			return t
+		}
+	}
+	return ""
+}
+
+// Glyph widths of the printable ASCII characters (32 to 126) in 1/1000 of the font size, taken from the Adobe font metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
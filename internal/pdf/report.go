@@ -0,0 +1,178 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Letterhead is printed at the top of the first page of every report.
+type Letterhead struct {
+	Name  string
+	Lines []string // Lines are printed below the name, e.g. address and contact details.
+}
+
+type Column struct {
+	Title string
+	Width float64 // Width is the relative width of the column, widths of all columns are scaled to the page.
+	Align Align
+}
+
+// Report lays out a business document on A4 pages: letterhead, title, header fields, tables and totals. New pages are
+// started automatically and every page gets a page number.
+type Report struct {
+	doc        *Document
+	letterhead Letterhead
+	title      string
+	y          float64
+	numbered   bool
+}
+
+const (
+	reportMargin   = 50
+	reportFontSize = 9
+	reportLeading  = 14
+)
+
+func NewReport(letterhead Letterhead, title string) *Report {
+	r := &Report{
+		doc:        New(A4Width, A4Height),
+		letterhead: letterhead,
+		title:      title,
+	}
+	r.doc.AddPage()
+	r.y = reportMargin
+
+	if letterhead.Name != "" {
+		r.doc.Text(A4Width-reportMargin, r.y+12, HelveticaBold, 14, Right, letterhead.Name)
+		r.y += 18
+		for _, line := range letterhead.Lines {
+			r.doc.Text(A4Width-reportMargin, r.y+reportFontSize, Helvetica, reportFontSize, Right, line)
+			r.y += 12
+		}
+		r.y += 6
+		r.doc.Line(reportMargin, r.y, A4Width-reportMargin, r.y, 0.5)
+		r.y += 20
+	}
+
+	r.doc.Text(reportMargin, r.y+16, HelveticaBold, 18, Left, title)
+	r.y += 36
+
+	return r
+}
+
+// Fields prints labeled values, one per line.
+func (r *Report) Fields(fields [][2]string) {
+	for _, field := range fields {
+		r.ensureSpace(reportLeading)
+		r.doc.Text(reportMargin, r.y+reportFontSize, HelveticaBold, reportFontSize, Left, field[0])
+		r.doc.Text(reportMargin+120, r.y+reportFontSize, Helvetica, reportFontSize, Left, field[1])
+		r.y += reportLeading
+	}
+	r.y += reportLeading
+}
+
+// Heading prints a section heading.
+func (r *Report) Heading(s string) {
+	r.ensureSpace(reportLeading * 3)
+	r.doc.Text(reportMargin, r.y+12, HelveticaBold, 12, Left, s)
+	r.y += reportLeading * 1.5
+}
+
+// Table prints rows below a header row. The header is repeated if the table continues on a new page.
+func (r *Report) Table(columns []Column, rows [][]string) {
+	var total float64
+	for _, column := range columns {
+		total += column.Width
+	}
+
+	available := A4Width - 2*reportMargin
+	widths := make([]float64, len(columns))
+	for i, column := range columns {
+		widths[i] = column.Width / total * available
+	}
+
+	header := func() {
+		r.ensureSpace(reportLeading * 2)
+		r.row(columns, widths, HelveticaBold, func(i int) string { return columns[i].Title })
+		r.doc.Line(reportMargin, r.y-3, A4Width-reportMargin, r.y-3, 0.5)
+		r.y += 3
+	}
+
+	header()
+	for _, row := range rows {
+		if r.y+reportLeading > A4Height-reportMargin {
+			r.newPage()
+			header()
+		}
+		r.row(columns, widths, Helvetica, func(i int) string {
+			if i < len(row) {
+				return row[i]
+			}
+			return ""
+		})
+	}
+	r.doc.Line(reportMargin, r.y-3, A4Width-reportMargin, r.y-3, 0.5)
+	r.y += reportLeading
+}
+
+func (r *Report) row(columns []Column, widths []float64, font Font, cell func(i int) string) {
+	const padding = 4
+
+	x := float64(reportMargin)
+	for i, column := range columns {
+		text := Truncate(font, reportFontSize, widths[i]-2*padding, cell(i))
+		switch column.Align {
+		case Right:
+			r.doc.Text(x+widths[i]-padding, r.y+reportFontSize, font, reportFontSize, Right, text)
+		case Center:
+			r.doc.Text(x+widths[i]/2, r.y+reportFontSize, font, reportFontSize, Center, text)
+		default:
+			r.doc.Text(x+padding, r.y+reportFontSize, font, reportFontSize, Left, text)
+		}
+		x += widths[i]
+	}
+	r.y += reportLeading
+}
+
+// Totals prints labeled values aligned to the right edge of the page.
+func (r *Report) Totals(totals [][2]string) {
+	for _, total := range totals {
+		r.ensureSpace(reportLeading)
+		r.doc.Text(A4Width-reportMargin-120, r.y+reportFontSize, HelveticaBold, reportFontSize, Right, total[0])
+		r.doc.Text(A4Width-reportMargin-4, r.y+reportFontSize, Helvetica, reportFontSize, Right, total[1])
+		r.y += reportLeading
+	}
+	r.y += reportLeading
+}
+
+// Paragraph prints a single line of text.
+func (r *Report) Paragraph(s string) {
+	r.ensureSpace(reportLeading)
+	r.doc.Text(reportMargin, r.y+reportFontSize, Helvetica, reportFontSize, Left, s)
+	r.y += reportLeading
+}
+
+func (r *Report) ensureSpace(height float64) {
+	if r.y+height > A4Height-reportMargin {
+		r.newPage()
+	}
+}
+
+func (r *Report) newPage() {
+	r.doc.AddPage()
+	r.y = reportMargin
+	r.doc.Text(reportMargin, r.y+reportFontSize, HelveticaBold, reportFontSize, Left, r.title)
+	r.y += reportLeading * 2
+}
+
+// WriteTo adds page numbers and writes the report.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	if !r.numbered {
+		for i, page := range r.doc.pages {
+			r.doc.text(page, A4Width-reportMargin, A4Height-reportMargin/2, Helvetica, 8, Right, fmt.Sprintf("Page %d of %d", i+1, len(r.doc.pages)))
+		}
+		r.numbered = true
+	}
+
+	return r.doc.WriteTo(w)
+}
@@ -0,0 +1,324 @@
+package valuation
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/database"
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+//go:embed schema.sql
+var Schema string
+
+type Database struct {
+	db database.Querier
+}
+
+func MakeDatabase(db database.Querier) Database {
+	return Database{
+		db: db,
+	}
+}
+
+// lockQuery serializes valuations, every valuation reads the stock values and price of an item and writes them back.
+const lockQuery = `LOCK TABLE valuation.items IN EXCLUSIVE MODE`
+
+func (db Database) settings(ctx context.Context) (Settings, error) {
+	const query = `
+SELECT currency_id, inventory_account_id, receipt_account_id, issue_account_id, adjustment_account_id, price_difference_account_id
+FROM valuation.settings
+WHERE id = 1
+`
+
+	return database.One[Settings](ctx, db.db, query)
+}
+
+func (db Database) setSettings(ctx context.Context, params SettingsParams) (Settings, error) {
+	const query = `
+INSERT INTO valuation.settings (id, currency_id, inventory_account_id, receipt_account_id, issue_account_id, adjustment_account_id, price_difference_account_id)
+VALUES (1, $1, $2, $3, $4, $5, $6)
+ON CONFLICT (id) DO UPDATE SET
+	currency_id                 = EXCLUDED.currency_id,
+	inventory_account_id        = EXCLUDED.inventory_account_id,
+	receipt_account_id          = EXCLUDED.receipt_account_id,
+	issue_account_id            = EXCLUDED.issue_account_id,
+	adjustment_account_id       = EXCLUDED.adjustment_account_id,
+	price_difference_account_id = EXCLUDED.price_difference_account_id
+RETURNING currency_id, inventory_account_id, receipt_account_id, issue_account_id, adjustment_account_id, price_difference_account_id
+`
+
+	return database.One[Settings](ctx, db.db, query, params.CurrencyID, params.InventoryAccountID, params.ReceiptAccountID,
+		params.IssueAccountID, params.AdjustmentAccountID, params.PriceDifferenceAccountID)
+}
+
+func (db Database) itemValuation(ctx context.Context, itemID int64) (ItemValuation, error) {
+	return queryItemValuation(ctx, db.db, itemID)
+}
+
+// queryItemValuation returns the valuation of an item, items without a valuation get the defaults.
+func queryItemValuation(ctx context.Context, q database.Querier, itemID int64) (ItemValuation, error) {
+	const query = `
+SELECT
+	$1::INTEGER AS item_id,
+	COALESCE((SELECT method FROM valuation.items WHERE item_id = $1), 'moving_average') AS method,
+	COALESCE((SELECT price FROM valuation.items WHERE item_id = $1), 0) AS price,
+	COALESCE((SELECT SUM(quantity) FROM valuation.entries WHERE item_id = $1), 0) AS quantity,
+	COALESCE((SELECT SUM(value) FROM valuation.entries WHERE item_id = $1), 0) AS value
+`
+
+	return database.One[ItemValuation](ctx, q, query, itemID)
+}
+
+// itemValuations returns the valuations of all items that have been valued or had their price set.
+func (db Database) itemValuations(ctx context.Context) ([]ItemValuation, error) {
+	const query = `
+SELECT
+	items.item_id,
+	items.method,
+	items.price,
+	COALESCE(SUM(entries.quantity), 0) AS quantity,
+	COALESCE(SUM(entries.value), 0) AS value
+FROM valuation.items
+LEFT JOIN valuation.entries ON entries.item_id = items.item_id
+GROUP BY items.item_id
+ORDER BY items.item_id ASC
+`
+
+	return database.Many[ItemValuation](ctx, db.db, query)
+}
+
+func (db Database) stockValues(ctx context.Context, filter StockValueFilter) ([]StockValue, error) {
+	return queryStockValues(ctx, db.db, filter)
+}
+
+func queryStockValues(ctx context.Context, q database.Querier, filter StockValueFilter) ([]StockValue, error) {
+	const query = `
+SELECT item_id, plant_id, SUM(quantity) AS quantity, SUM(value) AS value
+FROM valuation.entries
+WHERE
+	(item_id  = $1 OR $1 IS NULL) AND
+	(plant_id = $2 OR $2 IS NULL)
+GROUP BY item_id, plant_id
+HAVING SUM(quantity) <> 0 OR SUM(value) <> 0
+ORDER BY plant_id ASC, item_id ASC
+`
+
+	return database.Many[StockValue](ctx, q, query, filter.itemID, filter.plantID)
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Valid: id != 0, Int64: id}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{Valid: value != "", String: value}
+}
+
+func queryPlantStock(ctx context.Context, q database.Querier, itemID int64, plantID int64) (plantStock, error) {
+	const query = `
+SELECT COALESCE(SUM(quantity), 0) AS quantity, COALESCE(SUM(value), 0) AS value
+FROM valuation.entries
+WHERE item_id = $1 AND plant_id = $2
+`
+
+	return database.One[plantStock](ctx, q, query, itemID, plantID)
+}
+
+func (db Database) posting(ctx context.Context, id int64) (Posting, error) {
+	const query = `
+SELECT *
+FROM valuation.postings
+WHERE id = $1
+`
+
+	return database.One[Posting](ctx, db.db, query, id)
+}
+
+func (db Database) postings(ctx context.Context, filter PostingFilter) ([]Posting, error) {
+	const query = `
+SELECT *
+FROM valuation.postings
+WHERE
+	(item_id = $1 OR $1 IS NULL) AND
+	(status  = $2 OR $2 IS NULL)
+ORDER BY id DESC
+`
+
+	return database.Many[Posting](ctx, db.db, query, filter.itemID, filter.status)
+}
+
+func (db Database) entries(ctx context.Context, postingID int64) ([]Entry, error) {
+	const query = `
+SELECT *
+FROM valuation.entries
+WHERE posting_id = $1
+ORDER BY id ASC
+`
+
+	return database.Many[Entry](ctx, db.db, query, postingID)
+}
+
+// valueMovements values all goods movements that have not been valued yet in the order they were recorded and returns
+// how many it valued. Movements are read from the logistics schema directly, so movements committed out of order are
+// not skipped.
+func (db Database) valueMovements(ctx context.Context) (int, error) {
+	const movementsQuery = `
+SELECT
+	movements.*,
+	items.name AS item_name,
+	(lines.price::NUMERIC * lines.quantity / NULLIF(lines.base_quantity, 0))::FLOAT8 AS order_price
+FROM logistics.goods_movements movements
+JOIN logistics.items ON items.id = movements.item_id
+LEFT JOIN logistics.purchase_order_lines lines ON lines.id = movements.purchase_order_line_id
+WHERE NOT EXISTS (SELECT 1 FROM valuation.postings WHERE postings.movement_id = movements.id)
+ORDER BY movements.id ASC
+`
+
+	var valued int
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		movements, err := database.Many[pendingMovement](ctx, tx, movementsQuery)
+		if errors.Is(err, xerrors.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, movement := range movements {
+			item, err := queryItemValuation(ctx, tx, movement.ItemID)
+			if err != nil {
+				return err
+			}
+
+			var from, to plantStock
+			if movement.FromPlantID.Valid {
+				if from, err = queryPlantStock(ctx, tx, movement.ItemID, movement.FromPlantID.Int64); err != nil {
+					return err
+				}
+			}
+			if movement.ToPlantID.Valid {
+				if to, err = queryPlantStock(ctx, tx, movement.ItemID, movement.ToPlantID.Int64); err != nil {
+					return err
+				}
+			}
+
+			if err := insertPosting(ctx, tx, valueMovement(movement, item, from, to)); err != nil {
+				return err
+			}
+		}
+
+		valued = len(movements)
+		return nil
+	})
+
+	return valued, err
+}
+
+// updateItemValuation sets the method and price of an item and revalues its stock in every plant to the new price.
+func (db Database) updateItemValuation(ctx context.Context, itemID int64, itemName string, date string, params ItemValuationParams) (ItemValuation, error) {
+	const query = `
+INSERT INTO valuation.items (item_id, method, price)
+VALUES ($1, $2, $3)
+ON CONFLICT (item_id) DO UPDATE SET method = EXCLUDED.method, price = EXCLUDED.price
+`
+
+	var item ItemValuation
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		stocks, err := queryStockValues(ctx, tx, StockValueFilter{itemID: nullID(itemID)})
+		if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, query, itemID, params.Method, params.Price); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+
+		if posting, ok := revaluation(itemID, itemName, date, params.Price, stocks); ok {
+			if err := insertPosting(ctx, tx, posting); err != nil {
+				return err
+			}
+		}
+
+		item, err = queryItemValuation(ctx, tx, itemID)
+		return err
+	})
+
+	return item, err
+}
+
+// insertPosting inserts a posting with its entries and stores the price of the item after the posting.
+func insertPosting(ctx context.Context, q database.Querier, posting postingValues) error {
+	const priceQuery = `
+INSERT INTO valuation.items (item_id, price)
+VALUES ($1, $2)
+ON CONFLICT (item_id) DO UPDATE SET price = EXCLUDED.price
+`
+
+	const postingQuery = `
+INSERT INTO valuation.postings (movement_id, item_id, type, date, reference, description, value, offset_value, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *
+`
+
+	const entryQuery = `
+INSERT INTO valuation.entries (posting_id, item_id, plant_id, quantity, value)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+	if _, err := q.Exec(ctx, priceQuery, posting.ItemID, posting.Price); err != nil {
+		return xerrors.Join(xerrors.ErrInternal, err)
+	}
+
+	inserted, err := database.One[Posting](ctx, q, postingQuery, posting.MovementID, posting.ItemID, posting.Type, posting.Date,
+		posting.Reference, posting.Description, posting.Value, posting.OffsetValue, posting.Status)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range posting.Entries {
+		if _, err := q.Exec(ctx, entryQuery, inserted.ID, posting.ItemID, entry.PlantID, entry.Quantity, entry.Value); err != nil {
+			return xerrors.Join(xerrors.ErrInternal, err)
+		}
+	}
+
+	return nil
+}
+
+// postPosting posts the document of a pending posting and marks the posting as posted in one transaction. It fails
+// with ErrNotFound if the posting is no longer pending, the document is rolled back then.
+func (db Database) postPosting(ctx context.Context, id int64, document accounting.DocumentParams) (Posting, error) {
+	const query = `
+UPDATE valuation.postings
+SET
+	status      = $3,
+	document_id = $2
+WHERE id = $1 AND status = $4
+RETURNING *
+`
+
+	var posting Posting
+	err := database.Transaction(ctx, db.db, func(tx pgx.Tx) error {
+		posted, err := accounting.InsertDocument(ctx, tx, document)
+		if err != nil {
+			return err
+		}
+
+		posting, err = database.One[Posting](ctx, tx, query, id, posted.ID, PostingStatusPosted, PostingStatusPending)
+		return err
+	})
+
+	return posting, err
+}
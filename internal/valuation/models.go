@@ -0,0 +1,187 @@
+package valuation
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/tombuente/apex/internal/logistics"
+)
+
+const (
+	MethodStandard      = "standard"
+	MethodMovingAverage = "moving_average"
+)
+
+// Postings of goods movements have the type of their movement, revaluations are posted when the price of an item is
+// changed.
+const PostingTypeRevaluation = "revaluation"
+
+const (
+	PostingStatusPending = "pending"
+	PostingStatusPosted  = "posted"
+)
+
+// Settings are the accounts valuation postings are posted to. The inventory account carries the stock value, the
+// offset account of a posting depends on its type and differences between the stock value and the offset, like receipts
+// of standard price items at another order price, go to the price difference account.
+type Settings struct {
+	CurrencyID               int64 `json:"currency_id" db:"currency_id"`
+	InventoryAccountID       int64 `json:"inventory_account_id" db:"inventory_account_id"`
+	ReceiptAccountID         int64 `json:"receipt_account_id" db:"receipt_account_id"`
+	IssueAccountID           int64 `json:"issue_account_id" db:"issue_account_id"`
+	AdjustmentAccountID      int64 `json:"adjustment_account_id" db:"adjustment_account_id"`
+	PriceDifferenceAccountID int64 `json:"price_difference_account_id" db:"price_difference_account_id"`
+}
+
+type SettingsParams struct {
+	CurrencyID               int64 `form:"currency_id"`
+	InventoryAccountID       int64 `form:"inventory_account_id"`
+	ReceiptAccountID         int64 `form:"receipt_account_id"`
+	IssueAccountID           int64 `form:"issue_account_id"`
+	AdjustmentAccountID      int64 `form:"adjustment_account_id"`
+	PriceDifferenceAccountID int64 `form:"price_difference_account_id"`
+}
+
+// ItemValuation is the valuation price of an item per base unit together with the stock quantity and value of all
+// plants. Items without a valuation are valued at a moving average price of zero.
+type ItemValuation struct {
+	ItemID   int64   `json:"item_id" db:"item_id"`
+	Method   string  `json:"method" db:"method"`
+	Price    int64   `json:"price" db:"price"`
+	Quantity float64 `json:"quantity" db:"quantity"`
+	Value    int64   `json:"value" db:"value"`
+}
+
+// ItemValuationParams change the method and price of an item, stock on hand is revalued to the new price.
+type ItemValuationParams struct {
+	Method string `form:"method"`
+	Price  int64  `form:"price"`
+}
+
+// Posting is the value change of a goods movement or a revaluation. Pending postings have not been posted to the
+// accounting ledger yet, postings without a value are posted without a document.
+type Posting struct {
+	ID          int64         `json:"id" db:"id"`
+	MovementID  sql.NullInt64 `json:"movement_id" db:"movement_id"`
+	ItemID      int64         `json:"item_id" db:"item_id"`
+	Type        string        `json:"type" db:"type"`
+	Date        string        `json:"date" db:"date"`
+	Reference   string        `json:"reference" db:"reference"`
+	Description string        `json:"description" db:"description"`
+	// Value is the change of the stock value, OffsetValue is posted to the offset account of the type.
+	Value       int64         `json:"value" db:"value"`
+	OffsetValue int64         `json:"offset_value" db:"offset_value"`
+	Status      string        `json:"status" db:"status"`
+	DocumentID  sql.NullInt64 `json:"document_id" db:"document_id"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// Entry is the quantity and value change of a posting in a plant.
+type Entry struct {
+	ID        int64   `json:"id" db:"id"`
+	PostingID int64   `json:"posting_id" db:"posting_id"`
+	ItemID    int64   `json:"item_id" db:"item_id"`
+	PlantID   int64   `json:"plant_id" db:"plant_id"`
+	Quantity  float64 `json:"quantity" db:"quantity"`
+	Value     int64   `json:"value" db:"value"`
+}
+
+type PostingFilter struct {
+	itemID sql.NullInt64
+	status sql.NullString
+}
+
+// StockValue is the stock quantity and value of an item in a plant.
+type StockValue struct {
+	ItemID   int64   `json:"item_id" db:"item_id"`
+	PlantID  int64   `json:"plant_id" db:"plant_id"`
+	Quantity float64 `json:"quantity" db:"quantity"`
+	Value    int64   `json:"value" db:"value"`
+}
+
+type StockValueFilter struct {
+	itemID  sql.NullInt64
+	plantID sql.NullInt64
+}
+
+// pendingMovement is a goods movement that has not been valued yet. OrderPrice is the price per base unit of the
+// purchase order line of receipts against purchase orders.
+type pendingMovement struct {
+	logistics.GoodsMovement
+	ItemName   string          `db:"item_name"`
+	OrderPrice sql.NullFloat64 `db:"order_price"`
+}
+
+// plantStock is the valued stock of an item in a plant.
+type plantStock struct {
+	Quantity float64 `db:"quantity"`
+	Value    int64   `db:"value"`
+}
+
+// postingValues are the values of a posting and its entries before they are inserted, Price is the valuation price
+// of the item after the posting.
+type postingValues struct {
+	MovementID  sql.NullInt64
+	ItemID      int64
+	Type        string
+	Date        string
+	Reference   string
+	Description string
+	Value       int64
+	OffsetValue int64
+	Status      string
+	Price       int64
+	Entries     []entryValues
+}
+
+type entryValues struct {
+	PlantID  int64
+	Quantity float64
+	Value    int64
+}
+
+// Configured reports whether the accounts have been set, postings stay pending until they are.
+func (settings Settings) Configured() bool {
+	return settings.InventoryAccountID != 0
+}
+
+// offsetAccountID returns the account the offset value of postings of the type is posted to. Revaluations have no
+// offset value, their whole value is a price difference.
+func (settings Settings) offsetAccountID(postingType string) int64 {
+	switch postingType {
+	case logistics.MovementReceipt:
+		return settings.ReceiptAccountID
+	case logistics.MovementIssue:
+		return settings.IssueAccountID
+	case logistics.MovementAdjustment:
+		return settings.AdjustmentAccountID
+	default:
+		return settings.PriceDifferenceAccountID
+	}
+}
+
+func (item ItemValuation) GetID() string {
+	return strconv.FormatInt(item.ItemID, 10)
+}
+
+func (item ItemValuation) Redirect() string {
+	return "/valuation/items/" + item.GetID()
+}
+
+func (posting Posting) GetID() string {
+	return strconv.FormatInt(posting.ID, 10)
+}
+
+func (posting Posting) Redirect() string {
+	return "/valuation/postings/" + posting.GetID()
+}
+
+func (posting Posting) Pending() bool {
+	return posting.Status == PostingStatusPending
+}
+
+// PriceDifference is the part of the value that is posted to the price difference account.
+func (posting Posting) PriceDifference() int64 {
+	return posting.Value - posting.OffsetValue
+}
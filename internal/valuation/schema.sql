@@ -0,0 +1,48 @@
+CREATE SCHEMA IF NOT EXISTS valuation;
+GRANT ALL ON SCHEMA valuation TO postgres;
+
+-- There is a single row of settings with the accounts valuation postings are posted to.
+CREATE TABLE IF NOT EXISTS valuation.settings(
+	id                          INTEGER PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+	currency_id                 INTEGER NOT NULL REFERENCES accounting.currencies(id),
+	inventory_account_id        INTEGER NOT NULL REFERENCES accounting.accounts(id),
+	receipt_account_id          INTEGER NOT NULL REFERENCES accounting.accounts(id),
+	issue_account_id            INTEGER NOT NULL REFERENCES accounting.accounts(id),
+	adjustment_account_id       INTEGER NOT NULL REFERENCES accounting.accounts(id),
+	price_difference_account_id INTEGER NOT NULL REFERENCES accounting.accounts(id)
+);
+
+CREATE TABLE IF NOT EXISTS valuation.items(
+	item_id INTEGER      PRIMARY KEY REFERENCES logistics.items(id),
+	method  VARCHAR(255) NOT NULL DEFAULT 'moving_average',
+	price   BIGINT       NOT NULL DEFAULT 0 CHECK (price >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS valuation.postings(
+	id           SERIAL       PRIMARY KEY,
+	movement_id  INTEGER      UNIQUE REFERENCES logistics.goods_movements(id),
+	item_id      INTEGER      NOT NULL REFERENCES logistics.items(id),
+	type         VARCHAR(255) NOT NULL,
+	date         VARCHAR(255) NOT NULL,
+	reference    VARCHAR(255) NOT NULL,
+	description  TEXT         NOT NULL,
+	value        BIGINT       NOT NULL,
+	offset_value BIGINT       NOT NULL,
+	status       VARCHAR(255) NOT NULL DEFAULT 'pending',
+	document_id  INTEGER      REFERENCES accounting.documents(id),
+	created_at   TIMESTAMP    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS postings_item_id_idx ON valuation.postings(item_id);
+
+-- Entries hold the quantity and value changes of a posting per plant, the stock value of a plant is their sum.
+CREATE TABLE IF NOT EXISTS valuation.entries(
+	id         SERIAL        PRIMARY KEY,
+	posting_id INTEGER       NOT NULL REFERENCES valuation.postings(id),
+	item_id    INTEGER       NOT NULL REFERENCES logistics.items(id),
+	plant_id   INTEGER       NOT NULL REFERENCES logistics.plants(id),
+	quantity   NUMERIC(18,3) NOT NULL,
+	value      BIGINT        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS entries_item_id_plant_id_idx ON valuation.entries(item_id, plant_id);
@@ -0,0 +1,332 @@
+package valuation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/logistics"
+	"github.com/tombuente/apex/internal/xerrors"
+)
+
+// Service values logistics goods movements and posts the value changes to the accounting ledger.
+type Service struct {
+	db         Database
+	logistics  logistics.Service
+	accounting accounting.Service
+}
+
+func MakeService(db Database, logistics logistics.Service, accounting accounting.Service) Service {
+	return Service{
+		db:         db,
+		logistics:  logistics,
+		accounting: accounting,
+	}
+}
+
+// ValueMovements values all goods movements that have not been valued yet and posts all pending postings. It is meant
+// to be used as the movement hook of the logistics service.
+func (s Service) ValueMovements(ctx context.Context) error {
+	if _, err := s.db.valueMovements(ctx); err != nil {
+		return err
+	}
+
+	return s.postPending(ctx)
+}
+
+func (s Service) settings(ctx context.Context) (Settings, error) {
+	return s.db.settings(ctx)
+}
+
+// setSettings configures the accounts postings are posted to.
+func (s Service) setSettings(ctx context.Context, params SettingsParams) (Settings, error) {
+	accounts := []struct {
+		name string
+		id   int64
+	}{
+		{"inventory", params.InventoryAccountID},
+		{"receipt", params.ReceiptAccountID},
+		{"issue", params.IssueAccountID},
+		{"adjustment", params.AdjustmentAccountID},
+		{"price difference", params.PriceDifferenceAccountID},
+	}
+	for _, account := range accounts {
+		if account.id == 0 {
+			return Settings{}, fmt.Errorf("%w: the %v account is required", xerrors.ErrBadRequest, account.name)
+		}
+	}
+
+	if params.CurrencyID == 0 {
+		return Settings{}, fmt.Errorf("%w: currency is required", xerrors.ErrBadRequest)
+	}
+
+	settings, err := s.db.setSettings(ctx, params)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	// Postings that waited for the settings can be posted now.
+	return settings, s.postPending(ctx)
+}
+
+func (s Service) itemValuation(ctx context.Context, itemID int64) (ItemValuation, error) {
+	if _, err := s.logistics.Item(ctx, itemID); err != nil {
+		return ItemValuation{}, err
+	}
+
+	return s.db.itemValuation(ctx, itemID)
+}
+
+func (s Service) itemValuations(ctx context.Context) ([]ItemValuation, error) {
+	return s.db.itemValuations(ctx)
+}
+
+// updateItemValuation changes the method and price of an item. Stock on hand is revalued to the new price dated today
+// and the revaluation is posted right away.
+func (s Service) updateItemValuation(ctx context.Context, itemID int64, params ItemValuationParams) (ItemValuation, error) {
+	if params.Method != MethodStandard && params.Method != MethodMovingAverage {
+		return ItemValuation{}, fmt.Errorf("%w: unknown valuation method %q", xerrors.ErrBadRequest, params.Method)
+	}
+
+	if params.Price < 0 {
+		return ItemValuation{}, fmt.Errorf("%w: price can not be negative", xerrors.ErrBadRequest)
+	}
+
+	item, err := s.logistics.Item(ctx, itemID)
+	if err != nil {
+		return ItemValuation{}, err
+	}
+
+	// Value outstanding movements first, they have to be valued at the old price.
+	if _, err := s.db.valueMovements(ctx); err != nil {
+		return ItemValuation{}, err
+	}
+
+	valuation, err := s.db.updateItemValuation(ctx, itemID, item.Name, time.Now().Format(time.DateOnly), params)
+	if err != nil {
+		return ItemValuation{}, err
+	}
+
+	return valuation, s.postPending(ctx)
+}
+
+func (s Service) stockValues(ctx context.Context, filter StockValueFilter) ([]StockValue, error) {
+	return s.db.stockValues(ctx, filter)
+}
+
+func (s Service) posting(ctx context.Context, id int64) (Posting, error) {
+	return s.db.posting(ctx, id)
+}
+
+func (s Service) postings(ctx context.Context, filter PostingFilter) ([]Posting, error) {
+	return s.db.postings(ctx, filter)
+}
+
+func (s Service) entries(ctx context.Context, postingID int64) ([]Entry, error) {
+	return s.db.entries(ctx, postingID)
+}
+
+// inventoryBalance returns the balance of the inventory account, it is zero as long as no settings exist.
+func (s Service) inventoryBalance(ctx context.Context) (int64, error) {
+	settings, err := s.db.settings(ctx)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	balance, err := s.accounting.AccountBalance(ctx, settings.InventoryAccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance.Balance(), nil
+}
+
+// postPending posts a document for every pending posting, oldest first. Postings stay pending until the settings have
+// been configured.
+func (s Service) postPending(ctx context.Context) error {
+	settings, err := s.db.settings(ctx)
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pending, err := s.db.postings(ctx, PostingFilter{status: nullString(PostingStatusPending)})
+	if errors.Is(err, xerrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		if err := s.post(ctx, pending[i], settings); err != nil {
+			return fmt.Errorf("unable to post valuation posting %v: %w", pending[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// post creates the document of a pending posting. The inventory account is debited with the value, the offset account
+// of the type is credited with the offset value and the price difference account takes the rest. Negative amounts
+// switch sides.
+func (s Service) post(ctx context.Context, posting Posting, settings Settings) error {
+	document := accounting.DocumentParams{
+		DocumentHeaderParams: accounting.DocumentHeaderParams{
+			Description: posting.Description,
+			Date:        posting.Date,
+			PostingDate: posting.Date,
+			Reference:   posting.Reference,
+			CurrencyID:  settings.CurrencyID,
+		},
+	}
+	position := func(accountID int64, amount int64, typeID int64) {
+		if amount == 0 {
+			return
+		}
+		if amount < 0 {
+			amount = -amount
+			typeID = accounting.PositionTypeDebit + accounting.PositionTypeCredit - typeID
+		}
+
+		document.Positions = append(document.Positions, accounting.DocumentPositionParams{
+			Description: posting.Description, AccountID: accountID, TypeID: typeID, Amount: amount,
+		})
+	}
+	position(settings.InventoryAccountID, posting.Value, accounting.PositionTypeDebit)
+	position(settings.offsetAccountID(posting.Type), posting.OffsetValue, accounting.PositionTypeCredit)
+	position(settings.PriceDifferenceAccountID, posting.PriceDifference(), accounting.PositionTypeCredit)
+
+	if _, err := s.db.postPosting(ctx, posting.ID, document); err != nil {
+		if errors.Is(err, xerrors.ErrNotFound) {
+			// Posted by a concurrent run.
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// valueMovement values a goods movement. Stock leaving a plant is valued at the price of the item, unless the plant
+// runs out of stock, then its whole remaining value leaves with it so no rounding residue is left behind. Stock entering
+// a plant is valued at the price of the item, receipts of moving average items against purchase orders at the order
+// price which then moves the average. Receipts of standard price items post the difference between the order price and
+// the standard price as price difference.
+func valueMovement(movement pendingMovement, item ItemValuation, from plantStock, to plantStock) postingValues {
+	posting := postingValues{
+		MovementID:  nullID(movement.ID),
+		ItemID:      movement.ItemID,
+		Type:        movement.Type,
+		Date:        movement.Date,
+		Reference:   movement.Reference,
+		Description: fmt.Sprintf("%v of %v", movementDescriptions[movement.Type], movement.ItemName),
+		Status:      PostingStatusPending,
+		Price:       item.Price,
+	}
+	if posting.Reference == "" {
+		posting.Reference = fmt.Sprintf("GM-%v", movement.ID)
+	}
+
+	quantity := movement.Quantity
+
+	// Transfers between bins of the same plant do not change the stock value of any plant.
+	if movement.Type == logistics.MovementTransfer && movement.FromPlantID == movement.ToPlantID {
+		posting.Status = PostingStatusPosted
+		return posting
+	}
+
+	var issued int64
+	if movement.FromPlantID.Valid {
+		issued = int64(math.Round(quantity * float64(item.Price)))
+		if from.Quantity > 0 && math.Abs(from.Quantity-quantity) < 0.0005 {
+			issued = from.Value
+		}
+
+		posting.Entries = append(posting.Entries, entryValues{PlantID: movement.FromPlantID.Int64, Quantity: -quantity, Value: -issued})
+		posting.Value -= issued
+	}
+
+	if movement.ToPlantID.Valid {
+		received := int64(math.Round(quantity * float64(item.Price)))
+		switch {
+		case movement.Type == logistics.MovementTransfer:
+			received = issued
+		case movement.OrderPrice.Valid && item.Method == MethodMovingAverage:
+			received = int64(math.Round(quantity * movement.OrderPrice.Float64))
+		}
+
+		posting.Entries = append(posting.Entries, entryValues{PlantID: movement.ToPlantID.Int64, Quantity: quantity, Value: received})
+		posting.Value += received
+	}
+
+	switch movement.Type {
+	case logistics.MovementReceipt:
+		posting.OffsetValue = posting.Value
+		if movement.OrderPrice.Valid {
+			posting.OffsetValue = int64(math.Round(quantity * movement.OrderPrice.Float64))
+		}
+
+		if item.Method == MethodMovingAverage {
+			posting.Price = movingAveragePrice(item, quantity, posting.Value)
+		}
+	case logistics.MovementIssue, logistics.MovementAdjustment:
+		posting.OffsetValue = posting.Value
+	}
+
+	if posting.Value == 0 && posting.OffsetValue == 0 {
+		posting.Status = PostingStatusPosted
+	}
+
+	return posting
+}
+
+// movingAveragePrice returns the price of an item after receiving quantity at value. Without stock on hand the price of
+// the receipt becomes the new price.
+func movingAveragePrice(item ItemValuation, quantity float64, value int64) int64 {
+	if item.Quantity <= 0 {
+		return int64(math.Round(float64(value) / quantity))
+	}
+
+	return int64(math.Round(float64(item.Value+value) / (item.Quantity + quantity)))
+}
+
+var movementDescriptions = map[string]string{
+	logistics.MovementReceipt:    "Goods receipt",
+	logistics.MovementIssue:      "Goods issue",
+	logistics.MovementTransfer:   "Stock transfer",
+	logistics.MovementAdjustment: "Stock adjustment",
+}
+
+// revaluation revalues the stock of an item in every plant to price. It reports false if no value changes.
+func revaluation(itemID int64, itemName string, date string, price int64, stocks []StockValue) (postingValues, bool) {
+	posting := postingValues{
+		ItemID:      itemID,
+		Type:        PostingTypeRevaluation,
+		Date:        date,
+		Reference:   fmt.Sprintf("RV-%v", itemID),
+		Description: "Revaluation of " + itemName,
+		Status:      PostingStatusPending,
+		Price:       price,
+	}
+
+	for _, stock := range stocks {
+		difference := int64(math.Round(stock.Quantity*float64(price))) - stock.Value
+		if difference == 0 {
+			continue
+		}
+
+		posting.Entries = append(posting.Entries, entryValues{PlantID: stock.PlantID, Value: difference})
+		posting.Value += difference
+	}
+
+	return posting, len(posting.Entries) > 0
+}
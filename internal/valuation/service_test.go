@@ -0,0 +1,173 @@
+package valuation
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/tombuente/apex/internal/logistics"
+)
+
+func TestValueMovement(t *testing.T) {
+	plant1 := sql.NullInt64{Valid: true, Int64: 1}
+	plant2 := sql.NullInt64{Valid: true, Int64: 2}
+	receipt := func(quantity float64, orderPrice float64) pendingMovement {
+		movement := pendingMovement{GoodsMovement: logistics.GoodsMovement{Type: logistics.MovementReceipt, ToPlantID: plant1, Quantity: quantity}}
+		if orderPrice != 0 {
+			movement.OrderPrice = sql.NullFloat64{Valid: true, Float64: orderPrice}
+		}
+		return movement
+	}
+	issue := func(quantity float64) pendingMovement {
+		return pendingMovement{GoodsMovement: logistics.GoodsMovement{Type: logistics.MovementIssue, FromPlantID: plant1, Quantity: quantity}}
+	}
+	transfer := func(quantity float64, to sql.NullInt64) pendingMovement {
+		return pendingMovement{GoodsMovement: logistics.GoodsMovement{Type: logistics.MovementTransfer, FromPlantID: plant1, ToPlantID: to, Quantity: quantity}}
+	}
+
+	tests := []struct {
+		name            string
+		movement        pendingMovement
+		item            ItemValuation
+		from            plantStock
+		wantValue       int64
+		wantOffset      int64
+		wantPrice       int64
+		wantStatus      string
+		wantEntryValues []int64
+	}{
+		{
+			name:            "moving average receipt against a purchase order",
+			movement:        receipt(10, 130),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 10, Value: 1000},
+			wantValue:       1300,
+			wantOffset:      1300,
+			wantPrice:       115,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{1300},
+		},
+		{
+			name:            "moving average receipt without stock",
+			movement:        receipt(4, 125),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100},
+			wantValue:       500,
+			wantOffset:      500,
+			wantPrice:       125,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{500},
+		},
+		{
+			name:            "moving average receipt without a purchase order",
+			movement:        receipt(10, 0),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 10, Value: 1000},
+			wantValue:       1000,
+			wantOffset:      1000,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{1000},
+		},
+		{
+			// The order price is 200 above the standard price, which is posted as price difference.
+			name:            "standard price receipt against a purchase order",
+			movement:        receipt(10, 120),
+			item:            ItemValuation{Method: MethodStandard, Price: 100, Quantity: 10, Value: 1000},
+			wantValue:       1000,
+			wantOffset:      1200,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{1000},
+		},
+		{
+			name:            "issue",
+			movement:        issue(4),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 10, Value: 1003},
+			from:            plantStock{Quantity: 10, Value: 1003},
+			wantValue:       -400,
+			wantOffset:      -400,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{-400},
+		},
+		{
+			name:            "issue of the remaining stock",
+			movement:        issue(4),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 4, Value: 403},
+			from:            plantStock{Quantity: 4, Value: 403},
+			wantValue:       -403,
+			wantOffset:      -403,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{-403},
+		},
+		{
+			name:            "issue without stock",
+			movement:        issue(3),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100},
+			wantValue:       -300,
+			wantOffset:      -300,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{-300},
+		},
+		{
+			name:            "issue from negative stock",
+			movement:        issue(2),
+			item:            ItemValuation{Method: MethodStandard, Price: 100, Quantity: -2, Value: -200},
+			from:            plantStock{Quantity: -2, Value: -200},
+			wantValue:       -200,
+			wantOffset:      -200,
+			wantPrice:       100,
+			wantStatus:      PostingStatusPending,
+			wantEntryValues: []int64{-200},
+		},
+		{
+			name:       "issue of an item without price",
+			movement:   issue(2),
+			item:       ItemValuation{Method: MethodMovingAverage},
+			wantStatus: PostingStatusPosted,
+			// Entries are kept to track the quantity.
+			wantEntryValues: []int64{0},
+		},
+		{
+			name:       "transfer within a plant",
+			movement:   transfer(2, plant1),
+			item:       ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 10, Value: 1000},
+			from:       plantStock{Quantity: 10, Value: 1000},
+			wantPrice:  100,
+			wantStatus: PostingStatusPosted,
+		},
+		{
+			name:            "transfer of the remaining stock to another plant",
+			movement:        transfer(10, plant2),
+			item:            ItemValuation{Method: MethodMovingAverage, Price: 100, Quantity: 10, Value: 1005},
+			from:            plantStock{Quantity: 10, Value: 1005},
+			wantPrice:       100,
+			wantStatus:      PostingStatusPosted,
+			wantEntryValues: []int64{-1005, 1005},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posting := valueMovement(tt.movement, tt.item, tt.from, plantStock{})
+
+			if posting.Value != tt.wantValue || posting.OffsetValue != tt.wantOffset {
+				t.Errorf("value = %v, offset value = %v, want %v and %v", posting.Value, posting.OffsetValue, tt.wantValue, tt.wantOffset)
+			}
+			if posting.Price != tt.wantPrice {
+				t.Errorf("price = %v, want %v", posting.Price, tt.wantPrice)
+			}
+			if posting.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", posting.Status, tt.wantStatus)
+			}
+
+			var values []int64
+			for _, entry := range posting.Entries {
+				values = append(values, entry.Value)
+			}
+			if !slices.Equal(values, tt.wantEntryValues) {
+				t.Errorf("entry values = %v, want %v", values, tt.wantEntryValues)
+			}
+		})
+	}
+}
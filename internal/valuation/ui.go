@@ -0,0 +1,482 @@
+package valuation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tombuente/apex/internal/accounting"
+	"github.com/tombuente/apex/internal/flash"
+	"github.com/tombuente/apex/internal/logistics"
+	"github.com/tombuente/apex/internal/templates"
+	"github.com/tombuente/apex/internal/xerrors"
+	"github.com/tombuente/apex/internal/xui"
+)
+
+type UI struct {
+	service   Service
+	templates map[string]*template.Template
+}
+
+type settingsData struct {
+	Message    flash.Message
+	Resource   *Settings
+	Accounts   []accounting.Account
+	Currencies []accounting.Currency
+}
+
+type itemValuationListData struct {
+	Message   flash.Message
+	Resources []itemValuationRow
+}
+
+// itemValuationRow is a logistics item together with its valuation.
+type itemValuationRow struct {
+	Item         logistics.Item
+	Valuation    ItemValuation
+	BaseUnitCode string
+}
+
+type itemValuationData struct {
+	Message      flash.Message
+	Resource     *ItemValuation
+	Item         logistics.Item
+	BaseUnitCode string
+	Stocks       []StockValue
+	PlantNames   map[int64]string
+	Postings     []Posting
+}
+
+type stockValueData struct {
+	Message          flash.Message
+	Plants           []plantStockValue
+	ItemNames        map[int64]string
+	BaseUnitCodes    map[int64]string
+	Total            int64
+	InventoryBalance int64
+	Configured       bool
+	Pending          int
+}
+
+// plantStockValue are the stock values of the items in a plant.
+type plantStockValue struct {
+	Plant  logistics.Plant
+	Stocks []StockValue
+	Total  int64
+}
+
+// Difference is the stock value that is not reflected in the inventory account yet.
+func (data stockValueData) Difference() int64 {
+	return data.Total - data.InventoryBalance
+}
+
+type postingListData struct {
+	Message   flash.Message
+	Resources []Posting
+	ItemNames map[int64]string
+	Status    string
+}
+
+type postingData struct {
+	Message    flash.Message
+	Resource   *Posting
+	ItemName   string
+	Entries    []Entry
+	PlantNames map[int64]string
+}
+
+func NewUIRouter(templateFS fs.FS, service Service) (*chi.Mux, error) {
+	ui := UI{
+		service:   service,
+		templates: make(map[string]*template.Template),
+	}
+
+	var err error
+	ui.templates, err = templates.Load(templateFS, "valuation")
+	if err != nil {
+		return nil, fmt.Errorf("unable to load templates: %w", err)
+	}
+
+	r := chi.NewRouter()
+
+	r.Get("/stock", ui.stockValueView)
+	r.Post("/run", ui.valueMovements)
+
+	r.Get("/settings", ui.settingsView)
+	r.Post("/settings", ui.setSettings)
+
+	r.Route("/items", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.itemValuation, ui.makeAdditionalItemValuationData, ui.templates["item-valuation-detail"]))
+		r.Get("/", ui.itemValuationListView)
+		r.Post("/{id}", xui.Update(ui.service.updateItemValuation))
+	})
+
+	r.Route("/postings", func(r chi.Router) {
+		r.Get("/{id}", xui.DetailWithAdditionalData(ui.service.posting, ui.makeAdditionalPostingData, ui.templates["posting-detail"]))
+		r.Get("/", ui.postingListView)
+	})
+
+	return r, nil
+}
+
+func (ui UI) stockValueView(w http.ResponseWriter, r *http.Request) {
+	data, err := ui.makeStockValueData(r.Context(), w, r)
+	if err != nil {
+		slog.Error("Unable to make data", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	if err := ui.templates["stock-value"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeStockValueData(ctx context.Context, w http.ResponseWriter, r *http.Request) (stockValueData, error) {
+	stocks, err := ui.service.stockValues(ctx, StockValueFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return stockValueData{}, err
+	}
+
+	plants, err := ui.service.logistics.Plants(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return stockValueData{}, err
+	}
+
+	itemNames, baseUnitCodes, err := ui.itemLookups(ctx)
+	if err != nil {
+		return stockValueData{}, err
+	}
+
+	settings, err := ui.service.settings(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return stockValueData{}, err
+	}
+
+	balance, err := ui.service.inventoryBalance(ctx)
+	if err != nil {
+		return stockValueData{}, err
+	}
+
+	pending, err := ui.service.postings(ctx, PostingFilter{status: nullString(PostingStatusPending)})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return stockValueData{}, err
+	}
+
+	data := stockValueData{
+		Message:          flash.Get(w, r),
+		ItemNames:        itemNames,
+		BaseUnitCodes:    baseUnitCodes,
+		InventoryBalance: balance,
+		Configured:       settings.Configured(),
+		Pending:          len(pending),
+	}
+	for _, plant := range plants {
+		plantValue := plantStockValue{Plant: plant}
+		for _, stock := range stocks {
+			if stock.PlantID == plant.ID {
+				plantValue.Stocks = append(plantValue.Stocks, stock)
+				plantValue.Total += stock.Value
+			}
+		}
+
+		if len(plantValue.Stocks) > 0 {
+			data.Plants = append(data.Plants, plantValue)
+			data.Total += plantValue.Total
+		}
+	}
+
+	return data, nil
+}
+
+// itemLookups returns the names and base unit codes of all items by item ID.
+func (ui UI) itemLookups(ctx context.Context) (map[int64]string, map[int64]string, error) {
+	items, err := ui.service.logistics.Items(ctx, logistics.ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	units, err := ui.service.logistics.Units(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	unitCodes := make(map[int64]string, len(units))
+	for _, unit := range units {
+		unitCodes[unit.ID] = unit.Code
+	}
+
+	names := make(map[int64]string, len(items))
+	baseUnitCodes := make(map[int64]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.Name
+		baseUnitCodes[item.ID] = unitCodes[item.BaseUnitID]
+	}
+
+	return names, baseUnitCodes, nil
+}
+
+// plantNames returns the names of all plants by plant ID.
+func (ui UI) plantNames(ctx context.Context) (map[int64]string, error) {
+	plants, err := ui.service.logistics.Plants(ctx)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(plants))
+	for _, plant := range plants {
+		names[plant.ID] = plant.Name
+	}
+
+	return names, nil
+}
+
+func (ui UI) valueMovements(w http.ResponseWriter, r *http.Request) {
+	if err := ui.service.ValueMovements(r.Context()); err != nil {
+		slog.Error("Unable to value goods movements", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Sucess, Content: "Success! All goods movements have been valued and posted."})
+	http.Redirect(w, r, "/valuation/postings", http.StatusFound)
+}
+
+func (ui UI) settingsView(w http.ResponseWriter, r *http.Request) {
+	var resource *Settings
+	settings, err := ui.service.settings(r.Context())
+	if err == nil {
+		resource = &settings
+	}
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query settings", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	accounts, err := ui.service.accounting.Accounts(r.Context(), accounting.AccountFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query accounts", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	currencies, err := ui.service.accounting.Currencies(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query currencies", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := settingsData{
+		Message:    flash.Get(w, r),
+		Resource:   resource,
+		Accounts:   accounts,
+		Currencies: currencies,
+	}
+	if err := ui.templates["settings"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) setSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "unable to parse form", http.StatusBadRequest)
+		return
+	}
+
+	var params SettingsParams
+	if err := xui.Decoder.Decode(&params, r.PostForm); err != nil {
+		http.Error(w, "unable to decode form", http.StatusBadRequest)
+		return
+	}
+
+	_, err := ui.service.setSettings(r.Context(), params)
+	if errors.Is(err, xerrors.ErrBadRequest) {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+	if err != nil {
+		slog.Error("Unable to set valuation settings", "error", err)
+		msg, code := xerrors.HttpInfo(err)
+		http.Error(w, msg, code)
+		return
+	}
+
+	flash.EntryUpdated(w)
+	http.Redirect(w, r, "/valuation/settings", http.StatusFound)
+}
+
+func (ui UI) itemValuationListView(w http.ResponseWriter, r *http.Request) {
+	items, err := ui.service.logistics.Items(r.Context(), logistics.ItemFilter{})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	valuations, err := ui.service.itemValuations(r.Context())
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query item valuations", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	_, baseUnitCodes, err := ui.itemLookups(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	byItem := make(map[int64]ItemValuation, len(valuations))
+	for _, valuation := range valuations {
+		byItem[valuation.ItemID] = valuation
+	}
+
+	data := itemValuationListData{Message: flash.Get(w, r)}
+	for _, item := range items {
+		valuation, ok := byItem[item.ID]
+		if !ok {
+			valuation = ItemValuation{ItemID: item.ID, Method: MethodMovingAverage}
+		}
+
+		data.Resources = append(data.Resources, itemValuationRow{Item: item, Valuation: valuation, BaseUnitCode: baseUnitCodes[item.ID]})
+	}
+
+	if err := ui.templates["item-valuation-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ui UI) makeAdditionalItemValuationData(ctx context.Context, w http.ResponseWriter, r *http.Request, valuation *ItemValuation) (itemValuationData, error) {
+	item, err := ui.service.logistics.Item(ctx, valuation.ItemID)
+	if err != nil {
+		return itemValuationData{}, err
+	}
+
+	_, baseUnitCodes, err := ui.itemLookups(ctx)
+	if err != nil {
+		return itemValuationData{}, err
+	}
+
+	stocks, err := ui.service.stockValues(ctx, StockValueFilter{itemID: nullID(item.ID)})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemValuationData{}, err
+	}
+
+	plantNames, err := ui.plantNames(ctx)
+	if err != nil {
+		return itemValuationData{}, err
+	}
+
+	postings, err := ui.service.postings(ctx, PostingFilter{itemID: nullID(item.ID)})
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return itemValuationData{}, err
+	}
+
+	return itemValuationData{
+		Message:      flash.Get(w, r),
+		Resource:     valuation,
+		Item:         item,
+		BaseUnitCode: baseUnitCodes[item.ID],
+		Stocks:       stocks,
+		PlantNames:   plantNames,
+		Postings:     postings,
+	}, nil
+}
+
+func (ui UI) postingListView(w http.ResponseWriter, r *http.Request) {
+	filter, err := makePostingFilter(r.URL.Query())
+	if err != nil {
+		xui.RedirectBadRequest(w, r, err)
+		return
+	}
+
+	postings, err := ui.service.postings(r.Context(), filter)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		slog.Error("Unable to query postings", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	itemNames, _, err := ui.itemLookups(r.Context())
+	if err != nil {
+		slog.Error("Unable to query items", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := postingListData{
+		Message:   flash.Get(w, r),
+		Resources: postings,
+		ItemNames: itemNames,
+		Status:    filter.status.String,
+	}
+	if err := ui.templates["posting-list"].Execute(w, data); err != nil {
+		slog.Error("Unable to execute template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func makePostingFilter(values url.Values) (PostingFilter, error) {
+	filter := PostingFilter{}
+
+	if itemID := values.Get("item_id"); itemID != "" {
+		itemID, err := strconv.ParseInt(itemID, 10, 64)
+		if err != nil {
+			return PostingFilter{}, fmt.Errorf("%w: unable to convert item id to integer", xerrors.ErrBadRequest)
+		}
+
+		filter.itemID = sql.NullInt64{Valid: true, Int64: itemID}
+	}
+
+	if status := values.Get("status"); status != "" {
+		filter.status = nullString(status)
+	}
+
+	return filter, nil
+}
+
+func (ui UI) makeAdditionalPostingData(ctx context.Context, w http.ResponseWriter, r *http.Request, posting *Posting) (postingData, error) {
+	item, err := ui.service.logistics.Item(ctx, posting.ItemID)
+	if err != nil {
+		return postingData{}, err
+	}
+
+	entries, err := ui.service.entries(ctx, posting.ID)
+	if err != nil && !errors.Is(err, xerrors.ErrNotFound) {
+		return postingData{}, err
+	}
+
+	plantNames, err := ui.plantNames(ctx)
+	if err != nil {
+		return postingData{}, err
+	}
+
+	return postingData{
+		Message:    flash.Get(w, r),
+		Resource:   posting,
+		ItemName:   item.Name,
+		Entries:    entries,
+		PlantNames: plantNames,
+	}, nil
+}
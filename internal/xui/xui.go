@@ -1,10 +1,14 @@
 package xui
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"html/template"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -170,6 +174,47 @@ func Update[R Resource, P any](updateFunc UpdateFunc[R, P]) http.HandlerFunc {
 		}
 
 		item, err := updateFunc(r.Context(), id, params)
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			RedirectBadRequest(w, r, err)
+			return
+		}
+		if err != nil {
+			slog.Error("Unable to update resources", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		flash.EntryUpdated(w)
+		http.Redirect(w, r, item.Redirect(), http.StatusFound)
+	}
+}
+
+func UpdateWithFormParser[R Resource, P any](parseFormFunc ParseFormFunc[P], updateFunc UpdateFunc[R, P]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "malformatted id", http.StatusBadRequest)
+			return
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			http.Error(w, "unable to parse form", http.StatusBadRequest)
+			return
+		}
+
+		params, err := parseFormFunc(r.PostForm)
+		if err != nil {
+			slog.Error("Unable to decode form", "error", err)
+			http.Error(w, "unable to decode form", http.StatusBadRequest)
+			return
+		}
+
+		item, err := updateFunc(r.Context(), id, params)
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			RedirectBadRequest(w, r, err)
+			return
+		}
 		if err != nil {
 			slog.Error("Unable to update resources", "error", err)
 			http.Error(w, "internal error", http.StatusInternalServerError)
@@ -220,6 +265,10 @@ func Create[R Resource, P any](createFunc CreateFunc[R, P]) http.HandlerFunc {
 		}
 
 		item, err := createFunc(r.Context(), params)
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			RedirectBadRequest(w, r, err)
+			return
+		}
 		if err != nil {
 			slog.Error("Unable to create entry in database", "error", err)
 			http.Error(w, "unable to create resource", http.StatusInternalServerError)
@@ -247,6 +296,10 @@ func CreateWithFormParser[R Resource, P any](parseFormFunc ParseFormFunc[P], cre
 		}
 
 		item, err := createFunc(r.Context(), params)
+		if errors.Is(err, xerrors.ErrBadRequest) {
+			RedirectBadRequest(w, r, err)
+			return
+		}
 		if err != nil {
 			slog.Error("Unable to create entry in database", "error", err)
 			http.Error(w, "unable to create resource", http.StatusInternalServerError)
@@ -257,3 +310,106 @@ func CreateWithFormParser[R Resource, P any](parseFormFunc ParseFormFunc[P], cre
 		http.Redirect(w, r, item.Redirect(), http.StatusFound)
 	}
 }
+
+// RedirectBadRequest shows a rejected request's error as flash message on the page the request was sent from.
+func RedirectBadRequest(w http.ResponseWriter, r *http.Request, err error) {
+	referer := r.Referer()
+	if referer == "" {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flash.Set(w, flash.Message{Level: flash.Error, Content: err.Error()})
+	http.Redirect(w, r, referer, http.StatusFound)
+}
+
+// maxPageSize limits the page size requested by clients.
+const maxPageSize = 200
+
+// Page is a window into a result set and is used for server-side pagination.
+type Page struct {
+	Number int64 // Number is the current page, starting at 1.
+	Size   int64
+	Total  int64 // Total is the amount of resources across all pages.
+	values url.Values
+}
+
+// ParsePage reads the page and size query parameters. values are kept to build links to other pages.
+func ParsePage(values url.Values, defaultSize int64) (Page, error) {
+	page := Page{Number: 1, Size: defaultSize, values: values}
+
+	if number := values.Get("page"); number != "" {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil || n < 1 {
+			return Page{}, fmt.Errorf("%w: page has to be a positive integer", xerrors.ErrBadRequest)
+		}
+		page.Number = n
+	}
+
+	if size := values.Get("size"); size != "" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil || n < 1 || n > maxPageSize {
+			return Page{}, fmt.Errorf("%w: size has to be an integer between 1 and %v", xerrors.ErrBadRequest, maxPageSize)
+		}
+		page.Size = n
+	}
+
+	return page, nil
+}
+
+func (p Page) Offset() int64 {
+	return (p.Number - 1) * p.Size
+}
+
+// Count returns the amount of pages, there is always at least one page.
+func (p Page) Count() int64 {
+	if p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.Size - 1) / p.Size
+}
+
+func (p Page) HasPrevious() bool {
+	return p.Number > 1
+}
+
+func (p Page) HasNext() bool {
+	return p.Number < p.Count()
+}
+
+func (p Page) Previous() int64 {
+	return p.Number - 1
+}
+
+func (p Page) Next() int64 {
+	return p.Number + 1
+}
+
+// URL returns a relative URL to another page which keeps all other query parameters.
+func (p Page) URL(number int64) string {
+	values := url.Values{}
+	for k, v := range p.values {
+		values[k] = v
+	}
+	values.Set("page", strconv.FormatInt(number, 10))
+
+	return "?" + values.Encode()
+}
+
+// ServePDF renders a PDF document and sends it as a download. The document is rendered to memory first, so rendering
+// errors can still be reported with a proper status code.
+func ServePDF(w http.ResponseWriter, filename string, document io.WriterTo) {
+	var buf bytes.Buffer
+	if _, err := document.WriteTo(&buf); err != nil {
+		slog.Error("Unable to render PDF", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.Error("Unable to write PDF", "error", err)
+	}
+}